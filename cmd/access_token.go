@@ -0,0 +1,176 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	path2 "path"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"github.com/michaellihs/golab/cmd/credentials"
+	"github.com/michaellihs/golab/cmd/oauth"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// authOAuth marks configurations whose token is an (encoded) OAuth token
+const authOAuth = "oauth"
+
+// authentication describes how gitlabClient is authenticated
+type authentication struct {
+	// Token is the personal access token or the OAuth access token
+	Token string
+	// OAuth is set, if we are authenticated with an OAuth token
+	OAuth *oauth.Token
+	// Store is the kind of credential store the token was read from
+	Store string
+}
+
+// currentAuthentication is set together with gitlabClient
+var currentAuthentication authentication
+
+// authenticate resolves the token for the given Gitlab URL. Expired OAuth
+// tokens are refreshed and written back.
+func authenticate(httpClient *http.Client, gitlabUrl string) (authentication, error) {
+	auth := authentication{Store: viper.GetString("credential_store")}
+	if auth.Store == "" && viper.GetString("token") != "" {
+		auth.Store = credentials.Plaintext
+	}
+	secret, store, err := readSecret(gitlabUrl)
+	if err != nil || viper.GetString("auth") != authOAuth {
+		auth.Token = secret
+		return auth, err
+	}
+	token, err := oauth.Decode(secret)
+	if err != nil {
+		return auth, err
+	}
+	if token.Expired() {
+		token, err = oauth.Config{BaseURL: gitlabUrl, HTTPClient: httpClient}.Refresh(token)
+		if err != nil {
+			return auth, err
+		}
+		encoded, err := token.Encode()
+		if err != nil {
+			return auth, err
+		}
+		if err = writeSecret(gitlabUrl, store, encoded); err != nil {
+			return auth, fmt.Errorf("could not save refreshed OAuth token: %s", err)
+		}
+	}
+	auth.Token = token.AccessToken
+	auth.OAuth = token
+	return auth, nil
+}
+
+// readSecret returns the secret for the given Gitlab URL - either from the
+// golab config (plaintext) or from the configured credential store, which is
+// returned as well.
+func readSecret(gitlabUrl string) (string, credentials.Store, error) {
+	if token := viper.GetString("token"); token != "" {
+		return token, nil, nil
+	}
+	kind := viper.GetString("credential_store")
+	if kind == "" || kind == credentials.Plaintext {
+		return "", nil, nil
+	}
+	store, err := credentialStore(kind, golabConf{
+		CredentialHelper: viper.GetString("credential_helper"),
+		CredentialFile:   viper.GetString("credential_file"),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := store.Get(credentials.Key(gitlabUrl))
+	if err == credentials.ErrNotFound {
+		return "", store, fmt.Errorf("no access token for %s found in %s credential store, use `golab login` first", gitlabUrl, kind)
+	}
+	if err != nil {
+		return "", store, fmt.Errorf("could not read the access token from the %s credential store: %s", kind, err)
+	}
+	return secret, store, nil
+}
+
+// writeSecret writes the secret back to where readSecret found it.
+func writeSecret(gitlabUrl string, store credentials.Store, secret string) error {
+	if store != nil {
+		return store.Set(credentials.Key(gitlabUrl), secret)
+	}
+	filename := viper.ConfigFileUsed()
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	// we use a MapSlice to keep all other settings and their order untouched
+	conf := yaml.MapSlice{}
+	if err = yaml.Unmarshal(content, &conf); err != nil {
+		return err
+	}
+	for i := range conf {
+		if conf[i].Key == "token" {
+			conf[i].Value = secret
+		}
+	}
+	content, err = yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append([]byte("---\n"), content...), 0600)
+}
+
+func credentialStore(kind string, conf golabConf) (credentials.Store, error) {
+	return credentials.New(kind, credentials.Options{
+		Helper:     conf.CredentialHelper,
+		File:       credentialFile(conf.CredentialFile),
+		Passphrase: askForPassphrase(),
+	})
+}
+
+func credentialFile(file string) string {
+	if file != "" {
+		return file
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return ".golab.credentials.gpg"
+	}
+	return path2.Join(home, ".golab.credentials.gpg")
+}
+
+// askForPassphrase returns a function that asks for the passphrase of the
+// credentials file only once, unless it is given in $GOLAB_PASSPHRASE.
+func askForPassphrase() func() (string, error) {
+	passphrase := os.Getenv("GOLAB_PASSPHRASE")
+	return func() (string, error) {
+		if passphrase != "" {
+			return passphrase, nil
+		}
+		fmt.Print("Enter Passphrase for credentials file: ")
+		pass, err := gopass.GetPasswd()
+		passphrase = strings.TrimSpace(string(pass))
+		return passphrase, err
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+)
+
+type apiFlags struct {
+	Fields   *map[string]string `flag_name:"field" short:"f" type:"map" required:"no" description:"Add a key=value parameter - to the query string for GET and DELETE, to the JSON body otherwise"`
+	Input    *string            `flag_name:"input" type:"string" required:"no" description:"File with the request body, use - to read from stdin"`
+	Paginate *bool              `flag_name:"paginate" type:"bool" required:"no" description:"Fetch all pages of a list by following the next page headers (default: false)"`
+}
+
+var apiCmd = &golabCommand{
+	Parent: RootCmd,
+	Flags:  &apiFlags{},
+	Cmd: &cobra.Command{
+		Use:   "api <METHOD> <path>",
+		Short: "Send a request to the Gitlab API",
+		Long: `Send a request to any endpoint of the Gitlab API, using the URL, token and HTTP settings of golab.
+
+The path is relative to the API root (e.g. projects/1/issues) and may contain a query string.
+
+Examples:
+
+    golab api GET projects/my-group%2Fmy-project/issues --paginate
+    golab api POST projects/1/issues -f title="New issue" -f labels=bug
+    golab api PUT users/41 --input user.json`,
+		Args: cobra.ExactArgs(2),
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*apiFlags)
+		method := strings.ToUpper(cmd.Args[0])
+		path, query, err := apiPath(cmd.Args[1])
+		if err != nil {
+			return err
+		}
+
+		var fields map[string]string
+		if flags.Fields != nil {
+			fields = *flags.Fields
+		}
+		var body []byte
+		if flags.Input != nil {
+			if body, err = readInput(*flags.Input); err != nil {
+				return err
+			}
+		}
+		if method == "GET" || method == "DELETE" || body != nil {
+			for key, value := range fields {
+				query.Set(key, value)
+			}
+		} else if len(fields) > 0 {
+			if body, err = json.Marshal(fields); err != nil {
+				return err
+			}
+		}
+		if flags.Paginate != nil && *flags.Paginate && query.Get("per_page") == "" {
+			query.Set("per_page", "100")
+		}
+
+		content, nextPage, err := apiRequest(method, path, query, body)
+		if err != nil {
+			return err
+		}
+		if flags.Paginate == nil || !*flags.Paginate {
+			return outputResponse(content)
+		}
+		pages := [][]byte{content}
+		for nextPage != 0 {
+			query.Set("page", strconv.Itoa(nextPage))
+			if content, nextPage, err = apiRequest(method, path, query, body); err != nil {
+				return err
+			}
+			pages = append(pages, content)
+		}
+		return outputPages(pages)
+	},
+}
+
+// apiPath splits the path given by the user into a path relative to the API
+// root and the query
+func apiPath(path string) (string, url.Values, error) {
+	path = strings.TrimPrefix(path, "/")
+	path = strings.TrimPrefix(path, "api/v4/")
+	parts := strings.SplitN(path, "?", 2)
+	query := url.Values{}
+	if len(parts) == 2 {
+		var err error
+		if query, err = url.ParseQuery(parts[1]); err != nil {
+			return "", nil, err
+		}
+	}
+	return parts[0], query, nil
+}
+
+func readInput(file string) ([]byte, error) {
+	if file == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
+
+// apiRequest sends the request with gitlabClient and returns the body of the
+// response and the next page, if any
+func apiRequest(method string, path string, query url.Values, body []byte) ([]byte, int, error) {
+	req, err := gitlabClient.NewRequest(method, path, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Body, req.ContentLength = nil, 0
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Del("Content-Type")
+	}
+	var content bytes.Buffer
+	resp, err := gitlabClient.Do(req, &content)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, 0, nil
+	}
+	return content.Bytes(), resp.NextPage, nil
+}
+
+// outputResponse renders JSON responses like all other commands and prints
+// everything else (e.g. raw files) as it is
+func outputResponse(content []byte) error {
+	var result interface{}
+	if len(content) == 0 {
+		return nil
+	}
+	if err := unmarshalJson(content, &result); err != nil {
+		fmt.Print(string(content))
+		return nil
+	}
+	return OutputJson(result)
+}
+
+// outputPages merges the lists of all pages into a single list
+func outputPages(pages [][]byte) error {
+	result := []interface{}{}
+	for _, page := range pages {
+		var items []interface{}
+		if err := unmarshalJson(page, &items); err != nil {
+			return errors.New("--paginate requires an endpoint that returns a list: " + err.Error())
+		}
+		result = append(result, items...)
+	}
+	return OutputJson(result)
+}
+
+// unmarshalJson keeps numbers as they are, so that large IDs are not rendered as floats
+func unmarshalJson(content []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func init() {
+	apiCmd.Init()
+}
@@ -0,0 +1,92 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("api command", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(apiCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sends fields as JSON body for POST requests", func() {
+		var body string
+		mux.HandleFunc("/api/v4/projects/1/issues", func(w http.ResponseWriter, r *http.Request) {
+			testMethod(r, "POST")
+			content, _ := ioutil.ReadAll(r.Body)
+			body = string(content)
+			fmt.Fprint(w, `{"id":12345678901,"title":"New issue"}`)
+		})
+		stdout, _, err := executeCommand(RootCmd, "api", "post", "/api/v4/projects/1/issues", "-f", "title=New issue", "-f", "labels=bug")
+		Expect(err).To(BeNil())
+		Expect(body).To(MatchJSON(`{"title":"New issue","labels":"bug"}`))
+		Expect(stdout).To(MatchJSON(`{"id":12345678901,"title":"New issue"}`))
+		Expect(stdout).To(ContainSubstring(`"id": 12345678901`))
+	})
+
+	It("follows the next page headers with --paginate", func() {
+		var queries []string
+		mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+			testMethod(r, "GET")
+			queries = append(queries, r.URL.RawQuery)
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `<`+server.URL+`/api/v4/projects?page=2&per_page=100&search=golab>; rel="next"`)
+				fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id":3}]`)
+		})
+		stdout, _, err := executeCommand(RootCmd, "api", "GET", "projects?search=golab", "--paginate")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(MatchJSON(`[{"id":1},{"id":2},{"id":3}]`))
+		Expect(queries).To(Equal([]string{"per_page=100&search=golab", "page=2&per_page=100&search=golab"}))
+	})
+
+	It("requires key=value fields", func() {
+		_, _, err := executeCommand(RootCmd, "api", "POST", "projects/1/issues", "-f", "title")
+		Expect(err).To(MatchError(`invalid value "title" for flag --field, expected a key=value pair`))
+	})
+
+})
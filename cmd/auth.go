@@ -0,0 +1,129 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the authentication of golab",
+	Long:  `Show the status of the authentication of golab`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use one of the subcommands, see `golab auth -h`")
+	},
+}
+
+type authStatus struct {
+	Url             string   `json:"url"`
+	Username        string   `json:"username"`
+	UserId          int      `json:"user_id"`
+	IsAdmin         bool     `json:"is_admin"`
+	TokenType       string   `json:"token_type"`
+	CredentialStore string   `json:"credential_store,omitempty"`
+	TokenName       string   `json:"token_name,omitempty"`
+	TokenScopes     []string `json:"token_scopes,omitempty"`
+	TokenExpiresAt  string   `json:"token_expires_at,omitempty"`
+	Version         string   `json:"version,omitempty"`
+	Revision        string   `json:"revision,omitempty"`
+}
+
+// see https://docs.gitlab.com/ce/api/personal_access_tokens.html#using-a-request-header
+type personalAccessTokenInfo struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+var authStatusCmd = &golabCommand{
+	Parent: authCmd,
+	Cmd: &cobra.Command{
+		Use:   "status",
+		Short: "Show authentication status",
+		Long: `Show the Gitlab URL, the user, the token and the Gitlab version golab is currently using.
+
+Returns with a non-zero exit code, if the token is invalid or expired.`,
+	},
+	Run: func(cmd golabCommand) error {
+		status := authStatus{
+			Url:             strings.TrimSuffix(gitlabClient.BaseURL().String(), "/api/v4/"),
+			TokenType:       "personal_access_token",
+			CredentialStore: currentAuthentication.Store,
+		}
+
+		user, _, err := gitlabClient.Users.CurrentUser()
+		if e, ok := err.(*gitlab.ErrorResponse); ok && e.Response.StatusCode == http.StatusUnauthorized {
+			if currentAuthentication.Token == "" && currentAuthentication.OAuth == nil {
+				return fmt.Errorf("not logged in to %s, use `golab login` first", status.Url)
+			}
+			return fmt.Errorf("the token for %s is invalid or expired, use `golab login` to login again", status.Url)
+		}
+		if err != nil {
+			return err
+		}
+		status.Username = user.Username
+		status.UserId = user.ID
+		status.IsAdmin = user.IsAdmin
+
+		if token := currentAuthentication.OAuth; token != nil {
+			status.TokenType = "oauth"
+			status.TokenScopes = strings.Fields(token.Scope)
+			if !token.Expiry.IsZero() {
+				status.TokenExpiresAt = token.Expiry.Format(time.RFC3339)
+			}
+		} else if info, err := currentPersonalAccessToken(); err == nil {
+			// the endpoint is only available since Gitlab 15.5, so we ignore errors
+			status.TokenName = info.Name
+			status.TokenScopes = info.Scopes
+			status.TokenExpiresAt = info.ExpiresAt
+		}
+
+		if version, _, err := gitlabClient.Version.GetVersion(); err == nil {
+			status.Version = version.Version
+			status.Revision = version.Revision
+		}
+
+		return OutputJson(status)
+	},
+}
+
+func currentPersonalAccessToken() (*personalAccessTokenInfo, error) {
+	req, err := gitlabClient.NewRequest("GET", "personal_access_tokens/self", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := &personalAccessTokenInfo{}
+	_, err = gitlabClient.Do(req, info)
+	return info, err
+}
+
+func init() {
+	RootCmd.AddCommand(authCmd)
+	authStatusCmd.Init()
+}
@@ -0,0 +1,87 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("auth command", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "my-token")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+		currentAuthentication = authentication{Token: "my-token", Store: "keyring"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		currentAuthentication = authentication{}
+	})
+
+	Context("when the `status` command is executed", func() {
+
+		It("reports user, token and version", func() {
+			mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"id":1,"username":"root","is_admin":true}`)
+			})
+			mux.HandleFunc("/api/v4/personal_access_tokens/self", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"name":"golab","scopes":["api"],"expires_at":"2030-01-01"}`)
+			})
+			mux.HandleFunc("/api/v4/version", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"version":"11.0.0","revision":"abcdef"}`)
+			})
+			stdout, _, err := executeCommand(RootCmd, "auth", "status")
+			Expect(err).To(BeNil())
+			Expect(stdout).To(ContainSubstring(`"url": "` + server.URL + `"`))
+			Expect(stdout).To(ContainSubstring(`"username": "root"`))
+			Expect(stdout).To(ContainSubstring(`"is_admin": true`))
+			Expect(stdout).To(ContainSubstring(`"credential_store": "keyring"`))
+			Expect(stdout).To(ContainSubstring(`"token_expires_at": "2030-01-01"`))
+			Expect(stdout).To(ContainSubstring(`"version": "11.0.0"`))
+		})
+
+		It("returns an error for an invalid token", func() {
+			mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+			})
+			_, _, err := executeCommand(RootCmd, "auth", "status")
+			Expect(err).To(MatchError("the token for " + server.URL + " is invalid or expired, use `golab login` to login again"))
+		})
+
+	})
+
+})
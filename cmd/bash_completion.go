@@ -0,0 +1,50 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var bashCompletionPath string
+
+var bashCompletionCmd = &cobra.Command{
+	Use:   "bash-completion",
+	Short: "Generate Bash completion file",
+	Long:  `Generate Bash completion file, which completes commands, flags and the values of flags with a fixed set of values`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if bashCompletionPath == "" {
+			return errors.New("required parameter `-p` or `--path` not given - exiting")
+		}
+		return RootCmd.GenBashCompletionFile(bashCompletionPath)
+	},
+}
+
+func init() {
+	initBashCompletionCmd()
+}
+
+func initBashCompletionCmd() {
+	bashCompletionCmd.PersistentFlags().StringVarP(&bashCompletionPath, "path", "p", "", "(required) Path into which to render Bash completion")
+	RootCmd.AddCommand(bashCompletionCmd)
+}
@@ -45,7 +45,7 @@ var branchesCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#list-repository-branches
 type branchesListFlags struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 }
 
 var branchesListCmd = &golabCommand{
@@ -70,7 +70,7 @@ var branchesListCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#get-single-repository-branch
 type branchesGetSingleFlags struct {
-	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Branch *string `flag_name:"branch" short:"b" type:"string" required:"yes" description:"The name of the branch"`
 }
 
@@ -94,7 +94,7 @@ var branchesGetSingleCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#protect-repository-branch
 type branchesProtectFlags struct {
-	Id                 *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id                 *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Branch             *string `flag_name:"branch" short:"b" type:"string" required:"yes" description:"The name of the branch"`
 	DevelopersCanPush  *bool   `flag_name:"developers_can_push" short:"p" type:"boolean" required:"no" description:"Flag if developers can push to the branch"`
 	DevelopersCanMerge *bool   `flag_name:"developers_can_merge" short:"m" type:"boolean" required:"no" description:"Flag if developers can merge to the branch"`
@@ -122,7 +122,7 @@ var branchesProtectCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#unprotect-repository-branch
 type branchesUnprotectFlags struct {
-	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Branch *string `flag_name:"branch" short:"b" type:"string" required:"yes" description:"The name of the branch"`
 }
 
@@ -146,7 +146,7 @@ var branchesUnprotectCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#create-repository-branch
 type branchesCreateFlags struct {
-	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Branch *string `flag_name:"branch" short:"b" type:"string" required:"yes" description:"The name of the branch"`
 	Ref    *string `flag_name:"ref" short:"r" type:"string" required:"yes" description:"The branch name or commit SHA to create branch from"`
 }
@@ -173,7 +173,7 @@ var branchesCreateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#delete-repository-branch
 type branchesDeleteFlags struct {
-	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Branch *string `flag_name:"branch" short:"b" type:"string" required:"yes" description:"The name of the branch"`
 }
 
@@ -194,7 +194,7 @@ var branchesDeleteCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/branches.html#delete-merged-branches
 type branchesDeleteMergedFlags struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 }
 
 var branchesDeleteMergedCmd = &golabCommand{
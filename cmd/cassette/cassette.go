@@ -0,0 +1,238 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package cassette records the HTTP interactions of golab with Gitlab into
+// YAML files ("cassettes") and replays them, so that commands can be tested
+// against real API responses without a network connection.
+//
+// A cassette is recorded once against a real Gitlab by running the tests in
+// Record mode, afterwards the tests replay the cassette in Replay mode.
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/michaellihs/golab/cmd/helpers"
+	"gopkg.in/yaml.v2"
+)
+
+// Mode decides whether a Recorder sends requests to Gitlab or replays them
+type Mode int
+
+const (
+	// Replay answers requests with the recorded responses, requests that were not recorded fail
+	Replay Mode = iota
+	// Record sends requests to Gitlab and records the interactions into the cassette
+	Record
+)
+
+// recordedHeaders are the response headers that are kept in a cassette, other
+// headers are dropped, since they change with every request (and might contain session cookies)
+var recordedHeaders = []string{"Content-Type", "Link", "X-Next-Page", "X-Page", "X-Per-Page", "X-Prev-Page", "X-Total", "X-Total-Pages"}
+
+// Request is a recorded request, the host of the Gitlab server and the request
+// headers (with the access token) are not recorded, secrets in the URI and body are redacted
+type Request struct {
+	Method string `yaml:"method"`
+	URI    string `yaml:"uri"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+// Response is a recorded response, secrets in the body are redacted
+type Response struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// Interaction is a request and the response Gitlab sent for it
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Cassette holds the interactions in the order they were recorded
+type Cassette struct {
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// Recorder is a http.RoundTripper that records or replays the interactions of a cassette
+type Recorder struct {
+	path     string
+	mode     Mode
+	base     http.RoundTripper
+	cassette *Cassette
+	replayed []bool
+	mu       sync.Mutex
+}
+
+// New returns a Recorder for the cassette in the given file. In Record mode,
+// requests are sent with the base transport (http.DefaultTransport if nil),
+// in Replay mode the cassette has to exist.
+func New(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, base: base, cassette: &Cassette{}}
+	if mode == Record {
+		return r, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cassette %s: %s", path, err)
+	}
+	if err := yaml.Unmarshal(content, r.cassette); err != nil {
+		return nil, fmt.Errorf("cannot parse cassette %s: %s", path, err)
+	}
+	r.replayed = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	request := Request{Method: req.Method, URI: helpers.Redact(req.URL.RequestURI()), Body: helpers.Redact(string(body))}
+	if r.mode == Record {
+		return r.record(req, request)
+	}
+	return r.replay(req, request)
+}
+
+func (r *Recorder) record(req *http.Request, request Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response := Response{Status: resp.StatusCode, Headers: map[string]string{}, Body: helpers.Redact(string(body))}
+	for _, header := range recordedHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			response.Headers[header] = helpers.Redact(value)
+		}
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{Request: request, Response: response})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers the request with the first interaction that matches and was not replayed yet
+func (r *Recorder) replay(req *http.Request, request Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || !matches(interaction.Request, request) {
+			continue
+		}
+		r.replayed[i] = true
+		return interaction.Response.httpResponse(req), nil
+	}
+	return nil, fmt.Errorf("cassette %s has no interaction for %s %s", r.path, request.Method, request.URI)
+}
+
+// Unplayed returns the recorded interactions that were not replayed, e.g. to
+// check that a command sent all requests that were recorded. It is empty in Record mode.
+func (r *Recorder) Unplayed() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode == Record {
+		return nil
+	}
+	var unplayed []*Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.replayed[i] {
+			unplayed = append(unplayed, interaction)
+		}
+	}
+	return unplayed
+}
+
+// Stop writes the cassette in Record mode and does nothing in Replay mode
+func (r *Recorder) Stop() error {
+	if r.mode != Record {
+		return nil
+	}
+	content, err := yaml.Marshal(r.cassette)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, content, 0644)
+}
+
+// matches compares the redacted requests, since secrets are not recorded
+func matches(recorded Request, request Request) bool {
+	return recorded.Method == request.Method && helpers.Redact(recorded.URI) == request.URI && sameBody(helpers.Redact(recorded.Body), request.Body)
+}
+
+func sameBody(recorded string, body string) bool {
+	return strings.TrimSpace(recorded) == strings.TrimSpace(body)
+}
+
+func (resp Response) httpResponse(req *http.Request) *http.Response {
+	header := http.Header{}
+	for name, value := range resp.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// readBody reads the body of the request and replaces it, so that it can be sent afterwards
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errors.New("cannot read request body: " + err.Error())
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,179 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+
+	var (
+		dir      string
+		path     string
+		server   *httptest.Server
+		requests int
+	)
+
+	BeforeEach(func() {
+		dir, _ = ioutil.TempDir("", "golab-cassette")
+		path = filepath.Join(dir, "cassettes", "projects.yml")
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path == "/api/v4/runners" {
+				fmt.Fprint(w, `{"id":1,"token":"my-runner-token"}`)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Total", "1")
+			w.Header().Set("Set-Cookie", "session=secret")
+			fmt.Fprintf(w, `{"uri":%q,"body":%q}`, r.URL.RequestURI(), body)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	get := func(client *http.Client, uri string) (*http.Response, string, error) {
+		resp, err := client.Get(server.URL + uri)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body), nil
+	}
+
+	It("records interactions and replays them without a server", func() {
+		recorder, err := New(path, Record, nil)
+		Expect(err).To(BeNil())
+		client := &http.Client{Transport: recorder}
+		_, recorded, err := get(client, "/api/v4/projects?search=golab")
+		Expect(err).To(BeNil())
+		_, err = client.Post(server.URL+"/api/v4/projects", "application/json", strings.NewReader(`{"name":"golab"}`))
+		Expect(err).To(BeNil())
+		Expect(recorder.Stop()).To(BeNil())
+		server.Close()
+
+		replayer, err := New(path, Replay, nil)
+		Expect(err).To(BeNil())
+		client = &http.Client{Transport: replayer}
+		resp, replayed, err := get(client, "/api/v4/projects?search=golab")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal(2))
+		Expect(replayed).To(Equal(recorded))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("X-Total")).To(Equal("1"))
+		Expect(replayer.Unplayed()).To(HaveLen(1))
+		Expect(replayer.Unplayed()[0].Request.Body).To(Equal(`{"name":"golab"}`))
+	})
+
+	It("does not record request headers and volatile response headers", func() {
+		recorder, _ := New(path, Record, nil)
+		client := &http.Client{Transport: recorder}
+		req, _ := http.NewRequest("GET", server.URL+"/api/v4/user", nil)
+		req.Header.Set("Private-Token", "my-token")
+		client.Do(req)
+		recorder.Stop()
+
+		content, _ := ioutil.ReadFile(path)
+		Expect(string(content)).To(ContainSubstring("uri: /api/v4/user"))
+		Expect(string(content)).NotTo(ContainSubstring("my-token"))
+		Expect(string(content)).NotTo(ContainSubstring("session=secret"))
+	})
+
+	It("redacts secrets in recorded requests and responses", func() {
+		recorder, _ := New(path, Record, nil)
+		client := &http.Client{Transport: recorder}
+		post := func() (*http.Response, error) {
+			return client.Post(server.URL+"/api/v4/runners?private_token=my-token", "application/json", strings.NewReader(`{"registration_token":"my-registration-token"}`))
+		}
+		_, err := post()
+		Expect(err).To(BeNil())
+		recorder.Stop()
+		server.Close()
+
+		content, _ := ioutil.ReadFile(path)
+		Expect(string(content)).To(ContainSubstring("[REDACTED]"))
+		Expect(string(content)).NotTo(ContainSubstring("my-token"))
+		Expect(string(content)).NotTo(ContainSubstring("my-registration-token"))
+		Expect(string(content)).NotTo(ContainSubstring("my-runner-token"))
+
+		replayer, _ := New(path, Replay, nil)
+		client = &http.Client{Transport: replayer}
+		resp, err := post()
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(resp.Body)
+		Expect(string(body)).To(Equal(`{"id":1,"token":"[REDACTED]"}`))
+	})
+
+	It("replays interactions for the same request in the recorded order", func() {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(`interactions:
+- request: {method: GET, uri: /api/v4/projects/1}
+  response: {status: 200, body: '{"star_count":0}'}
+- request: {method: GET, uri: /api/v4/projects/1}
+  response: {status: 200, body: '{"star_count":1}'}
+`), 0644)
+		replayer, err := New(path, Replay, nil)
+		Expect(err).To(BeNil())
+		client := &http.Client{Transport: replayer}
+
+		_, first, _ := get(client, "/api/v4/projects/1")
+		_, second, _ := get(client, "/api/v4/projects/1")
+
+		Expect(first).To(Equal(`{"star_count":0}`))
+		Expect(second).To(Equal(`{"star_count":1}`))
+	})
+
+	It("fails for requests that were not recorded", func() {
+		recorder, _ := New(path, Record, nil)
+		recorder.Stop()
+		replayer, _ := New(path, Replay, nil)
+		client := &http.Client{Transport: replayer}
+
+		_, _, err := get(client, "/api/v4/groups")
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("has no interaction for GET /api/v4/groups"))
+	})
+
+	It("fails for missing cassettes in replay mode", func() {
+		_, err := New(path, Replay, nil)
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("cannot read cassette"))
+	})
+})
@@ -34,6 +34,10 @@ import (
 	"io"
 	"strings"
 	"io/ioutil"
+	"reflect"
+
+	"github.com/michaellihs/golab/cmd/cassette"
+	"github.com/xanzy/go-gitlab"
 )
 
 func TestCmd(t *testing.T) {
@@ -82,6 +86,27 @@ func resetCommandLineFlagSet() {
 	pflag.CommandLine = pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
 }
 
+// resetFlags resets the flags of a command, since cobra and the mapper keep their values between executions
+func resetFlags(cmd *golabCommand) {
+	for _, mapped := range []interface{}{cmd.Flags, cmd.Opts} {
+		if mapped != nil {
+			v := reflect.ValueOf(mapped).Elem()
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+	cmd.Cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Value.Type() == "stringArray" {
+			// string arrays would append to their old values
+			fs := pflag.NewFlagSet("reset", pflag.ContinueOnError)
+			fs.StringArray(f.Name, nil, f.Usage)
+			f.Value = fs.Lookup(f.Name).Value
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
 func testMethod(r *http.Request, want string) {
 	if got := r.Method; got != want {
 		Fail(fmt.Sprintf("Request method: %s, want %s", got, want))
@@ -99,3 +124,21 @@ func readFixture(fixture string) string {
 	}
 	return string(content)
 }
+
+// useCassette lets gitlabClient replay the interactions recorded in fixtures/cassettes/<name>.yml,
+// the interactions are recorded (again) from the Gitlab in $GOLAB_RECORD_URL with $GOLAB_RECORD_TOKEN
+// if the variable is set. Call Stop() on the recorder to write the cassette after recording.
+func useCassette(name string) *cassette.Recorder {
+	path := fmt.Sprintf("fixtures/cassettes/%s.yml", name)
+	mode, url, token := cassette.Replay, "https://gitlab.example.com", ""
+	if os.Getenv("GOLAB_RECORD_URL") != "" {
+		mode, url, token = cassette.Record, os.Getenv("GOLAB_RECORD_URL"), os.Getenv("GOLAB_RECORD_TOKEN")
+	}
+	recorder, err := cassette.New(path, mode, nil)
+	if err != nil {
+		Fail(err.Error())
+	}
+	gitlabClient = gitlab.NewClient(&http.Client{Transport: recorder}, token)
+	gitlabClient.SetBaseURL(url + "/api/v4")
+	return recorder
+}
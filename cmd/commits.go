@@ -44,7 +44,7 @@ var commitsCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/commits.html#list-repository-commits
 type commitsListFlags struct {
-	Id      *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id      *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	RefName *string `flag_name:"ref_name" short:"r" type:"string" required:"no" description:"The name of a repository branch or tag or if not given the default branch"`
 	Since   *string `flag_name:"since" transform:"string2TimeVal" short:"s" type:"string" required:"no" description:"Only commits after or on this date will be returned in ISO 8601 format YYYY-MM-DDTHH:MM:SSZ"`
 	Until   *string `flag_name:"until" transform:"string2TimeVal" short:"u" type:"string" required:"no" description:"Only commits before or on this date will be returned in ISO 8601 format YYYY-MM-DDTHH:MM:SSZ"`
@@ -73,7 +73,7 @@ var commitsListCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/commits.html#create-a-commit-with-multiple-files-and-actions
 type commitsCreateFlags struct {
-	Id            *string `flag_name:"id" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id            *string `flag_name:"id" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Branch        *string `flag_name:"branch" type:"string" required:"yes" description:"Name of the branch to commit into. To create a new branch, also provide start_branch."`
 	CommitMessage *string `flag_name:"commit_message" type:"string" required:"yes" description:"Commit message"`
 	StartBranch   *string `flag_name:"start_branch" type:"string" required:"no" description:"Name of the branch to start the new commit from"`
@@ -0,0 +1,217 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credentials", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "golab-credentials")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	passphrase := func(p string) func() (string, error) {
+		return func() (string, error) { return p, nil }
+	}
+
+	Describe("Key", func() {
+
+		It("returns host and port of the Gitlab URL", func() {
+			Expect(Key("https://gitlab.my-domain.com:8443/")).To(Equal("gitlab.my-domain.com:8443"))
+			Expect(Key("http://localhost:8080")).To(Equal("localhost:8080"))
+		})
+
+	})
+
+	Describe("New", func() {
+
+		It("returns an error for unknown stores", func() {
+			_, err := New("unknown", Options{})
+			Expect(err).To(MatchError(ContainSubstring("unknown credential store 'unknown'")))
+		})
+
+		It("returns an error for a helper store without command", func() {
+			_, err := New(Helper, Options{})
+			Expect(err).To(MatchError("the helper credential store requires a helper command"))
+		})
+
+	})
+
+	Describe("file store", func() {
+
+		It("stores, reads and erases tokens", func() {
+			path := filepath.Join(dir, "credentials.gpg")
+			store, err := New(File, Options{File: path, Passphrase: passphrase("secret")})
+			Expect(err).To(BeNil())
+
+			_, err = store.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+
+			Expect(store.Set("gitlab.com", "token-1")).To(BeNil())
+			Expect(store.Set("localhost:8080", "token-2")).To(BeNil())
+
+			content, err := ioutil.ReadFile(path)
+			Expect(err).To(BeNil())
+			Expect(string(content)).NotTo(ContainSubstring("token-1"))
+
+			token, err := store.Get("gitlab.com")
+			Expect(err).To(BeNil())
+			Expect(token).To(Equal("token-1"))
+
+			Expect(store.Erase("gitlab.com")).To(BeNil())
+			_, err = store.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+			token, err = store.Get("localhost:8080")
+			Expect(err).To(BeNil())
+			Expect(token).To(Equal("token-2"))
+		})
+
+		It("returns an error for a wrong passphrase", func() {
+			path := filepath.Join(dir, "credentials.gpg")
+			store, _ := New(File, Options{File: path, Passphrase: passphrase("secret")})
+			Expect(store.Set("gitlab.com", "token")).To(BeNil())
+
+			store, _ = New(File, Options{File: path, Passphrase: passphrase("wrong")})
+			_, err := store.Get("gitlab.com")
+			Expect(err).NotTo(BeNil())
+		})
+
+	})
+
+	Describe("helper store", func() {
+
+		It("talks to the helper with the git credential protocol", func() {
+			storage := filepath.Join(dir, "stored")
+			script := filepath.Join(dir, "helper.sh")
+			Expect(ioutil.WriteFile(script, []byte(`#!/bin/sh
+case "$1" in
+  store) cat > `+storage+` ;;
+  get) cat `+storage+` 2>/dev/null ;;
+  erase) rm -f `+storage+` ;;
+esac
+`), 0700)).To(BeNil())
+
+			store, err := New(Helper, Options{Helper: script})
+			Expect(err).To(BeNil())
+
+			Expect(store.Set("gitlab.com", "my-token")).To(BeNil())
+			stored, _ := ioutil.ReadFile(storage)
+			Expect(string(stored)).To(ContainSubstring("host=gitlab.com\n"))
+
+			token, err := store.Get("gitlab.com")
+			Expect(err).To(BeNil())
+			Expect(token).To(Equal("my-token"))
+
+			Expect(store.Erase("gitlab.com")).To(BeNil())
+			_, err = store.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+		})
+
+	})
+
+	// fakeTool puts a script with the given name and body first on the PATH, until the returned function is called
+	fakeTool := func(name string, body string) func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0700)).To(BeNil())
+		path := os.Getenv("PATH")
+		os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+		return func() { os.Setenv("PATH", path) }
+	}
+
+	Describe("pass store", func() {
+
+		It("returns ErrNotFound only for entries that are not in the password store", func() {
+			defer fakeTool("pass", `echo "Error: $3 is not in the password store." >&2; exit 1`)()
+			_, err := passStore{}.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+		})
+
+		It("returns the errors of pass and gpg", func() {
+			defer fakeTool("pass", `echo "gpg: decryption failed: No secret key" >&2; exit 2`)()
+			_, err := passStore{}.Get("gitlab.com")
+			Expect(err).To(MatchError("pass: gpg: decryption failed: No secret key"))
+		})
+
+	})
+
+	Describe("keyring store", func() {
+
+		It("returns ErrNotFound only for hosts without a secret", func() {
+			defer fakeTool("secret-tool", `exit 1`)()
+			_, err := keyringStore{os: "linux"}.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+
+			defer fakeTool("security", `echo "security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain." >&2; exit 44`)()
+			_, err = keyringStore{os: "darwin"}.Get("gitlab.com")
+			Expect(err).To(Equal(ErrNotFound))
+		})
+
+		It("returns the errors of the Secret Service", func() {
+			defer fakeTool("secret-tool", `echo "Cannot autolaunch D-Bus without X11 \$DISPLAY" >&2; exit 1`)()
+			_, err := keyringStore{os: "linux"}.Get("gitlab.com")
+			Expect(err).To(MatchError("secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY"))
+		})
+
+		It("returns an error, if the tool is missing", func() {
+			path := os.Getenv("PATH")
+			os.Setenv("PATH", dir)
+			defer os.Setenv("PATH", path)
+			_, err := keyringStore{os: "linux"}.Get("gitlab.com")
+			Expect(err).NotTo(Equal(ErrNotFound))
+			Expect(err.Error()).To(ContainSubstring("secret-tool: exec: \"secret-tool\": executable file not found"))
+		})
+
+		It("does not pass the token as argument to the Keychain", func() {
+			script := filepath.Join(dir, "security")
+			Expect(ioutil.WriteFile(script, []byte(`#!/bin/sh
+echo "$@" > `+filepath.Join(dir, "args")+`
+cat > `+filepath.Join(dir, "stdin")+`
+`), 0700)).To(BeNil())
+			path := os.Getenv("PATH")
+			os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+			defer os.Setenv("PATH", path)
+
+			Expect(keyringStore{os: "darwin"}.Set("gitlab.com", "my-token")).To(BeNil())
+
+			args, _ := ioutil.ReadFile(filepath.Join(dir, "args"))
+			stdin, _ := ioutil.ReadFile(filepath.Join(dir, "stdin"))
+			Expect(string(args)).NotTo(ContainSubstring("my-token"))
+			Expect(string(args)).To(HaveSuffix("-w\n"))
+			Expect(string(stdin)).To(Equal("my-token\nmy-token\n"))
+		})
+
+	})
+
+})
@@ -0,0 +1,137 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"gopkg.in/yaml.v2"
+)
+
+// fileStore keeps the tokens of all hosts in a single, symmetrically
+// encrypted OpenPGP file. The file can be decrypted with `gpg -d <file>`.
+type fileStore struct {
+	path       string
+	passphrase func() (string, error)
+}
+
+func (f fileStore) Get(host string) (string, error) {
+	tokens, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[host]
+	if !ok || token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (f fileStore) Set(host string, token string) error {
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	tokens[host] = token
+	return f.write(tokens)
+}
+
+func (f fileStore) Erase(host string) error {
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, host)
+	return f.write(tokens)
+}
+
+func (f fileStore) read() (map[string]string, error) {
+	tokens := map[string]string{}
+	encrypted, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := armor.Decode(bytes.NewReader(encrypted))
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	// the prompt is called again, if the passphrase was wrong - so we have to stop after the first attempt
+	prompted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if prompted {
+			return nil, errors.New("wrong passphrase for credentials file " + f.path)
+		}
+		prompted = true
+		return []byte(passphrase), nil
+	}
+	md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{}, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(plaintext, &tokens)
+	return tokens, err
+}
+
+func (f fileStore) write(tokens map[string]string) error {
+	plaintext, err := yaml.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return err
+	}
+	var encrypted bytes.Buffer
+	armored, err := armor.Encode(&encrypted, "PGP MESSAGE", nil)
+	if err != nil {
+		return err
+	}
+	w, err := openpgp.SymmetricallyEncrypt(armored, []byte(passphrase), nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(plaintext); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = armored.Close(); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path, encrypted.Bytes(), 0600)
+}
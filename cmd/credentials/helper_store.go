@@ -0,0 +1,68 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"fmt"
+	"strings"
+)
+
+// helperStore delegates to an external credential helper that speaks the
+// protocol of git credential helpers, see https://git-scm.com/docs/git-credential
+// The helper is called as `<command> get|store|erase` and the token is
+// exchanged as `password` attribute.
+type helperStore struct {
+	command string
+}
+
+func (h helperStore) Get(host string) (string, error) {
+	out, err := h.call("get", host, "")
+	if err != nil {
+		// like git, we treat a failing helper as a helper that knows no token
+		return "", ErrNotFound
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "password=") {
+			if token := strings.TrimSpace(strings.TrimPrefix(line, "password=")); token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (h helperStore) Set(host string, token string) error {
+	_, err := h.call("store", host, token)
+	return err
+}
+
+func (h helperStore) Erase(host string) error {
+	_, err := h.call("erase", host, "")
+	return err
+}
+
+func (h helperStore) call(action string, host string, token string) (string, error) {
+	input := fmt.Sprintf("protocol=https\nhost=%s\nusername=golab\n", host)
+	if token != "" {
+		input += "password=" + token + "\n"
+	}
+	return run(input+"\n", "sh", "-c", h.command+" "+action)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const keyringService = "golab"
+
+// keyringStore uses the keyring of the OS. On Linux we talk to the Secret
+// Service (Gnome Keyring, KWallet...) via `secret-tool`, on MacOS we use the
+// Keychain via `security`.
+type keyringStore struct {
+	os string
+}
+
+func newKeyringStore() (Store, error) {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, errors.New("keyring credential store requires `secret-tool` (libsecret) to be installed")
+		}
+	case "darwin":
+		if _, err := exec.LookPath("security"); err != nil {
+			return nil, errors.New("keyring credential store requires the `security` command")
+		}
+	default:
+		return nil, errors.New("keyring credential store is not supported on " + runtime.GOOS)
+	}
+	return keyringStore{os: runtime.GOOS}, nil
+}
+
+func (k keyringStore) Get(host string) (string, error) {
+	var out string
+	var err error
+	if k.os == "darwin" {
+		out, err = run("", "security", "find-generic-password", "-s", keyringService, "-a", host, "-w")
+		// security exits with errSecItemNotFound (44), if there is no password for the host
+		if failedWith(err, 44) {
+			return "", ErrNotFound
+		}
+	} else {
+		out, err = run("", "secret-tool", "lookup", "service", keyringService, "host", host)
+		// secret-tool exits with 1 without a message, if there is no secret for the host
+		if failedWith(err, 1) && err.(*toolError).stderr == "" {
+			return "", ErrNotFound
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(out)
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (k keyringStore) Set(host string, token string) error {
+	if k.os == "darwin" {
+		// -w without a value must be the last option, security then prompts for the token
+		// (and its confirmation), so that it is not visible in the process list
+		_, err := run(token+"\n"+token+"\n", "security", "add-generic-password", "-U", "-s", keyringService, "-a", host, "-w")
+		return err
+	}
+	_, err := run(token, "secret-tool", "store", "--label=golab token for "+host, "service", keyringService, "host", host)
+	return err
+}
+
+func (k keyringStore) Erase(host string) error {
+	if k.os == "darwin" {
+		_, err := run("", "security", "delete-generic-password", "-s", keyringService, "-a", host)
+		return err
+	}
+	_, err := run("", "secret-tool", "clear", "service", keyringService, "host", host)
+	return err
+}
@@ -0,0 +1,60 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"strings"
+)
+
+// passStore keeps tokens in the standard unix password manager,
+// see https://www.passwordstore.org/
+type passStore struct{}
+
+func passEntry(host string) string {
+	return "golab/" + host
+}
+
+func (p passStore) Get(host string) (string, error) {
+	out, err := run("", "pass", "show", passEntry(host))
+	if err != nil {
+		// other errors, e.g. of gpg, must not look like a missing token
+		if failedWith(err, 1) && strings.Contains(err.Error(), "is not in the password store") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	// pass entries may contain further lines, the secret is always the first one
+	token := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (p passStore) Set(host string, token string) error {
+	_, err := run(token+"\n", "pass", "insert", "--multiline", "--force", passEntry(host))
+	return err
+}
+
+func (p passStore) Erase(host string) error {
+	_, err := run("", "pass", "rm", "--force", passEntry(host))
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package credentials provides stores for the access tokens golab uses to
+// authenticate against Gitlab, so that tokens don't have to be written into
+// the (plaintext) golab configuration file.
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+const (
+	Keyring   = "keyring"
+	Pass      = "pass"
+	File      = "file"
+	Helper    = "helper"
+	Plaintext = "plaintext"
+)
+
+// ErrNotFound is returned by a store, if no token is stored for a host.
+var ErrNotFound = errors.New("no token found in credential store")
+
+// Store persists the access token for a Gitlab host.
+type Store interface {
+	Get(host string) (string, error)
+	Set(host string, token string) error
+	Erase(host string) error
+}
+
+// Options holds the settings that are required by some of the stores.
+type Options struct {
+	// Helper is the command line of an external credential helper (helper store only)
+	Helper string
+	// File is the path of the encrypted credentials file (file store only)
+	File string
+	// Passphrase provides the passphrase for the encrypted credentials file (file store only)
+	Passphrase func() (string, error)
+}
+
+// New returns the store for the given kind. Plaintext is not handled by this
+// package, since plaintext tokens are written into the golab configuration.
+func New(kind string, options Options) (Store, error) {
+	switch kind {
+	case Keyring:
+		return newKeyringStore()
+	case Pass:
+		return passStore{}, nil
+	case File:
+		if options.File == "" {
+			return nil, errors.New("the file credential store requires a file path")
+		}
+		if options.Passphrase == nil {
+			return nil, errors.New("the file credential store requires a passphrase")
+		}
+		return fileStore{path: options.File, passphrase: options.Passphrase}, nil
+	case Helper:
+		if options.Helper == "" {
+			return nil, errors.New("the helper credential store requires a helper command")
+		}
+		return helperStore{command: options.Helper}, nil
+	}
+	return nil, errors.New("unknown credential store '" + kind + "', use one of keyring, pass, file, helper or plaintext")
+}
+
+// Key returns the key under which the token for the given Gitlab URL is stored,
+// i.e. the host (and port) of the URL.
+func Key(gitlabUrl string) string {
+	u, err := url.Parse(gitlabUrl)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(gitlabUrl, "/")
+	}
+	return u.Host
+}
+
+func run(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", &toolError{name: name, stderr: strings.TrimSpace(stderr.String()), err: err}
+	}
+	return stdout.String(), nil
+}
+
+// toolError is returned by run, if the tool could not be started or failed
+type toolError struct {
+	name   string
+	stderr string
+	err    error
+}
+
+func (e *toolError) Error() string {
+	if e.stderr != "" {
+		return e.name + ": " + e.stderr
+	}
+	return e.name + ": " + e.err.Error()
+}
+
+// failedWith returns true, if err is the error of a tool that exited with the given code
+func failedWith(err error, code int) bool {
+	toolErr, ok := err.(*toolError)
+	if !ok {
+		return false
+	}
+	exitErr, ok := toolErr.err.(*exec.ExitError)
+	return ok && exitErr.ExitCode() == code
+}
@@ -0,0 +1,197 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/michaellihs/golab/cmd/dashboard"
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/michaellihs/golab/cmd/mapper"
+	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultDashboardRefresh = 60
+
+type dashboardFlags struct {
+	Refresh *int `flag_name:"refresh" short:"r" type:"integer" required:"no" description:"Seconds between two refreshes of the dashboard (default: 60)"`
+}
+
+var dashboardCmd = &golabCommand{
+	Parent: RootCmd,
+	Flags:  &dashboardFlags{},
+	Cmd: &cobra.Command{
+		Use:     "dashboard",
+		Aliases: []string{"dash"},
+		Short:   "Interactive dashboard of merge requests, to-dos, pipelines and events",
+		Long: `Shows a full-screen dashboard with the open merge requests assigned to you, your pending to-dos,
+the running pipelines of your projects and your recent events. The dashboard is refreshed in the background.
+
+Keys:
+
+    tab, 1-4     switch between merge requests, to-dos, pipelines and events
+    up/down, j/k select a row, scroll the diff or notes
+    enter        show the diff of the selected merge request
+    d, n         show the diff or the notes of the merge request
+    a            approve the merge request
+    m, c         merge or close the merge request (asks for confirmation)
+    o            open the selection in the browser
+    r            refresh
+    esc, q       go back, quit`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*dashboardFlags)
+		refresh := defaultDashboardRefresh
+		if flags.Refresh != nil {
+			refresh = *flags.Refresh
+		}
+		if refresh <= 0 {
+			return &mapper.FlagError{Flag: "refresh", Value: strconv.Itoa(refresh), Expected: "a positive number of seconds"}
+		}
+		backend := &gitlabDashboard{projects: map[int]*gitlab.Project{}}
+		return dashboard.Run(dashboard.New(backend), os.Stdin, os.Stdout, time.Duration(refresh)*time.Second)
+	},
+}
+
+// gitlabDashboard is the backend of the dashboard, that loads the data from Gitlab
+type gitlabDashboard struct {
+	user *gitlab.User
+	// projects caches the projects of the running pipelines
+	projects map[int]*gitlab.Project
+}
+
+// Load returns the data of the dashboard, the running pipelines are taken from the projects
+// of the merge requests and the events, since Gitlab cannot list the pipelines of a user
+func (g *gitlabDashboard) Load() (*dashboard.Data, error) {
+	if g.user == nil {
+		user, _, err := gitlabClient.Users.CurrentUser()
+		if err != nil {
+			return nil, err
+		}
+		g.user = user
+	}
+	data := &dashboard.Data{User: g.user}
+
+	mergeRequests, _, err := gitlabClient.MergeRequests.ListMergeRequests(&gitlab.ListMergeRequestsOptions{
+		State: gitlab.String("opened"),
+		Scope: gitlab.String("assigned_to_me"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading merge requests failed: %s", err)
+	}
+	data.MergeRequests = mergeRequests
+
+	todos, _, err := gitlabClient.Todos.ListTodos(&gitlab.ListTodosOptions{State: gitlab.String("pending")})
+	if err != nil {
+		return nil, fmt.Errorf("loading to-dos failed: %s", err)
+	}
+	data.Todos = todos
+
+	req, err := gitlabClient.NewRequest("GET", "events", nil, []gitlab.OptionFunc{perPage(50)})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = gitlabClient.Do(req, &data.Events); err != nil {
+		return nil, fmt.Errorf("loading events failed: %s", err)
+	}
+
+	pipelines, err := g.runningPipelines(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading pipelines failed: %s", err)
+	}
+	data.Pipelines = pipelines
+	return data, nil
+}
+
+func (g *gitlabDashboard) runningPipelines(data *dashboard.Data) ([]dashboard.Pipeline, error) {
+	projectIds := map[int]bool{}
+	for _, mr := range data.MergeRequests {
+		projectIds[mr.ProjectID] = true
+	}
+	for _, event := range data.Events {
+		if event.ProjectID != 0 {
+			projectIds[event.ProjectID] = true
+		}
+	}
+	var ids []int
+	for id := range projectIds {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var pipelines []dashboard.Pipeline
+	for _, id := range ids {
+		project, ok := g.projects[id]
+		if !ok {
+			p, _, err := gitlabClient.Projects.GetProject(id)
+			if err != nil {
+				return nil, err
+			}
+			project, g.projects[id] = p, p
+		}
+		list, _, err := gitlabClient.Pipelines.ListProjectPipelines(id, queryParam("status", "running"), queryParam("username", g.user.Username))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			pipelines = append(pipelines, dashboard.Pipeline{Project: project.PathWithNamespace, ID: p.ID, Status: p.Status,
+				Ref: p.Ref, Sha: p.Sha, WebURL: project.WebURL + "/pipelines/" + strconv.Itoa(p.ID)})
+		}
+	}
+	return pipelines, nil
+}
+
+func (g *gitlabDashboard) Diff(mr *gitlab.MergeRequest) ([]FileDiff, error) {
+	return mergeRequestDiffs(strconv.Itoa(mr.ProjectID), mr.IID, nil, nil)
+}
+
+func (g *gitlabDashboard) Notes(mr *gitlab.MergeRequest) ([]*gitlab.Note, error) {
+	notes, _, err := gitlabClient.Notes.ListMergeRequestNotes(mr.ProjectID, mr.IID, queryParam("sort", "asc"), perPage(100))
+	return notes, err
+}
+
+func (g *gitlabDashboard) Approve(mr *gitlab.MergeRequest) error {
+	_, err := approveMergeRequest(strconv.Itoa(mr.ProjectID), mr.IID, nil)
+	return err
+}
+
+func (g *gitlabDashboard) Merge(mr *gitlab.MergeRequest) error {
+	_, _, err := gitlabClient.MergeRequests.AcceptMergeRequest(mr.ProjectID, mr.IID, &gitlab.AcceptMergeRequestOptions{})
+	return err
+}
+
+func (g *gitlabDashboard) Close(mr *gitlab.MergeRequest) error {
+	_, _, err := gitlabClient.MergeRequests.UpdateMergeRequest(mr.ProjectID, mr.IID, &gitlab.UpdateMergeRequestOptions{StateEvent: gitlab.String("close")})
+	return err
+}
+
+func (g *gitlabDashboard) Open(url string) error {
+	return NewBrowserHelper().Open(url)
+}
+
+func init() {
+	dashboardCmd.Init()
+}
@@ -0,0 +1,441 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package dashboard implements the full-screen terminal dashboard of golab, that
+// shows the merge requests, to-dos, running pipelines and recent events of the
+// user and allows to review, approve, merge and close merge requests.
+//
+// The Dashboard holds the state of the screen and does not depend on a terminal,
+// Run connects it to the terminal and polls the Backend in the background.
+package dashboard
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/michaellihs/golab/cmd/helpers"
+	"github.com/xanzy/go-gitlab"
+)
+
+// Data is the content of the dashboard as loaded by the Backend
+type Data struct {
+	User          *gitlab.User
+	MergeRequests []*gitlab.MergeRequest
+	Todos         []*gitlab.Todo
+	Pipelines     []Pipeline
+	Events        []Event
+}
+
+// Pipeline is a running pipeline, the pipeline lists of the API don't contain the project
+type Pipeline struct {
+	Project string
+	ID      int
+	Status  string
+	Ref     string
+	Sha     string
+	WebURL  string
+}
+
+// Event is an event of the user, go-gitlab provides no type for it,
+// see https://docs.gitlab.com/ce/api/events.html
+type Event struct {
+	ID             int        `json:"id"`
+	ProjectID      int        `json:"project_id"`
+	ActionName     string     `json:"action_name"`
+	TargetID       int        `json:"target_id"`
+	TargetType     string     `json:"target_type"`
+	TargetTitle    string     `json:"target_title"`
+	AuthorUsername string     `json:"author_username"`
+	CreatedAt      *time.Time `json:"created_at"`
+	PushData       *struct {
+		Ref         string `json:"ref"`
+		CommitTitle string `json:"commit_title"`
+	} `json:"push_data"`
+}
+
+// Backend loads the data of the dashboard and executes the actions of the user
+type Backend interface {
+	Load() (*Data, error)
+	Diff(mr *gitlab.MergeRequest) ([]helpers.FileDiff, error)
+	Notes(mr *gitlab.MergeRequest) ([]*gitlab.Note, error)
+	Approve(mr *gitlab.MergeRequest) error
+	Merge(mr *gitlab.MergeRequest) error
+	Close(mr *gitlab.MergeRequest) error
+	Open(url string) error
+}
+
+const (
+	mergeRequestsTab = iota
+	todosTab
+	pipelinesTab
+	eventsTab
+)
+
+var tabNames = []string{"Merge Requests", "To-Dos", "Pipelines", "Events"}
+
+const (
+	diffView  = "diff"
+	notesView = "notes"
+)
+
+// task is run in the background, the returned update is applied to the dashboard afterwards
+type task func() update
+
+type update func(d *Dashboard)
+
+// Dashboard is the state of the dashboard screen
+type Dashboard struct {
+	backend Backend
+	data    *Data
+	err     error
+	updated time.Time
+	loading bool
+	tab     int
+	cursor  [4]int
+	detail  *detail
+	confirm *confirmation
+	status  string
+	quit    bool
+	// height is the height of the terminal, used for paging
+	height int
+	// do runs tasks, synchronously unless the dashboard is connected to a terminal by Run
+	do func(t task)
+}
+
+// detail is the view of a single merge request
+type detail struct {
+	mr     *gitlab.MergeRequest
+	view   string
+	lines  []string
+	offset int
+}
+
+// confirmation is an action that waits for the user to confirm it
+type confirmation struct {
+	prompt string
+	action task
+}
+
+var now = time.Now
+
+// New returns a dashboard that shows the data of the given backend
+func New(backend Backend) *Dashboard {
+	d := &Dashboard{backend: backend, height: 24}
+	d.do = func(t task) {
+		t()(d)
+	}
+	return d
+}
+
+// Done returns true, once the user quit the dashboard
+func (d *Dashboard) Done() bool {
+	return d.quit
+}
+
+// Refresh loads the data of the dashboard, unless it is already loading
+func (d *Dashboard) Refresh() {
+	if d.loading {
+		return
+	}
+	d.loading = true
+	d.do(func() update {
+		data, err := d.backend.Load()
+		return func(d *Dashboard) {
+			d.loading = false
+			d.err = err
+			if err == nil {
+				d.data, d.updated = data, now()
+				for tab := range d.cursor {
+					d.cursor[tab] = clamp(d.cursor[tab], d.rows(tab))
+				}
+			}
+		}
+	})
+}
+
+// HandleKey changes the dashboard according to a key pressed by the user
+func (d *Dashboard) HandleKey(key Key) {
+	if key == KeyCtrlC {
+		d.quit = true
+		return
+	}
+	if d.confirm != nil {
+		confirm := d.confirm
+		d.confirm = nil
+		if key == "y" || key == "Y" {
+			d.status = ""
+			d.do(confirm.action)
+		} else {
+			d.status = "cancelled"
+		}
+		return
+	}
+	d.status = ""
+	if d.detail != nil {
+		d.handleDetailKey(key)
+	} else {
+		d.handleListKey(key)
+	}
+}
+
+func (d *Dashboard) handleListKey(key Key) {
+	switch key {
+	case "q", KeyEscape:
+		d.quit = true
+	case KeyTab, KeyRight, "l":
+		d.tab = (d.tab + 1) % len(tabNames)
+	case KeyBacktab, KeyLeft, "h":
+		d.tab = (d.tab + len(tabNames) - 1) % len(tabNames)
+	case "1", "2", "3", "4":
+		d.tab = int(key[0] - '1')
+	case KeyUp, "k":
+		d.move(-1)
+	case KeyDown, "j":
+		d.move(1)
+	case KeyPageUp:
+		d.move(-d.pageSize())
+	case KeyPageDown, " ":
+		d.move(d.pageSize())
+	case KeyHome, "g":
+		d.move(-d.rows(d.tab))
+	case KeyEnd, "G":
+		d.move(d.rows(d.tab))
+	case "r":
+		d.Refresh()
+	case KeyEnter:
+		if mr := d.selectedMergeRequest(); mr != nil {
+			d.openDetail(mr, diffView)
+		} else if d.tab == todosTab || d.tab == pipelinesTab {
+			d.open(d.selectedURL())
+		}
+	case "o":
+		d.open(d.selectedURL())
+	case "a", "m", "c":
+		if mr := d.selectedMergeRequest(); mr != nil {
+			d.act(key, mr)
+		} else {
+			d.status = "select a merge request first"
+		}
+	}
+}
+
+func (d *Dashboard) handleDetailKey(key Key) {
+	mr := d.detail.mr
+	switch key {
+	case "q", KeyEscape, KeyBackspace:
+		d.detail = nil
+	case KeyUp, "k":
+		d.scroll(-1)
+	case KeyDown, "j", KeyEnter:
+		d.scroll(1)
+	case KeyPageUp:
+		d.scroll(-d.pageSize())
+	case KeyPageDown, " ":
+		d.scroll(d.pageSize())
+	case KeyHome, "g":
+		d.scroll(-len(d.detail.lines))
+	case KeyEnd, "G":
+		d.scroll(len(d.detail.lines))
+	case "d":
+		d.openDetail(mr, diffView)
+	case "n":
+		d.openDetail(mr, notesView)
+	case "r":
+		d.openDetail(mr, d.detail.view)
+	case "o":
+		d.open(mr.WebURL)
+	case "a", "m", "c":
+		d.act(key, mr)
+	}
+}
+
+// act approves the merge request right away, merging and closing need a confirmation
+func (d *Dashboard) act(key Key, mr *gitlab.MergeRequest) {
+	switch key {
+	case "a":
+		d.status = fmt.Sprintf("approving !%d ...", mr.IID)
+		d.do(d.action(mr, "approved", d.backend.Approve))
+	case "m":
+		d.confirm = &confirmation{prompt: fmt.Sprintf("Merge !%d %s? [y/N]", mr.IID, mr.Title), action: d.action(mr, "merged", d.backend.Merge)}
+	case "c":
+		d.confirm = &confirmation{prompt: fmt.Sprintf("Close !%d %s? [y/N]", mr.IID, mr.Title), action: d.action(mr, "closed", d.backend.Close)}
+	}
+}
+
+// action returns a task that runs the action and refreshes the dashboard, if the action succeeded
+func (d *Dashboard) action(mr *gitlab.MergeRequest, done string, action func(mr *gitlab.MergeRequest) error) task {
+	return func() update {
+		err := action(mr)
+		return func(d *Dashboard) {
+			if err != nil {
+				d.status = fmt.Sprintf("!%d: %s", mr.IID, err)
+				return
+			}
+			d.status = fmt.Sprintf("%s !%d", done, mr.IID)
+			d.Refresh()
+		}
+	}
+}
+
+func (d *Dashboard) open(url string) {
+	if url == "" {
+		d.status = "nothing to open"
+		return
+	}
+	if err := d.backend.Open(url); err != nil {
+		d.status = "cannot open browser: " + err.Error()
+	}
+}
+
+// openDetail shows the diff or the notes of the merge request
+func (d *Dashboard) openDetail(mr *gitlab.MergeRequest, view string) {
+	d.detail = &detail{mr: mr, view: view, lines: []string{"loading " + view + " ..."}}
+	d.do(func() update {
+		lines, err := d.detailLines(mr, view)
+		return func(d *Dashboard) {
+			// the user might have left the view in the meantime
+			if d.detail == nil || d.detail.mr != mr || d.detail.view != view {
+				return
+			}
+			if err != nil {
+				lines = []string{"error: " + err.Error()}
+			}
+			d.detail.lines, d.detail.offset = lines, 0
+		}
+	})
+}
+
+func (d *Dashboard) detailLines(mr *gitlab.MergeRequest, view string) ([]string, error) {
+	var buf bytes.Buffer
+	if view == diffView {
+		diffs, err := d.backend.Diff(mr)
+		if err != nil {
+			return nil, err
+		}
+		if len(diffs) == 0 {
+			return []string{"no changes"}, nil
+		}
+		helpers.DiffRenderer{Out: &buf}.Render(diffs)
+	} else {
+		notes, err := d.backend.Notes(mr)
+		if err != nil {
+			return nil, err
+		}
+		if len(notes) == 0 {
+			return []string{"no notes"}, nil
+		}
+		for _, note := range notes {
+			fmt.Fprintf(&buf, "@%s %s\n", note.Author.Username, formatTime(note.CreatedAt, "2006-01-02 15:04"))
+			for _, line := range strings.Split(strings.TrimSpace(note.Body), "\n") {
+				fmt.Fprintln(&buf, "    "+line)
+			}
+			fmt.Fprintln(&buf)
+		}
+	}
+	text := strings.Replace(strings.TrimSuffix(buf.String(), "\n"), "\t", "    ", -1)
+	return strings.Split(strings.Replace(text, "\r", "", -1), "\n"), nil
+}
+
+func (d *Dashboard) move(delta int) {
+	d.cursor[d.tab] = clamp(d.cursor[d.tab]+delta, d.rows(d.tab))
+}
+
+func (d *Dashboard) scroll(delta int) {
+	d.detail.offset = clamp(d.detail.offset+delta, len(d.detail.lines))
+}
+
+// pageSize is the number of rows in the body of the screen
+func (d *Dashboard) pageSize() int {
+	if rows := d.height - 4; rows > 1 {
+		return rows
+	}
+	return 1
+}
+
+// rows returns the number of rows of a tab
+func (d *Dashboard) rows(tab int) int {
+	if d.data == nil {
+		return 0
+	}
+	switch tab {
+	case mergeRequestsTab:
+		return len(d.data.MergeRequests)
+	case todosTab:
+		return len(d.data.Todos)
+	case pipelinesTab:
+		return len(d.data.Pipelines)
+	default:
+		return len(d.data.Events)
+	}
+}
+
+// selectedMergeRequest returns the selected merge request or the merge request of the selected to-do
+func (d *Dashboard) selectedMergeRequest() *gitlab.MergeRequest {
+	if d.rows(d.tab) == 0 {
+		return nil
+	}
+	switch d.tab {
+	case mergeRequestsTab:
+		return d.data.MergeRequests[d.cursor[d.tab]]
+	case todosTab:
+		todo := d.data.Todos[d.cursor[d.tab]]
+		if todo.TargetType != "MergeRequest" {
+			return nil
+		}
+		return &gitlab.MergeRequest{ID: todo.Target.ID, IID: todo.Target.IID, ProjectID: todo.Target.ProjectID,
+			Title: todo.Target.Title, State: todo.Target.State, WebURL: todo.TargetURL}
+	}
+	return nil
+}
+
+func (d *Dashboard) selectedURL() string {
+	if d.rows(d.tab) == 0 {
+		return ""
+	}
+	switch d.tab {
+	case mergeRequestsTab:
+		return d.data.MergeRequests[d.cursor[d.tab]].WebURL
+	case todosTab:
+		return d.data.Todos[d.cursor[d.tab]].TargetURL
+	case pipelinesTab:
+		return d.data.Pipelines[d.cursor[d.tab]].WebURL
+	}
+	return ""
+}
+
+// clamp limits an index to the range of a list with the given length
+func clamp(index int, length int) int {
+	if index >= length {
+		index = length - 1
+	}
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+func formatTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(layout)
+}
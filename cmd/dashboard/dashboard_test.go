@@ -0,0 +1,237 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michaellihs/golab/cmd/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+type fakeBackend struct {
+	data      *Data
+	err       error
+	actionErr error
+	diffs     []helpers.FileDiff
+	notes     []*gitlab.Note
+	loads     int
+	actions   []string
+	opened    []string
+}
+
+func (b *fakeBackend) Load() (*Data, error) {
+	b.loads++
+	return b.data, b.err
+}
+
+func (b *fakeBackend) Diff(mr *gitlab.MergeRequest) ([]helpers.FileDiff, error) {
+	return b.diffs, nil
+}
+
+func (b *fakeBackend) Notes(mr *gitlab.MergeRequest) ([]*gitlab.Note, error) {
+	return b.notes, nil
+}
+
+func (b *fakeBackend) Approve(mr *gitlab.MergeRequest) error {
+	return b.act("approve", mr)
+}
+
+func (b *fakeBackend) Merge(mr *gitlab.MergeRequest) error {
+	return b.act("merge", mr)
+}
+
+func (b *fakeBackend) Close(mr *gitlab.MergeRequest) error {
+	return b.act("close", mr)
+}
+
+func (b *fakeBackend) act(action string, mr *gitlab.MergeRequest) error {
+	b.actions = append(b.actions, fmt.Sprintf("%s !%d", action, mr.IID))
+	return b.actionErr
+}
+
+func (b *fakeBackend) Open(url string) error {
+	b.opened = append(b.opened, url)
+	return nil
+}
+
+var _ = Describe("Dashboard", func() {
+
+	var (
+		backend   *fakeBackend
+		dashboard *Dashboard
+	)
+
+	mergeRequest := func(iid int, title string) *gitlab.MergeRequest {
+		mr := &gitlab.MergeRequest{IID: iid, ProjectID: 3, Title: title, SourceBranch: "feature", TargetBranch: "master",
+			WebURL: fmt.Sprintf("https://gitlab.example.com/my-group/my-project/merge_requests/%d", iid)}
+		mr.Author.Username = "someone"
+		return mr
+	}
+
+	BeforeEach(func() {
+		todo := &gitlab.Todo{ActionName: "mentioned", TargetType: "Issue", TargetURL: "https://gitlab.example.com/my-group/my-project/issues/7"}
+		todo.Project.PathWithNamespace = "my-group/my-project"
+		todo.Target.Title = "Broken build"
+		backend = &fakeBackend{
+			data: &Data{
+				User:          &gitlab.User{Username: "root"},
+				MergeRequests: []*gitlab.MergeRequest{mergeRequest(1, "Add feature"), mergeRequest(2, "Fix bug")},
+				Todos:         []*gitlab.Todo{todo},
+				Pipelines:     []Pipeline{{Project: "my-group/my-project", ID: 42, Status: "running", Ref: "master", Sha: "0123456789abcdef"}},
+			},
+			diffs: []helpers.FileDiff{{OldPath: "README.md", NewPath: "README.md", Diff: "@@ -1 +1 @@\n-old\n+new\n"}},
+			notes: []*gitlab.Note{{Body: "Looks good to me"}},
+		}
+		backend.notes[0].Author.Username = "reviewer"
+		dashboard = New(backend)
+		dashboard.Refresh()
+	})
+
+	screen := func() string {
+		return strings.Join(dashboard.Render(120, 12), "\n")
+	}
+
+	press := func(keys ...Key) {
+		for _, key := range keys {
+			dashboard.HandleKey(key)
+		}
+	}
+
+	It("shows the merge requests assigned to the user", func() {
+		Expect(screen()).To(ContainSubstring("@root"))
+		Expect(screen()).To(ContainSubstring("Merge Requests (2)"))
+		Expect(screen()).To(ContainSubstring("my-group/my-project"))
+		Expect(screen()).To(ContainSubstring("!2     Fix bug  (feature → master)  @someone"))
+	})
+
+	It("renders exactly one screen", func() {
+		lines := dashboard.Render(40, 8)
+
+		Expect(lines).To(HaveLen(8))
+		for _, line := range lines {
+			Expect(visibleLength(line)).To(BeNumerically("<=", 40))
+		}
+	})
+
+	It("switches between the tabs", func() {
+		press(KeyTab)
+		Expect(screen()).To(ContainSubstring("Broken build"))
+
+		press("3")
+		Expect(screen()).To(ContainSubstring("#42      running   01234567 master"))
+
+		press(KeyBacktab, KeyBacktab)
+		Expect(screen()).To(ContainSubstring("Add feature"))
+	})
+
+	It("shows the diff and the notes of the selected merge request", func() {
+		press(KeyDown, KeyDown, KeyEnter)
+		Expect(screen()).To(ContainSubstring("!2 Fix bug  my-group/my-project  [diff]"))
+		Expect(screen()).To(ContainSubstring("diff --git a/README.md b/README.md"))
+		Expect(screen()).To(ContainSubstring(styleGreen + "+new"))
+
+		press("n")
+		Expect(screen()).To(ContainSubstring("@reviewer"))
+		Expect(screen()).To(ContainSubstring("    Looks good to me"))
+
+		press(KeyEscape)
+		Expect(screen()).To(ContainSubstring("Merge Requests (2)"))
+	})
+
+	It("approves a merge request and refreshes the dashboard", func() {
+		press("a")
+
+		Expect(backend.actions).To(Equal([]string{"approve !1"}))
+		Expect(backend.loads).To(Equal(2))
+		Expect(screen()).To(ContainSubstring("approved !1"))
+	})
+
+	It("merges a merge request only after a confirmation", func() {
+		press("m")
+		Expect(screen()).To(ContainSubstring("Merge !1 Add feature? [y/N]"))
+		press("n")
+		Expect(backend.actions).To(BeEmpty())
+		Expect(screen()).To(ContainSubstring("cancelled"))
+
+		press(KeyDown, "m", "y")
+		Expect(backend.actions).To(Equal([]string{"merge !2"}))
+	})
+
+	It("closes the merge request shown in the detail view", func() {
+		press(KeyEnter, "c", "y")
+
+		Expect(backend.actions).To(Equal([]string{"close !1"}))
+	})
+
+	It("shows the errors of actions", func() {
+		backend.actionErr = errors.New("405 Method Not Allowed")
+
+		press("a")
+
+		Expect(screen()).To(ContainSubstring("!1: 405 Method Not Allowed"))
+	})
+
+	It("shows the errors of refreshes and keeps the data", func() {
+		backend.err = errors.New("connection refused")
+
+		press("r")
+
+		Expect(screen()).To(ContainSubstring("connection refused"))
+		Expect(screen()).To(ContainSubstring("Add feature"))
+	})
+
+	It("opens the selection in the browser", func() {
+		press("o", KeyTab, KeyEnter)
+
+		Expect(backend.opened).To(Equal([]string{
+			"https://gitlab.example.com/my-group/my-project/merge_requests/1",
+			"https://gitlab.example.com/my-group/my-project/issues/7",
+		}))
+	})
+
+	It("drills into the merge request of a to-do", func() {
+		backend.data.Todos[0].TargetType = "MergeRequest"
+		backend.data.Todos[0].TargetURL = "https://gitlab.example.com/my-group/my-project/merge_requests/5"
+		backend.data.Todos[0].Target.IID = 5
+
+		press("2", KeyEnter)
+
+		Expect(screen()).To(ContainSubstring("!5 Broken build  my-group/my-project  [diff]"))
+	})
+
+	It("quits with q and ctrl-c", func() {
+		press(KeyEnter, "q")
+		Expect(dashboard.Done()).To(BeFalse())
+
+		press("q")
+		Expect(dashboard.Done()).To(BeTrue())
+
+		dashboard = New(backend)
+		press(KeyEnter, KeyCtrlC)
+		Expect(dashboard.Done()).To(BeTrue())
+	})
+
+})
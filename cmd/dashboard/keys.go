@@ -0,0 +1,122 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dashboard
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Key is a key pressed by the user, printable keys are given as the character itself
+type Key string
+
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdn"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyTab       Key = "tab"
+	KeyBacktab   Key = "backtab"
+	KeyEscape    Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl-c"
+)
+
+// escapeSequences are the sequences sent by xterm compatible terminals for special keys
+var escapeSequences = []struct {
+	sequence string
+	key      Key
+}{
+	{"\x1b[A", KeyUp},
+	{"\x1b[B", KeyDown},
+	{"\x1b[C", KeyRight},
+	{"\x1b[D", KeyLeft},
+	{"\x1bOA", KeyUp},
+	{"\x1bOB", KeyDown},
+	{"\x1bOC", KeyRight},
+	{"\x1bOD", KeyLeft},
+	{"\x1b[5~", KeyPageUp},
+	{"\x1b[6~", KeyPageDown},
+	{"\x1b[H", KeyHome},
+	{"\x1b[F", KeyEnd},
+	{"\x1b[1~", KeyHome},
+	{"\x1b[4~", KeyEnd},
+	{"\x1b[Z", KeyBacktab},
+}
+
+// ParseKeys returns the keys for the input read from a terminal in raw mode
+func ParseKeys(input []byte) []Key {
+	var keys []Key
+	s := string(input)
+	for len(s) > 0 {
+		if s[0] == '\x1b' {
+			key, length := parseEscapeSequence(s)
+			if key != "" {
+				keys = append(keys, key)
+			}
+			s = s[length:]
+			continue
+		}
+		switch s[0] {
+		case '\r', '\n':
+			keys = append(keys, KeyEnter)
+		case '\t':
+			keys = append(keys, KeyTab)
+		case 3:
+			keys = append(keys, KeyCtrlC)
+		case 8, 127:
+			keys = append(keys, KeyBackspace)
+		default:
+			r, size := utf8.DecodeRuneInString(s)
+			if r >= ' ' {
+				keys = append(keys, Key(string(r)))
+			}
+			s = s[size:]
+			continue
+		}
+		s = s[1:]
+	}
+	return keys
+}
+
+// parseEscapeSequence returns the key for the escape sequence at the start of s and its length,
+// unknown control sequences are skipped, so that their parameters are not taken for key presses
+func parseEscapeSequence(s string) (Key, int) {
+	for _, e := range escapeSequences {
+		if strings.HasPrefix(s, e.sequence) {
+			return e.key, len(e.sequence)
+		}
+	}
+	if strings.HasPrefix(s, "\x1b[") {
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return "", i + 1
+			}
+		}
+		return "", len(s)
+	}
+	return KeyEscape, 1
+}
@@ -0,0 +1,46 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dashboard
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseKeys", func() {
+
+	It("parses printable characters and control keys", func() {
+		Expect(ParseKeys([]byte("jä\r\t\x03\x7f"))).To(Equal([]Key{"j", "ä", KeyEnter, KeyTab, KeyCtrlC, KeyBackspace}))
+	})
+
+	It("parses the escape sequences of special keys", func() {
+		Expect(ParseKeys([]byte("\x1b[A\x1bOB\x1b[5~\x1b[Z"))).To(Equal([]Key{KeyUp, KeyDown, KeyPageUp, KeyBacktab}))
+	})
+
+	It("parses a single escape as the escape key", func() {
+		Expect(ParseKeys([]byte("\x1b"))).To(Equal([]Key{KeyEscape}))
+	})
+
+	It("skips unknown control sequences including their parameters", func() {
+		Expect(ParseKeys([]byte("\x1b[1;5Aq"))).To(Equal([]Key{"q"}))
+	})
+
+})
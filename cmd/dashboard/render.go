@@ -0,0 +1,265 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleCyan    = "\x1b[36m"
+)
+
+const (
+	listHelp   = "q quit  tab switch  ↑/↓ select  enter open  a approve  m merge  c close  o browser  r refresh"
+	detailHelp = "esc back  ↑/↓ scroll  d diff  n notes  a approve  m merge  c close  o browser  r reload"
+)
+
+// Render returns the lines of the screen for a terminal of the given size, including ANSI styles
+func (d *Dashboard) Render(width int, height int) []string {
+	// paging moves by the height of the last rendered screen
+	d.height = height
+	lines := []string{d.header(width)}
+	if d.detail != nil {
+		lines = append(lines, style(styleBold, fit(d.detailTitle(), width)))
+		lines = append(lines, d.detailBody(width, d.pageSize())...)
+	} else {
+		lines = append(lines, d.tabs(width))
+		lines = append(lines, d.listBody(width, d.pageSize())...)
+	}
+	lines = append(lines, d.statusLine(width), style(styleDim, fit(d.help(), width)))
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	return lines
+}
+
+func (d *Dashboard) header(width int) string {
+	title := " golab dashboard"
+	if d.data != nil && d.data.User != nil {
+		title += "  @" + d.data.User.Username
+	}
+	state := ""
+	if d.loading {
+		state = "loading ... "
+	} else if !d.updated.IsZero() {
+		state = "updated " + d.updated.Format("15:04:05") + " "
+	}
+	if gap := width - utf8.RuneCountInString(title) - utf8.RuneCountInString(state); gap > 0 {
+		title += strings.Repeat(" ", gap) + state
+	}
+	return style(styleReverse, fit(title, width))
+}
+
+func (d *Dashboard) tabs(width int) string {
+	var tabs []string
+	for tab, name := range tabNames {
+		label := fmt.Sprintf(" %d %s", tab+1, name)
+		if d.data != nil {
+			label += fmt.Sprintf(" (%d)", d.rows(tab))
+		}
+		label += " "
+		if tab == d.tab {
+			label = style(styleReverse+styleBold, label)
+		}
+		tabs = append(tabs, label)
+	}
+	// the styles don't take space, so the tabs are cut before they are styled
+	line := strings.Join(tabs, " ")
+	if visibleLength(line) > width {
+		return fit(strings.Join(tabNames, " | "), width)
+	}
+	return line
+}
+
+func (d *Dashboard) listBody(width int, rows int) []string {
+	var body []string
+	switch {
+	case d.data == nil && d.err == nil:
+		body = append(body, " loading ...")
+	case d.data == nil:
+		body = append(body, " no data")
+	case d.rows(d.tab) == 0:
+		body = append(body, " nothing here")
+	default:
+		cursor := d.cursor[d.tab]
+		offset := 0
+		if cursor >= rows {
+			offset = cursor - rows + 1
+		}
+		for row := offset; row < d.rows(d.tab) && row < offset+rows; row++ {
+			line := fit(" "+d.row(row), width)
+			if row == cursor {
+				line = style(styleReverse, line)
+			}
+			body = append(body, line)
+		}
+	}
+	return pad(body, rows)
+}
+
+// row returns the text of a row of the current tab
+func (d *Dashboard) row(row int) string {
+	switch d.tab {
+	case mergeRequestsTab:
+		mr := d.data.MergeRequests[row]
+		return fmt.Sprintf("%s %s %s  (%s → %s)  @%s", fit(projectPath(mr.WebURL), 24), fit(fmt.Sprintf("!%d", mr.IID), 6),
+			mr.Title, mr.SourceBranch, mr.TargetBranch, mr.Author.Username)
+	case todosTab:
+		todo := d.data.Todos[row]
+		return fmt.Sprintf("%s %s %s %s  @%s", fit(todo.Project.PathWithNamespace, 24), fit(string(todo.ActionName), 18),
+			fit(todo.TargetType, 13), todo.Target.Title, todo.Author.Username)
+	case pipelinesTab:
+		pipeline := d.data.Pipelines[row]
+		sha := pipeline.Sha
+		if len(sha) > 8 {
+			sha = sha[:8]
+		}
+		return fmt.Sprintf("%s %s %s %s %s", fit(pipeline.Project, 24), fit(fmt.Sprintf("#%d", pipeline.ID), 8),
+			fit(pipeline.Status, 9), sha, pipeline.Ref)
+	default:
+		event := d.data.Events[row]
+		target := strings.TrimSpace(event.TargetType + " " + event.TargetTitle)
+		if event.PushData != nil {
+			target = strings.TrimSpace(event.PushData.Ref + " " + event.PushData.CommitTitle)
+		}
+		return fmt.Sprintf("%s @%s %s %s", formatTime(event.CreatedAt, "01-02 15:04"), event.AuthorUsername, event.ActionName, target)
+	}
+}
+
+func (d *Dashboard) detailTitle() string {
+	return fmt.Sprintf(" !%d %s  %s  [%s]", d.detail.mr.IID, d.detail.mr.Title, projectPath(d.detail.mr.WebURL), d.detail.view)
+}
+
+func (d *Dashboard) detailBody(width int, rows int) []string {
+	var body []string
+	for i := d.detail.offset; i < len(d.detail.lines) && i < d.detail.offset+rows; i++ {
+		line := fit(d.detail.lines[i], width)
+		if d.detail.view == diffView {
+			line = style(diffStyle(line), line)
+		} else if strings.HasPrefix(line, "@") {
+			line = style(styleBold, line)
+		}
+		body = append(body, line)
+	}
+	return pad(body, rows)
+}
+
+func diffStyle(line string) string {
+	for _, prefix := range []string{"diff --git", "--- ", "+++ ", "new file mode", "deleted file mode", "rename "} {
+		if strings.HasPrefix(line, prefix) {
+			return styleBold
+		}
+	}
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return styleCyan
+	case strings.HasPrefix(line, "+"):
+		return styleGreen
+	case strings.HasPrefix(line, "-"):
+		return styleRed
+	}
+	return ""
+}
+
+func (d *Dashboard) statusLine(width int) string {
+	switch {
+	case d.confirm != nil:
+		return style(styleBold, fit(" "+d.confirm.prompt, width))
+	case d.status != "":
+		return fit(" "+d.status, width)
+	case d.err != nil:
+		return style(styleRed, fit(" "+d.err.Error(), width))
+	}
+	return fit("", width)
+}
+
+func (d *Dashboard) help() string {
+	if d.detail != nil {
+		return " " + detailHelp
+	}
+	return " " + listHelp
+}
+
+// projectPath returns the path of the project from the web URL of a merge request
+func projectPath(webURL string) string {
+	path := webURL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.Index(path, "/merge_requests/"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimSuffix(path, "/-")
+}
+
+// fit cuts or pads the text to the given width
+func fit(text string, width int) string {
+	length := utf8.RuneCountInString(text)
+	if length > width {
+		if width < 1 {
+			return ""
+		}
+		return string([]rune(text)[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-length)
+}
+
+func pad(lines []string, rows int) []string {
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func style(style string, text string) string {
+	if style == "" {
+		return text
+	}
+	return style + text + styleReset
+}
+
+// visibleLength returns the length of the text without ANSI styles
+func visibleLength(text string) int {
+	length, inEscape := 0, false
+	for _, r := range text {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			inEscape = r != 'm'
+		default:
+			length++
+		}
+	}
+	return length
+}
@@ -0,0 +1,134 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dashboard
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// switch to the alternate screen and hide the cursor, so that the terminal is restored on exit
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearLine   = "\x1b[K"
+	home        = "\x1b[H"
+)
+
+// resizeInterval is the interval for checking the size of the terminal, which works on all platforms unlike SIGWINCH
+const resizeInterval = 250 * time.Millisecond
+
+// Run shows the dashboard in the terminal until the user quits, the data is refreshed every interval
+func Run(d *Dashboard, in *os.File, out io.Writer, interval time.Duration) error {
+	fd := int(in.Fd())
+	if !terminal.IsTerminal(fd) {
+		return errors.New("the dashboard needs an interactive terminal")
+	}
+	if interval <= 0 {
+		return errors.New("the refresh interval must be positive")
+	}
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore(fd, state)
+	io.WriteString(out, enterScreen)
+	defer io.WriteString(out, leaveScreen)
+
+	done := make(chan struct{})
+	defer close(done)
+	updates := make(chan update)
+	d.do = func(t task) {
+		go func() {
+			select {
+			case updates <- t():
+			case <-done:
+			}
+		}()
+	}
+	keys := make(chan Key)
+	go readKeys(in, keys, done)
+
+	refresh := time.NewTicker(interval)
+	defer refresh.Stop()
+	resize := time.NewTicker(resizeInterval)
+	defer resize.Stop()
+
+	d.Refresh()
+	screen := ""
+	for !d.Done() {
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			return err
+		}
+		// some terminals, e.g. serial consoles, don't report their size
+		if width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		// only draw changes, redrawing the same screen makes it flicker
+		if next := frame(d.Render(width, height)); next != screen {
+			screen = next
+			io.WriteString(out, screen)
+		}
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			d.HandleKey(key)
+		case u := <-updates:
+			u(d)
+		case <-refresh.C:
+			d.Refresh()
+		case <-resize.C:
+		}
+	}
+	return nil
+}
+
+// readKeys sends the keys read from the terminal to the channel, it closes the channel when reading fails
+func readKeys(in io.Reader, keys chan<- Key, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			select {
+			case keys <- key:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// frame returns the output that draws the lines over the previous screen
+func frame(lines []string) string {
+	return home + strings.Join(lines, clearLine+"\r\n") + clearLine
+}
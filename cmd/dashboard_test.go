@@ -0,0 +1,107 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/michaellihs/golab/cmd/fakegitlab"
+	"github.com/michaellihs/golab/cmd/mapper"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("dashboard", func() {
+
+	var (
+		server  *fakegitlab.Server
+		backend *gitlabDashboard
+		project *gitlab.Project
+		root    *gitlab.User
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(dashboardCmd)
+		server = fakegitlab.New()
+		gitlabClient = server.Client()
+		backend = &gitlabDashboard{projects: map[int]*gitlab.Project{}}
+		project = server.AddProject("root", "my-project")
+		root, _, _ = gitlabClient.Users.CurrentUser()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createMergeRequest := func(title string, assignee int) *gitlab.MergeRequest {
+		mr, _, err := gitlabClient.MergeRequests.CreateMergeRequest(project.ID, &gitlab.CreateMergeRequestOptions{
+			Title:        gitlab.String(title),
+			SourceBranch: gitlab.String("feature"),
+			TargetBranch: gitlab.String("master"),
+			AssigneeID:   gitlab.Int(assignee),
+		})
+		Expect(err).To(BeNil())
+		return mr
+	}
+
+	It("rejects a refresh interval that is not positive", func() {
+		_, _, err := executeCommand(RootCmd, "dashboard", "--refresh", "0")
+
+		Expect(err).To(MatchError(`invalid value "0" for flag --refresh, expected a positive number of seconds`))
+		Expect(exitCode(err)).To(Equal(exitUsageError))
+		Expect(err).To(BeAssignableToTypeOf(&mapper.FlagError{}))
+	})
+
+	It("loads the open merge requests assigned to the user", func() {
+		other := server.AddUser("someone")
+		assigned := createMergeRequest("assigned to me", root.ID)
+		createMergeRequest("assigned to someone else", other.ID)
+
+		data, err := backend.Load()
+
+		Expect(err).To(BeNil())
+		Expect(data.User.Username).To(Equal("root"))
+		Expect(data.MergeRequests).To(HaveLen(1))
+		Expect(data.MergeRequests[0].IID).To(Equal(assigned.IID))
+		Expect(data.Todos).To(BeEmpty())
+		Expect(data.Events).To(BeEmpty())
+		Expect(data.Pipelines).To(BeEmpty())
+	})
+
+	It("approves, merges and closes merge requests", func() {
+		toMerge := createMergeRequest("to merge", root.ID)
+		toClose := createMergeRequest("to close", root.ID)
+
+		Expect(backend.Approve(toMerge)).To(BeNil())
+		Expect(backend.Approve(toMerge)).NotTo(BeNil())
+		Expect(backend.Merge(toMerge)).To(BeNil())
+		Expect(backend.Close(toClose)).To(BeNil())
+		data, err := backend.Load()
+
+		Expect(err).To(BeNil())
+		Expect(data.MergeRequests).To(BeEmpty())
+		merged, _, _ := gitlabClient.MergeRequests.GetMergeRequest(project.ID, toMerge.IID)
+		closed, _, _ := gitlabClient.MergeRequests.GetMergeRequest(project.ID, toClose.IID)
+		Expect(merged.State).To(Equal("merged"))
+		Expect(closed.State).To(Equal("closed"))
+	})
+
+})
@@ -63,7 +63,7 @@ var deployKeysListAllCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/deploy_keys.html#list-project-deploy-keys
 type deployKeysListAllForProjectFlags struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 }
 
 var deployKeysListAllForProjectCmd = &golabCommand{
@@ -87,7 +87,7 @@ var deployKeysListAllForProjectCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/deploy_keys.html#single-deploy-key
 type deployKeysGetSingleFlags struct {
-	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	KeyId *int    `flag_name:"key_id" short:"k" type:"integer" required:"yes" description:"The ID of the deploy key"`
 }
 
@@ -111,7 +111,7 @@ var deployKeysGetSingleCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/deploy_keys.html#add-deploy-key
 type deployKeysAddFlags struct {
-	Id      *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id      *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Title   *string `flag_name:"title" short:"t" type:"string" required:"yes" description:"New deploy key's title"`
 	Key     *string `flag_name:"key" short:"k" type:"string" required:"yes" description:"New deploy key"`
 	CanPush *bool   `flag_name:"can_push" short:"p" type:"boolean" required:"no" description:"Can deploy key push to the project's repository"`
@@ -142,7 +142,7 @@ If the deploy key already exists in another project, it will be joined to curren
 
 // see https://docs.gitlab.com/ce/api/deploy_keys.html#delete-deploy-key
 type deplyKeysDeleteFlags struct {
-	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	KeyId *int    `flag_name:"key_id" short:"k" type:"integer" required:"yes" description:"The ID of the deploy key"`
 }
 
@@ -164,7 +164,7 @@ var deplyKeysDeleteCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/deploy_keys.html#enable-a-deploy-key
 type deployKeysEnableFlags struct {
-	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id    *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	KeyId *int    `flag_name:"key_id" short:"k" type:"integer" required:"yes" description:"The ID of the deploy key"`
 }
 
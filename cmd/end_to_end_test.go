@@ -0,0 +1,196 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/michaellihs/golab/cmd/fakegitlab"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+// these tests run the commands end-to-end against the in-memory fake Gitlab
+var _ = Describe("commands against a fake Gitlab", func() {
+
+	var server *fakegitlab.Server
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		for _, cmd := range []*golabCommand{projectCreateCmd, projectGetCmd, projectDeleteCmd, labelsCreateCmd, labelsListCmd,
+			labelsDeleteCmd, groupMemberAddCmd, groupMembersLsCmd, groupMemberEditCmd, searchCmd, mergeRequestsApproveCmd,
+			mergeRequestsUnapproveCmd, mergeRequestsApprovalsCmd, userCreateCmd, userLsCmd, userDeleteCmd, groupCreateCmd,
+			groupGetCmd, groupDeleteCmd, mergeRequestsCreateCmd, mergeRequestUpdateCmd, mergeRequestAcceptCmd, mergeRequestGetCmd,
+			projectStarCmd, projectArchiveCmd, projectHousekeepingCmd} {
+			resetFlags(cmd)
+		}
+		server = fakegitlab.New()
+		gitlabClient = server.Client()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	golab := func(args ...string) string {
+		stdout, _, err := executeCommand(RootCmd, args...)
+		Expect(err).To(BeNil())
+		return stdout
+	}
+
+	It("creates, reads and deletes a project", func() {
+		group := server.AddGroup("my-group")
+
+		created := &gitlab.Project{}
+		Expect(json.Unmarshal([]byte(golab("project", "create", "-n", "my-project", "--namespace_id", strconv.Itoa(group.ID), "--description", "golab test")), created)).To(BeNil())
+		project := &gitlab.Project{}
+		Expect(json.Unmarshal([]byte(golab("project", "get", "-i", "my-group/my-project")), project)).To(BeNil())
+		golab("project", "delete", "-i", strconv.Itoa(project.ID))
+		_, _, err := executeCommand(RootCmd, "project", "get", "-i", "my-group/my-project")
+
+		Expect(project.ID).To(Equal(created.ID))
+		Expect(project.Description).To(Equal("golab test"))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("404 Project Not Found"))
+	})
+
+	It("manages the labels of a project", func() {
+		server.AddProject("root", "my-project")
+
+		golab("labels", "create", "-i", "root/my-project", "-n", "bug", "-c", "#FF0000")
+		golab("labels", "create", "-i", "root/my-project", "-n", "feature", "-c", "#00FF00")
+		golab("labels", "delete", "-i", "root/my-project", "-n", "bug")
+		labels := []*gitlab.Label{}
+		Expect(json.Unmarshal([]byte(golab("labels", "list", "-i", "root/my-project")), &labels)).To(BeNil())
+
+		Expect(labels).To(HaveLen(1))
+		Expect(labels[0].Name).To(Equal("feature"))
+	})
+
+	It("manages the members of a group", func() {
+		group, user := server.AddGroup("my-group"), server.AddUser("jdoe")
+
+		golab("group-members", "add", "-i", strconv.Itoa(group.ID), "-u", strconv.Itoa(user.ID), "-a", "30")
+		golab("group-members", "edit", "-i", strconv.Itoa(group.ID), "-u", strconv.Itoa(user.ID), "-a", "40", "-e", "2030-01-31")
+		members := []*gitlab.GroupMember{}
+		Expect(json.Unmarshal([]byte(golab("group-members", "ls", "-i", strconv.Itoa(group.ID))), &members)).To(BeNil())
+
+		Expect(members).To(HaveLen(1))
+		Expect(members[0].Username).To(Equal("jdoe"))
+		Expect(members[0].AccessLevel).To(Equal(gitlab.MasterPermissions))
+		Expect(time.Time(*members[0].ExpiresAt).Format("2006-01-02")).To(Equal("2030-01-31"))
+	})
+
+	It("creates, lists and deletes a user", func() {
+		created := &gitlab.User{}
+		Expect(json.Unmarshal([]byte(golab("user", "create", "-e", "jdoe@my-domain.com", "-u", "jdoe", "-n", "John Doe", "-p", "secret-password")), created)).To(BeNil())
+		users := []*gitlab.User{}
+		Expect(json.Unmarshal([]byte(golab("user", "ls")), &users)).To(BeNil())
+		golab("user", "delete", "-i", "jdoe")
+		_, _, err := executeCommand(RootCmd, "user", "delete", "-i", "jdoe")
+
+		Expect(created.Username).To(Equal("jdoe"))
+		Expect(users).To(ContainElement(WithTransform(func(u *gitlab.User) string { return u.Username }, Equal("jdoe"))))
+		Expect(err).To(MatchError("Number of users found for username: 0"))
+	})
+
+	It("creates, reads and deletes a group", func() {
+		created := &gitlab.Group{}
+		Expect(json.Unmarshal([]byte(golab("group", "create", "-n", "My Group", "-p", "my-group", "--visibility", "private")), created)).To(BeNil())
+		group := &gitlab.Group{}
+		Expect(json.Unmarshal([]byte(golab("group", "get", "--id", "my-group")), group)).To(BeNil())
+		golab("group", "delete", "--id", strconv.Itoa(group.ID))
+		_, _, err := executeCommand(RootCmd, "group", "get", "--id", "my-group")
+
+		Expect(group.ID).To(Equal(created.ID))
+		Expect(group.Name).To(Equal("My Group"))
+		Expect(group.Visibility).To(Equal(gitlab.Visibility(gitlab.PrivateVisibility)))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("404"))
+	})
+
+	It("creates, updates and merges a merge request", func() {
+		server.AddProject("root", "my-project")
+
+		created := &gitlab.MergeRequest{}
+		Expect(json.Unmarshal([]byte(golab("mr", "create", "-i", "root/my-project", "-s", "feature", "-t", "master", "-n", "Add feature")), created)).To(BeNil())
+		iid := strconv.Itoa(created.IID)
+		golab("mr", "update", "-i", "root/my-project", "-m", iid, "--title", "Add open command")
+		golab("mr", "accept", "-i", "root/my-project", "-m", iid)
+		mr := &gitlab.MergeRequest{}
+		Expect(json.Unmarshal([]byte(golab("mr", "get", "-i", "root/my-project", "-m", iid)), mr)).To(BeNil())
+
+		Expect(mr.Title).To(Equal("Add open command"))
+		Expect(mr.SourceBranch).To(Equal("feature"))
+		Expect(mr.State).To(Equal("merged"))
+	})
+
+	It("stars, archives and cleans up a project", func() {
+		server.AddProject("root", "my-project")
+
+		starred, archived := &gitlab.Project{}, &gitlab.Project{}
+		Expect(json.Unmarshal([]byte(golab("project", "star", "-i", "root/my-project")), starred)).To(BeNil())
+		Expect(json.Unmarshal([]byte(golab("project", "archive", "-i", "root/my-project")), archived)).To(BeNil())
+		golab("project", "housekeeping", "-i", "root/my-project")
+
+		Expect(starred.StarCount).To(Equal(1))
+		Expect(archived.Archived).To(BeTrue())
+		Expect(golab("project", "housekeeping", "-i", "root/my-project")).To(MatchJSON(`{"project_id": "root/my-project", "status": "conflict"}`))
+	})
+
+	It("searches all resources", func() {
+		server.AddGroup("golab-group")
+		server.AddProject("golab-group", "golab")
+
+		results := map[string][]map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(golab("search", "-s", "golab")), &results)).To(BeNil())
+
+		Expect(results["projects"]).To(HaveLen(1))
+		Expect(results["groups"]).To(HaveLen(1))
+		Expect(results["users"]).To(BeEmpty())
+		Expect(results["namespaces"]).To(HaveLen(1))
+	})
+
+	It("approves and unapproves a merge request", func() {
+		project := server.AddProject("root", "my-project")
+		title, source, target := "release", "release", "master"
+		mr, _, err := gitlabClient.MergeRequests.CreateMergeRequest(project.ID, &gitlab.CreateMergeRequestOptions{
+			Title: &title, SourceBranch: &source, TargetBranch: &target})
+		Expect(err).To(BeNil())
+
+		approved := &gitlab.MergeRequestApprovals{}
+		Expect(json.Unmarshal([]byte(golab("mr", "approve", "-i", "root/my-project", "-m", strconv.Itoa(mr.IID))), approved)).To(BeNil())
+		_, _, err = executeCommand(RootCmd, "mr", "approve", "-i", "root/my-project", "-m", strconv.Itoa(mr.IID))
+		Expect(err).NotTo(BeNil())
+		unapproved := &gitlab.MergeRequestApprovals{}
+		Expect(json.Unmarshal([]byte(golab("mr", "unapprove", "-i", "root/my-project", "-m", strconv.Itoa(mr.IID))), unapproved)).To(BeNil())
+		approvals := &gitlab.MergeRequestApprovals{}
+		Expect(json.Unmarshal([]byte(golab("mr", "approvals", "-i", "root/my-project", "-m", strconv.Itoa(mr.IID))), approvals)).To(BeNil())
+
+		Expect(approved.ApprovedBy).To(HaveLen(1))
+		Expect(approved.ApprovedBy[0].User.Username).To(Equal("root"))
+		Expect(unapproved.ApprovedBy).To(BeEmpty())
+		Expect(approvals.ApprovedBy).To(BeEmpty())
+	})
+})
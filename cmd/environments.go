@@ -45,7 +45,7 @@ var environmentsCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/environments.html#list-environments
 type environmentsListFlag struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 }
 
 var environmentsListCmd = &golabCommand{
@@ -71,7 +71,7 @@ var environmentsListCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/environments.html#create-a-new-environment
 type environmentsCreateFlags struct {
-	Id          *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id          *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name        *string `flag_name:"name" type:"string" required:"yes" description:"The name of the environment"`
 	ExternalURL *string `flag_name:"external_url" type:"string" required:"no" description:"Place to link to for this environment"`
 }
@@ -98,7 +98,7 @@ var environmentsCreateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/environments.html#delete-an-environment
 type environmentsDeleteFlags struct {
-	Id            *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id            *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	EnvironmentId *int    `flag_name:"environment_id" short:"e" type:"integer" required:"yes" description:"The ID of the environment"`
 }
 
@@ -120,7 +120,7 @@ var environmentsDeleteCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/environments.html#edit-an-existing-environment
 type environmentsEditFlags struct {
-	Id            *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id            *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	EnvironmentId *int    `flag_name:"environment_id" short:"e" type:"integer" required:"yes" description:"The ID of the environment"`
 	Name          *string `flag_name:"name" type:"string" required:"no" description:"The new name of the environment"`
 	ExternalURL   *string `flag_name:"external_url" type:"string" required:"yes" description:"The new external_url"`
@@ -152,7 +152,7 @@ It returns 200 if the environment was successfully updated. In case of an error,
 // see https://docs.gitlab.com/ce/api/environments.html#stop-an-environment
 /* TODO not implemented in go-gitlab yet
 type environmentsStopFlags struct {
-	Id            *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id            *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	EnvironmentId *int    `flag_name:"environment_id" short:"e" type:"integer" required:"yes" description:"The ID of the environment"`
 }
 
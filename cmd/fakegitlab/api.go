@@ -0,0 +1,754 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fakegitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// apiRoutes returns the implemented endpoints of the API, see https://docs.gitlab.com/ce/api/
+func (s *Server) apiRoutes() []route {
+	routes := []struct {
+		method  string
+		pattern string
+		handler func(r *request)
+	}{
+		{"GET", "user", s.getCurrentUser},
+		{"GET", "users", s.listUsers},
+		{"POST", "users", s.createUser},
+		{"GET", "users/:id", s.getUser},
+		{"DELETE", "users/:id", s.deleteUser},
+
+		{"GET", "projects", s.listProjects},
+		{"POST", "projects", s.createProject},
+		{"GET", "projects/:id", s.getProject},
+		{"PUT", "projects/:id", s.editProject},
+		{"DELETE", "projects/:id", s.deleteProject},
+		{"POST", "projects/:id/star", s.starProject(1)},
+		{"POST", "projects/:id/unstar", s.starProject(-1)},
+		{"POST", "projects/:id/archive", s.archiveProject(true)},
+		{"POST", "projects/:id/unarchive", s.archiveProject(false)},
+		{"POST", "projects/:id/housekeeping", s.startHousekeeping},
+
+		{"GET", "projects/:id/labels", s.listLabels},
+		{"POST", "projects/:id/labels", s.createLabel},
+		{"DELETE", "projects/:id/labels", s.deleteLabel},
+
+		{"GET", "merge_requests", s.listAllMergeRequests},
+		{"GET", "projects/:id/merge_requests", s.listMergeRequests},
+		{"POST", "projects/:id/merge_requests", s.createMergeRequest},
+		{"GET", "projects/:id/merge_requests/:iid", s.getMergeRequest},
+		{"PUT", "projects/:id/merge_requests/:iid", s.updateMergeRequest},
+		{"PUT", "projects/:id/merge_requests/:iid/merge", s.acceptMergeRequest},
+		{"GET", "projects/:id/merge_requests/:iid/approvals", s.getMergeRequestApprovals},
+		{"POST", "projects/:id/merge_requests/:iid/approve", s.approveMergeRequest},
+		{"POST", "projects/:id/merge_requests/:iid/unapprove", s.unapproveMergeRequest},
+		{"GET", "projects/:id/merge_requests/:iid/notes", s.listEmpty},
+
+		// the fake has no CI, to-dos and events
+		{"GET", "projects/:id/pipelines", s.listEmpty},
+		{"GET", "todos", s.listEmpty},
+		{"GET", "events", s.listEmpty},
+
+		{"GET", "groups", s.listGroups},
+		{"POST", "groups", s.createGroup},
+		{"GET", "groups/:id", s.getGroup},
+		{"DELETE", "groups/:id", s.deleteGroup},
+		{"GET", "groups/:id/members", s.listGroupMembers},
+		{"POST", "groups/:id/members", s.addGroupMember},
+		{"GET", "groups/:id/members/:user_id", s.getGroupMember},
+		{"PUT", "groups/:id/members/:user_id", s.editGroupMember},
+		{"DELETE", "groups/:id/members/:user_id", s.removeGroupMember},
+
+		{"GET", "namespaces", s.listNamespaces},
+	}
+	var result []route
+	for _, r := range routes {
+		result = append(result, route{method: r.method, pattern: strings.Split(r.pattern, "/"), handler: r.handler})
+	}
+	return result
+}
+
+// matchesSearch checks the search query parameter, which matches parts of the given names
+func matchesSearch(r *request, names ...string) bool {
+	search := strings.ToLower(r.URL.Query().Get("search"))
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// users
+
+func (s *Server) user(id int) *gitlab.User {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *Server) getCurrentUser(r *request) {
+	r.respond(http.StatusOK, s.root)
+}
+
+func (s *Server) listUsers(r *request) {
+	username := r.URL.Query().Get("username")
+	var users []interface{}
+	for _, user := range s.users {
+		if (username == "" || user.Username == username) && matchesSearch(r, user.Username, user.Name, user.Email) {
+			users = append(users, user)
+		}
+	}
+	r.paginate(users)
+}
+
+func (s *Server) createUser(r *request) {
+	opts := &gitlab.CreateUserOptions{}
+	if !r.decode(opts) {
+		return
+	}
+	if opts.Username == nil || opts.Email == nil || opts.Name == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - email, name and username are required")
+		return
+	}
+	for _, user := range s.users {
+		if user.Username == *opts.Username {
+			writeError(r.w, http.StatusConflict, "Username has already been taken")
+			return
+		}
+	}
+	r.respond(http.StatusCreated, s.addUser(*opts.Username, *opts.Email, *opts.Name))
+}
+
+func (s *Server) getUser(r *request) {
+	if user := s.user(r.intParam("id")); user != nil {
+		r.respond(http.StatusOK, user)
+		return
+	}
+	r.notFound("User")
+}
+
+func (s *Server) deleteUser(r *request) {
+	for i, user := range s.users {
+		if user.ID == r.intParam("id") {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			r.w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	r.notFound("User")
+}
+
+// projects
+
+// project returns the project with the ID or path (namespace/project) given in the :id parameter
+func (s *Server) project(r *request) *gitlab.Project {
+	id := r.params["id"]
+	for _, project := range s.projects {
+		if strconv.Itoa(project.ID) == id || project.PathWithNamespace == id {
+			return project
+		}
+	}
+	r.notFound("Project")
+	return nil
+}
+
+func (s *Server) listProjects(r *request) {
+	query := r.URL.Query()
+	var projects []interface{}
+	for _, project := range s.projects {
+		if !matchesSearch(r, project.Name, project.Path) ||
+			(query.Get("visibility") != "" && string(project.Visibility) != query.Get("visibility")) ||
+			(query.Get("archived") != "" && strconv.FormatBool(project.Archived) != query.Get("archived")) ||
+			(query.Get("starred") == "true" && project.StarCount == 0) {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	r.paginate(projects)
+}
+
+func (s *Server) createProject(r *request) {
+	opts := &gitlab.CreateProjectOptions{}
+	if !r.decode(opts) {
+		return
+	}
+	if opts.Name == nil && opts.Path == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - name or path is required")
+		return
+	}
+	name, path := stringOr(opts.Name, ""), stringOr(opts.Path, "")
+	if name == "" {
+		name = path
+	}
+	if path == "" {
+		path = strings.ToLower(strings.Replace(name, " ", "-", -1))
+	}
+	var namespace *gitlab.ProjectNamespace
+	if opts.NamespaceID != nil {
+		for _, group := range s.groups {
+			if group.ID == *opts.NamespaceID {
+				namespace = s.namespace(group.FullPath)
+			}
+		}
+		if namespace == nil {
+			r.notFound("Namespace")
+			return
+		}
+	}
+	project := s.addProject(namespace, path, name)
+	editProject(project, (*gitlab.EditProjectOptions)(opts))
+	r.respond(http.StatusCreated, project)
+}
+
+func (s *Server) getProject(r *request) {
+	if project := s.project(r); project != nil {
+		r.respond(http.StatusOK, project)
+	}
+}
+
+func (s *Server) editProject(r *request) {
+	project := s.project(r)
+	opts := &gitlab.EditProjectOptions{}
+	if project == nil || !r.decode(opts) {
+		return
+	}
+	if opts.Name != nil {
+		project.Name = *opts.Name
+	}
+	editProject(project, opts)
+	r.respond(http.StatusOK, project)
+}
+
+// editProject applies the options that are shared by creating and editing a project
+func editProject(project *gitlab.Project, opts *gitlab.EditProjectOptions) {
+	project.Description = stringOr(opts.Description, project.Description)
+	project.DefaultBranch = stringOr(opts.DefaultBranch, project.DefaultBranch)
+	if opts.Visibility != nil {
+		project.Visibility = *opts.Visibility
+	}
+	if opts.IssuesEnabled != nil {
+		project.IssuesEnabled = *opts.IssuesEnabled
+	}
+	if opts.MergeRequestsEnabled != nil {
+		project.MergeRequestsEnabled = *opts.MergeRequestsEnabled
+	}
+	if opts.TagList != nil {
+		project.TagList = *opts.TagList
+	}
+}
+
+func (s *Server) deleteProject(r *request) {
+	project := s.project(r)
+	if project == nil {
+		return
+	}
+	for i, p := range s.projects {
+		if p == project {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+		}
+	}
+	r.respond(http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+}
+
+func (s *Server) starProject(delta int) func(r *request) {
+	return func(r *request) {
+		if project := s.project(r); project != nil {
+			project.StarCount = max(project.StarCount+delta, 0)
+			r.respond(http.StatusCreated, project)
+		}
+	}
+}
+
+func (s *Server) archiveProject(archived bool) func(r *request) {
+	return func(r *request) {
+		if project := s.project(r); project != nil {
+			project.Archived = archived
+			r.respond(http.StatusCreated, project)
+		}
+	}
+}
+
+// startHousekeeping starts a housekeeping, which keeps running until the server is closed
+func (s *Server) startHousekeeping(r *request) {
+	project := s.project(r)
+	if project == nil {
+		return
+	}
+	if s.housekeeping[project.ID] {
+		writeError(r.w, http.StatusConflict, "Somebody already triggered housekeeping for this resource within the last 10 minutes")
+		return
+	}
+	s.housekeeping[project.ID] = true
+	r.w.WriteHeader(http.StatusCreated)
+}
+
+// labels
+
+func (s *Server) listLabels(r *request) {
+	project := s.project(r)
+	if project == nil {
+		return
+	}
+	var labels []interface{}
+	for _, label := range s.labels[project.ID] {
+		labels = append(labels, label)
+	}
+	r.paginate(labels)
+}
+
+func (s *Server) createLabel(r *request) {
+	project := s.project(r)
+	opts := &gitlab.CreateLabelOptions{}
+	if project == nil || !r.decode(opts) {
+		return
+	}
+	if opts.Name == nil || opts.Color == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - name and color are required")
+		return
+	}
+	for _, label := range s.labels[project.ID] {
+		if label.Name == *opts.Name {
+			writeError(r.w, http.StatusConflict, "Label already exists")
+			return
+		}
+	}
+	label := &gitlab.Label{Name: *opts.Name, Color: *opts.Color, Description: stringOr(opts.Description, "")}
+	s.labels[project.ID] = append(s.labels[project.ID], label)
+	r.respond(http.StatusCreated, label)
+}
+
+func (s *Server) deleteLabel(r *request) {
+	project := s.project(r)
+	if project == nil {
+		return
+	}
+	labels := s.labels[project.ID]
+	for i, label := range labels {
+		if label.Name == r.URL.Query().Get("name") {
+			s.labels[project.ID] = append(labels[:i], labels[i+1:]...)
+			r.w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	r.notFound("Label")
+}
+
+// merge requests
+
+func (s *Server) listMergeRequests(r *request) {
+	project := s.project(r)
+	if project == nil {
+		return
+	}
+	state := r.URL.Query().Get("state")
+	var mergeRequests []interface{}
+	for _, mr := range s.mergeRequests[project.ID] {
+		if state == "" || state == "all" || mr.State == state {
+			mergeRequests = append(mergeRequests, mr)
+		}
+	}
+	r.paginate(mergeRequests)
+}
+
+func (s *Server) createMergeRequest(r *request) {
+	project := s.project(r)
+	opts := &gitlab.CreateMergeRequestOptions{}
+	if project == nil || !r.decode(opts) {
+		return
+	}
+	if opts.SourceBranch == nil || opts.TargetBranch == nil || opts.Title == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - source_branch, target_branch and title are required")
+		return
+	}
+	now := time.Now()
+	mr := &gitlab.MergeRequest{
+		ID:              s.id(),
+		IID:             len(s.mergeRequests[project.ID]) + 1,
+		ProjectID:       project.ID,
+		SourceProjectID: project.ID,
+		TargetProjectID: project.ID,
+		Title:           *opts.Title,
+		Description:     stringOr(opts.Description, ""),
+		SourceBranch:    *opts.SourceBranch,
+		TargetBranch:    *opts.TargetBranch,
+		State:           "opened",
+		CreatedAt:       &now,
+		UpdatedAt:       &now,
+		WebURL:          project.WebURL + "/merge_requests/" + strconv.Itoa(len(s.mergeRequests[project.ID])+1),
+	}
+	mr.Author.ID, mr.Author.Username, mr.Author.Name, mr.Author.State = s.root.ID, s.root.Username, s.root.Name, s.root.State
+	if opts.AssigneeID != nil {
+		if assignee := s.user(*opts.AssigneeID); assignee != nil {
+			assign(mr, assignee)
+		}
+	}
+	s.mergeRequests[project.ID] = append(s.mergeRequests[project.ID], mr)
+	r.respond(http.StatusCreated, mr)
+}
+
+// mergeRequest returns the merge request given by the :id and :iid parameters
+func (s *Server) mergeRequest(r *request) *gitlab.MergeRequest {
+	project := s.project(r)
+	if project == nil {
+		return nil
+	}
+	for _, mr := range s.mergeRequests[project.ID] {
+		if mr.IID == r.intParam("iid") {
+			return mr
+		}
+	}
+	r.notFound("Merge Request")
+	return nil
+}
+
+// assign sets the assignee of the merge request, Assignees is a slice of an anonymous struct
+func assign(mr *gitlab.MergeRequest, user *gitlab.User) {
+	assignees, _ := json.Marshal([]*gitlab.User{user})
+	json.Unmarshal(assignees, &mr.Assignees)
+}
+
+// listAllMergeRequests lists the merge requests of all projects, the scope is assigned_to_me or created_by_me
+func (s *Server) listAllMergeRequests(r *request) {
+	query := r.URL.Query()
+	var mergeRequests []interface{}
+	for _, project := range s.projects {
+		for _, mr := range s.mergeRequests[project.ID] {
+			state := query.Get("state")
+			if state != "" && state != "all" && mr.State != state {
+				continue
+			}
+			if query.Get("scope") == "assigned_to_me" && (len(mr.Assignees) == 0 || mr.Assignees[0].ID != s.root.ID) {
+				continue
+			}
+			if query.Get("scope") == "created_by_me" && mr.Author.ID != s.root.ID {
+				continue
+			}
+			mergeRequests = append(mergeRequests, mr)
+		}
+	}
+	r.paginate(mergeRequests)
+}
+
+func (s *Server) getMergeRequest(r *request) {
+	if mr := s.mergeRequest(r); mr != nil {
+		r.respond(http.StatusOK, mr)
+	}
+}
+
+func (s *Server) updateMergeRequest(r *request) {
+	mr := s.mergeRequest(r)
+	opts := &gitlab.UpdateMergeRequestOptions{}
+	if mr == nil || !r.decode(opts) {
+		return
+	}
+	mr.Title = stringOr(opts.Title, mr.Title)
+	mr.Description = stringOr(opts.Description, mr.Description)
+	mr.TargetBranch = stringOr(opts.TargetBranch, mr.TargetBranch)
+	if opts.AssigneeID != nil {
+		if assignee := s.user(*opts.AssigneeID); assignee != nil {
+			assign(mr, assignee)
+		}
+	}
+	switch stringOr(opts.StateEvent, "") {
+	case "close":
+		mr.State = "closed"
+	case "reopen":
+		mr.State = "opened"
+	}
+	now := time.Now()
+	mr.UpdatedAt = &now
+	r.respond(http.StatusOK, mr)
+}
+
+func (s *Server) acceptMergeRequest(r *request) {
+	mr := s.mergeRequest(r)
+	if mr == nil {
+		return
+	}
+	if mr.State != "opened" {
+		writeError(r.w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	mr.State = "merged"
+	r.respond(http.StatusOK, mr)
+}
+
+// approveMergeRequest approves the merge request as root, like Gitlab it refuses to approve twice
+func (s *Server) approveMergeRequest(r *request) {
+	mr := s.mergeRequest(r)
+	if mr == nil {
+		return
+	}
+	for _, id := range s.approvals[mr.ID] {
+		if id == s.root.ID {
+			writeError(r.w, http.StatusUnauthorized, "401 Unauthorized")
+			return
+		}
+	}
+	s.approvals[mr.ID] = append(s.approvals[mr.ID], s.root.ID)
+	r.respond(http.StatusCreated, s.mergeRequestApprovals(mr))
+}
+
+// unapproveMergeRequest withdraws the approval of root, like Gitlab it answers 404 if root did not approve
+func (s *Server) unapproveMergeRequest(r *request) {
+	mr := s.mergeRequest(r)
+	if mr == nil {
+		return
+	}
+	for i, id := range s.approvals[mr.ID] {
+		if id == s.root.ID {
+			s.approvals[mr.ID] = append(s.approvals[mr.ID][:i], s.approvals[mr.ID][i+1:]...)
+			r.w.WriteHeader(http.StatusCreated)
+			return
+		}
+	}
+	writeError(r.w, http.StatusNotFound, "404 Not Found")
+}
+
+func (s *Server) getMergeRequestApprovals(r *request) {
+	mr := s.mergeRequest(r)
+	if mr == nil {
+		return
+	}
+	r.respond(http.StatusOK, s.mergeRequestApprovals(mr))
+}
+
+func (s *Server) mergeRequestApprovals(mr *gitlab.MergeRequest) map[string]interface{} {
+	approvedBy := []map[string]interface{}{}
+	for _, id := range s.approvals[mr.ID] {
+		approvedBy = append(approvedBy, map[string]interface{}{"user": s.user(id)})
+	}
+	return map[string]interface{}{"id": mr.ID, "iid": mr.IID, "project_id": mr.ProjectID,
+		"title": mr.Title, "state": mr.State, "approved_by": approvedBy}
+}
+
+func (s *Server) listEmpty(r *request) {
+	r.paginate(nil)
+}
+
+// groups
+
+// group returns the group with the ID or path given in the :id parameter
+func (s *Server) group(r *request) *gitlab.Group {
+	id := r.params["id"]
+	for _, group := range s.groups {
+		if strconv.Itoa(group.ID) == id || group.FullPath == id {
+			return group
+		}
+	}
+	r.notFound("Group")
+	return nil
+}
+
+func (s *Server) listGroups(r *request) {
+	var groups []interface{}
+	for _, group := range s.groups {
+		if matchesSearch(r, group.Name, group.Path) {
+			groups = append(groups, group)
+		}
+	}
+	r.paginate(groups)
+}
+
+func (s *Server) createGroup(r *request) {
+	opts := &gitlab.CreateGroupOptions{}
+	if !r.decode(opts) {
+		return
+	}
+	if opts.Name == nil || opts.Path == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - name and path are required")
+		return
+	}
+	for _, group := range s.groups {
+		if group.Path == *opts.Path {
+			writeError(r.w, http.StatusBadRequest, "Failed to save group {:path=>[\"has already been taken\"]}")
+			return
+		}
+	}
+	group := s.addGroup(*opts.Path, *opts.Name, stringOr(opts.Description, ""))
+	if opts.Visibility != nil {
+		group.Visibility = opts.Visibility
+	}
+	r.respond(http.StatusCreated, group)
+}
+
+func (s *Server) getGroup(r *request) {
+	group := s.group(r)
+	if group == nil {
+		return
+	}
+	group.Projects = nil
+	for _, project := range s.projects {
+		if project.Namespace.Kind == "group" && project.Namespace.ID == group.ID {
+			group.Projects = append(group.Projects, project)
+		}
+	}
+	r.respond(http.StatusOK, group)
+}
+
+func (s *Server) deleteGroup(r *request) {
+	group := s.group(r)
+	if group == nil {
+		return
+	}
+	for i, g := range s.groups {
+		if g == group {
+			s.groups = append(s.groups[:i], s.groups[i+1:]...)
+		}
+	}
+	delete(s.members, group.ID)
+	r.respond(http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+}
+
+func (s *Server) listGroupMembers(r *request) {
+	group := s.group(r)
+	if group == nil {
+		return
+	}
+	var members []interface{}
+	for _, member := range s.members[group.ID] {
+		members = append(members, member)
+	}
+	r.paginate(members)
+}
+
+func (s *Server) groupMember(r *request) (*gitlab.Group, int, *gitlab.GroupMember) {
+	group := s.group(r)
+	if group == nil {
+		return nil, 0, nil
+	}
+	for i, member := range s.members[group.ID] {
+		if member.ID == r.intParam("user_id") {
+			return group, i, member
+		}
+	}
+	r.notFound("Member")
+	return group, 0, nil
+}
+
+func (s *Server) addGroupMember(r *request) {
+	group := s.group(r)
+	opts := &gitlab.AddGroupMemberOptions{}
+	if group == nil || !r.decode(opts) {
+		return
+	}
+	if opts.UserID == nil || opts.AccessLevel == nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - user_id and access_level are required")
+		return
+	}
+	user := s.user(*opts.UserID)
+	if user == nil {
+		r.notFound("User")
+		return
+	}
+	for _, member := range s.members[group.ID] {
+		if member.ID == user.ID {
+			writeError(r.w, http.StatusConflict, "Member already exists")
+			return
+		}
+	}
+	member := &gitlab.GroupMember{ID: user.ID, Username: user.Username, Email: user.Email, Name: user.Name, State: user.State, AccessLevel: *opts.AccessLevel}
+	if err := setExpiresAt(member, opts.ExpiresAt); err != nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - expires_at is invalid")
+		return
+	}
+	s.members[group.ID] = append(s.members[group.ID], member)
+	r.respond(http.StatusCreated, member)
+}
+
+func (s *Server) getGroupMember(r *request) {
+	if _, _, member := s.groupMember(r); member != nil {
+		r.respond(http.StatusOK, member)
+	}
+}
+
+func (s *Server) editGroupMember(r *request) {
+	_, _, member := s.groupMember(r)
+	opts := &gitlab.EditGroupMemberOptions{}
+	if member == nil || !r.decode(opts) {
+		return
+	}
+	if opts.AccessLevel != nil {
+		member.AccessLevel = *opts.AccessLevel
+	}
+	if err := setExpiresAt(member, opts.ExpiresAt); err != nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - expires_at is invalid")
+		return
+	}
+	r.respond(http.StatusOK, member)
+}
+
+func (s *Server) removeGroupMember(r *request) {
+	group, i, member := s.groupMember(r)
+	if member == nil {
+		return
+	}
+	s.members[group.ID] = append(s.members[group.ID][:i], s.members[group.ID][i+1:]...)
+	r.w.WriteHeader(http.StatusNoContent)
+}
+
+func setExpiresAt(member *gitlab.GroupMember, expiresAt *string) error {
+	if expiresAt == nil || *expiresAt == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", *expiresAt)
+	if err != nil {
+		return err
+	}
+	isoTime := gitlab.ISOTime(t)
+	member.ExpiresAt = &isoTime
+	return nil
+}
+
+// namespaces
+
+func (s *Server) listNamespaces(r *request) {
+	var namespaces []interface{}
+	for _, user := range s.users {
+		if matchesSearch(r, user.Username) {
+			namespaces = append(namespaces, &gitlab.Namespace{ID: user.ID, Name: user.Name, Path: user.Username, Kind: "user", FullPath: user.Username})
+		}
+	}
+	for _, group := range s.groups {
+		if matchesSearch(r, group.Name, group.Path) {
+			namespaces = append(namespaces, &gitlab.Namespace{ID: group.ID, Name: group.Name, Path: group.Path, Kind: "group", FullPath: group.FullPath})
+		}
+	}
+	r.paginate(namespaces)
+}
+
+func stringOr(s *string, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	return *s
+}
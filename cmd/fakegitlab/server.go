@@ -0,0 +1,304 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package fakegitlab provides an in-memory Gitlab server that implements the
+// endpoints of the Gitlab API used by golab, so that commands can be tested
+// end-to-end without a network connection or a Gitlab instance.
+//
+// Requests for endpoints that are not implemented are answered with
+// 501 Not Implemented, so that missing endpoints are easy to spot in tests.
+package fakegitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// Token is the access token of the root user, which is created with the server
+const Token = "fake-gitlab-token"
+
+// Server is a fake Gitlab server, all its state is kept in memory
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	nextId        int
+	root          *gitlab.User
+	users         []*gitlab.User
+	groups        []*gitlab.Group
+	projects      []*gitlab.Project
+	members       map[int][]*gitlab.GroupMember  // group ID => members
+	labels        map[int][]*gitlab.Label        // project ID => labels
+	mergeRequests map[int][]*gitlab.MergeRequest // project ID => merge requests
+	approvals     map[int][]int                  // merge request ID => IDs of the approving users
+	housekeeping  map[int]bool                   // project ID => housekeeping is running
+	routes        []route
+}
+
+// New starts a fake Gitlab server with a root user, stop it with Close()
+func New() *Server {
+	s := &Server{
+		members:       map[int][]*gitlab.GroupMember{},
+		labels:        map[int][]*gitlab.Label{},
+		mergeRequests: map[int][]*gitlab.MergeRequest{},
+		approvals:     map[int][]int{},
+		housekeeping:  map[int]bool{},
+	}
+	s.root = s.AddUser("root")
+	s.root.IsAdmin = true
+	s.routes = s.apiRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ApiUrl returns the base URL of the API, e.g. for gitlab.Client.SetBaseURL
+func (s *Server) ApiUrl() string {
+	return s.URL + "/api/v4"
+}
+
+// Client returns a go-gitlab client for the server, authenticated as root
+func (s *Server) Client() *gitlab.Client {
+	client := gitlab.NewClient(nil, Token)
+	client.SetBaseURL(s.ApiUrl())
+	return client
+}
+
+// AddUser adds a user with the given username
+func (s *Server) AddUser(username string) *gitlab.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(username, username+"@example.com", username)
+}
+
+// AddGroup adds a top-level group with the given path
+func (s *Server) AddGroup(path string) *gitlab.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addGroup(path, path, "")
+}
+
+// AddProject adds a project to the namespace (a group path or username) with the given path
+func (s *Server) AddProject(namespace string, path string) *gitlab.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addProject(s.namespace(namespace), path, path)
+}
+
+func (s *Server) id() int {
+	s.nextId++
+	return s.nextId
+}
+
+func (s *Server) addUser(username string, email string, name string) *gitlab.User {
+	user := &gitlab.User{ID: s.id(), Username: username, Email: email, Name: name, State: "active"}
+	s.users = append(s.users, user)
+	return user
+}
+
+func (s *Server) addGroup(path string, name string, description string) *gitlab.Group {
+	visibility := gitlab.PrivateVisibility
+	group := &gitlab.Group{ID: s.id(), Path: path, Name: name, FullPath: path, FullName: name, Description: description, Visibility: &visibility}
+	s.groups = append(s.groups, group)
+	return group
+}
+
+func (s *Server) addProject(namespace *gitlab.ProjectNamespace, path string, name string) *gitlab.Project {
+	if namespace == nil {
+		namespace = s.userNamespace(s.root)
+	}
+	project := &gitlab.Project{
+		ID:                   s.id(),
+		Name:                 name,
+		Path:                 path,
+		NameWithNamespace:    namespace.Name + " / " + name,
+		PathWithNamespace:    namespace.FullPath + "/" + path,
+		Namespace:            namespace,
+		DefaultBranch:        "master",
+		Visibility:           gitlab.PrivateVisibility,
+		IssuesEnabled:        true,
+		MergeRequestsEnabled: true,
+		WebURL:               s.URL + "/" + namespace.FullPath + "/" + path,
+		HTTPURLToRepo:        s.URL + "/" + namespace.FullPath + "/" + path + ".git",
+	}
+	s.projects = append(s.projects, project)
+	return project
+}
+
+// namespace returns the namespace of the group or user with the given path
+func (s *Server) namespace(path string) *gitlab.ProjectNamespace {
+	for _, group := range s.groups {
+		if group.FullPath == path {
+			return &gitlab.ProjectNamespace{ID: group.ID, Name: group.Name, Path: group.Path, Kind: "group", FullPath: group.FullPath}
+		}
+	}
+	for _, user := range s.users {
+		if user.Username == path {
+			return s.userNamespace(user)
+		}
+	}
+	return nil
+}
+
+func (s *Server) userNamespace(user *gitlab.User) *gitlab.ProjectNamespace {
+	return &gitlab.ProjectNamespace{ID: user.ID, Name: user.Name, Path: user.Username, Kind: "user", FullPath: user.Username}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Private-Token") != Token && r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+	// split the escaped path, since project IDs can be URL-encoded paths, e.g. my-group%2Fmy-project
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/"), "/")
+	for i, segment := range segments {
+		segments[i], _ = url.PathUnescape(segment)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, route := range s.routes {
+		if params, ok := route.match(r.Method, segments); ok {
+			route.handler(&request{Request: r, params: params, w: w})
+			return
+		}
+	}
+	writeError(w, http.StatusNotImplemented, fmt.Sprintf("%s %s is not implemented by the fake Gitlab", r.Method, r.URL.Path))
+}
+
+// route maps a method and a path pattern like projects/:id/labels to a handler
+type route struct {
+	method  string
+	pattern []string
+	handler func(r *request)
+}
+
+func (rt route) match(method string, segments []string) (map[string]string, bool) {
+	if method != rt.method || len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, part := range rt.pattern {
+		if strings.HasPrefix(part, ":") {
+			params[part[1:]] = segments[i]
+		} else if part != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// request wraps a request with the parameters of its path
+type request struct {
+	*http.Request
+	params map[string]string
+	w      http.ResponseWriter
+}
+
+func (r *request) intParam(name string) int {
+	i, _ := strconv.Atoi(r.params[name])
+	return i
+}
+
+// decode decodes the JSON body of the request into the given options
+func (r *request) decode(opts interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(opts); err != nil {
+		writeError(r.w, http.StatusBadRequest, "400 Bad request - "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (r *request) respond(status int, body interface{}) {
+	r.w.Header().Set("Content-Type", "application/json")
+	r.w.WriteHeader(status)
+	json.NewEncoder(r.w).Encode(body)
+}
+
+func (r *request) notFound(resource string) {
+	writeError(r.w, http.StatusNotFound, "404 "+resource+" Not Found")
+}
+
+// paginate writes the page of the list that was requested with page and per_page, including the pagination headers
+func (r *request) paginate(list []interface{}) {
+	if list == nil {
+		list = []interface{}{}
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	totalPages := (len(list) + perPage - 1) / perPage
+	start, end := min((page-1)*perPage, len(list)), min(page*perPage, len(list))
+	header := r.w.Header()
+	header.Set("X-Page", strconv.Itoa(page))
+	header.Set("X-Per-Page", strconv.Itoa(perPage))
+	header.Set("X-Total", strconv.Itoa(len(list)))
+	header.Set("X-Total-Pages", strconv.Itoa(totalPages))
+	links := []string{r.pageLink(1, "first"), r.pageLink(max(totalPages, 1), "last")}
+	if page < totalPages {
+		header.Set("X-Next-Page", strconv.Itoa(page+1))
+		links = append(links, r.pageLink(page+1, "next"))
+	}
+	if page > 1 {
+		header.Set("X-Prev-Page", strconv.Itoa(page-1))
+		links = append(links, r.pageLink(page-1, "prev"))
+	}
+	header.Set("Link", strings.Join(links, ", "))
+	r.respond(http.StatusOK, list[start:end])
+}
+
+// pageLink returns an entry of the Link header, which go-gitlab uses for pagination
+func (r *request) pageLink(page int, rel string) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return fmt.Sprintf(`<http://%s%s?%s>; rel="%s"`, r.Host, r.URL.Path, query.Encode(), rel)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
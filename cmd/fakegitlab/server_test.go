@@ -0,0 +1,108 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fakegitlab
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("fake Gitlab", func() {
+
+	var (
+		server *Server
+		client *gitlab.Client
+	)
+
+	BeforeEach(func() {
+		server = New()
+		client = server.Client()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("requires the access token", func() {
+		client = gitlab.NewClient(nil, "wrong-token")
+		client.SetBaseURL(server.ApiUrl())
+
+		_, resp, err := client.Users.CurrentUser()
+
+		Expect(err).NotTo(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("creates projects in groups and finds them by path", func() {
+		group := server.AddGroup("my-group")
+		name := "My Project"
+
+		created, _, err := client.Projects.CreateProject(&gitlab.CreateProjectOptions{Name: &name, NamespaceID: &group.ID})
+		Expect(err).To(BeNil())
+		project, _, err := client.Projects.GetProject("my-group/my-project")
+
+		Expect(err).To(BeNil())
+		Expect(project.ID).To(Equal(created.ID))
+		Expect(project.Namespace.Kind).To(Equal("group"))
+		Expect(project.WebURL).To(Equal(server.URL + "/my-group/my-project"))
+	})
+
+	It("paginates lists", func() {
+		for _, path := range []string{"one", "two", "three"} {
+			server.AddProject("root", path)
+		}
+
+		projects, resp, err := client.Projects.ListProjects(&gitlab.ListProjectsOptions{ListOptions: gitlab.ListOptions{Page: 1, PerPage: 2}})
+
+		Expect(err).To(BeNil())
+		Expect(projects).To(HaveLen(2))
+		Expect(resp.Header.Get("X-Total")).To(Equal("3"))
+		Expect(resp.NextPage).To(Equal(2))
+		Expect(resp.LastPage).To(Equal(2))
+	})
+
+	It("keeps the state of group members", func() {
+		group := server.AddGroup("my-group")
+		user := server.AddUser("jdoe")
+		opts := &gitlab.AddGroupMemberOptions{UserID: &user.ID, AccessLevel: gitlab.AccessLevel(gitlab.DeveloperPermissions)}
+
+		_, _, err := client.GroupMembers.AddGroupMember(group.ID, opts)
+		Expect(err).To(BeNil())
+		_, resp, err := client.GroupMembers.AddGroupMember(group.ID, opts)
+		Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+		_, err = client.GroupMembers.RemoveGroupMember(group.ID, user.ID)
+		Expect(err).To(BeNil())
+		_, resp, _ = client.GroupMembers.GetGroupMember(group.ID, user.ID)
+
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("answers requests for endpoints that are not implemented with 501", func() {
+		_, resp, err := client.Tags.ListTags(1, nil)
+
+		Expect(err).NotTo(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusNotImplemented))
+		Expect(err.Error()).To(ContainSubstring("is not implemented by the fake Gitlab"))
+	})
+})
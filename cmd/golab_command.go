@@ -32,6 +32,8 @@ type golabCommand struct {
 	Flags  interface{}
 	Opts   interface{}
 	Paged  bool
+	// Args holds the positional arguments of the command
+	Args   []string
 	Run    func(cmd golabCommand) error
 	Mapper mapper.FlagMapper
 	Cmd    *cobra.Command
@@ -69,6 +71,7 @@ func applyPagination(c golabCommand) error {
 
 func (c golabCommand) Init() error {
 	c.Cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		c.Args = args
 		return c.Execute()
 	}
 	c.Mapper = mapper.InitializedMapper(c.Cmd, c.Flags, c.Opts)
@@ -0,0 +1,206 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+)
+
+// see https://docs.gitlab.com/ce/api/graphql/
+type graphqlFlags struct {
+	Query    *string            `flag_name:"query" short:"q" type:"string" required:"yes" description:"File with the GraphQL query, use - to read from stdin"`
+	Vars     *map[string]string `flag_name:"var" type:"map" required:"no" description:"Add a key=value variable, values are parsed as JSON if possible (e.g. 10, true), strings otherwise"`
+	Paginate *bool              `flag_name:"paginate" type:"bool" required:"no" description:"Fetch all pages of the first connection with pageInfo, the query has to accept an $endCursor variable (default: false)"`
+}
+
+var graphqlCmd = &golabCommand{
+	Parent: RootCmd,
+	Flags:  &graphqlFlags{},
+	Cmd: &cobra.Command{
+		Use:   "graphql",
+		Short: "Send a query to the Gitlab GraphQL API",
+		Long: `Send a query to the Gitlab GraphQL API, using the URL, token and HTTP settings of golab.
+
+With --paginate, the query has to accept an $endCursor variable and has to select
+pageInfo { hasNextPage endCursor } of the connection that should be paginated:
+
+    query($fullPath: ID!, $endCursor: String) {
+      project(fullPath: $fullPath) {
+        mergeRequests(state: opened, after: $endCursor) {
+          nodes { iid title approved }
+          pageInfo { hasNextPage endCursor }
+        }
+      }
+    }
+
+Example:
+
+    golab graphql --query mrs.graphql --var fullPath=my-group/my-project --paginate`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*graphqlFlags)
+		query, err := readInput(*flags.Query)
+		if err != nil {
+			return err
+		}
+		vars := map[string]interface{}{}
+		if flags.Vars != nil {
+			for key, value := range *flags.Vars {
+				vars[key] = graphqlValue(value)
+			}
+		}
+
+		result, err := graphqlRequest(string(query), vars)
+		if err != nil {
+			return err
+		}
+		if flags.Paginate != nil && *flags.Paginate {
+			if err = graphqlPaginate(string(query), vars, result); err != nil {
+				return err
+			}
+		}
+		return OutputJson(result)
+	},
+}
+
+// graphqlValue parses numbers, booleans, lists and objects as JSON, everything else is a string
+func graphqlValue(value string) interface{} {
+	var parsed interface{}
+	if err := unmarshalJson([]byte(value), &parsed); err == nil {
+		if _, isString := parsed.(string); !isString {
+			return parsed
+		}
+	}
+	return value
+}
+
+type graphqlBody struct {
+	Query     string                 `json:"query" url:"-"`
+	Variables map[string]interface{} `json:"variables" url:"-"`
+}
+
+// graphqlRequest posts the query to the GraphQL endpoint, which is located
+// next to the REST API at /api/graphql
+func graphqlRequest(query string, vars map[string]interface{}) (map[string]interface{}, error) {
+	req, err := gitlabClient.NewRequest("POST", "", &graphqlBody{Query: query, Variables: vars}, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Opaque = strings.TrimSuffix(gitlabClient.BaseURL().Path, "v4/") + "graphql"
+
+	var content bytes.Buffer
+	if _, err = gitlabClient.Do(req, &content); err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err = unmarshalJson(content.Bytes(), &result); err != nil {
+		return nil, err
+	}
+	return result, graphqlErrors(result)
+}
+
+func graphqlErrors(result map[string]interface{}) error {
+	errs, ok := result["errors"].([]interface{})
+	if !ok || len(errs) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, e := range errs {
+		if e, ok := e.(map[string]interface{}); ok {
+			messages = append(messages, stringValue(e["message"]))
+		}
+	}
+	return errors.New("GraphQL query failed: " + strings.Join(messages, "; "))
+}
+
+// graphqlPaginate fetches all further pages of the first connection with
+// pageInfo and merges their nodes and edges into the result
+func graphqlPaginate(query string, vars map[string]interface{}, result map[string]interface{}) error {
+	connection := findConnection(result["data"])
+	if connection == nil {
+		return errors.New("--paginate requires a query that selects pageInfo { hasNextPage endCursor }")
+	}
+	for {
+		pageInfo, _ := connection["pageInfo"].(map[string]interface{})
+		endCursor := stringValue(pageInfo["endCursor"])
+		if pageInfo["hasNextPage"] != true || endCursor == "" || endCursor == stringValue(vars["endCursor"]) {
+			return nil
+		}
+		vars["endCursor"] = endCursor
+		page, err := graphqlRequest(query, vars)
+		if err != nil {
+			return err
+		}
+		next := findConnection(page["data"])
+		if next == nil {
+			return errors.New("page for cursor " + endCursor + " contains no pageInfo")
+		}
+		for _, key := range []string{"nodes", "edges"} {
+			if items, ok := next[key].([]interface{}); ok {
+				existing, _ := connection[key].([]interface{})
+				connection[key] = append(existing, items...)
+			}
+		}
+		connection["pageInfo"] = next["pageInfo"]
+	}
+}
+
+// findConnection returns the first object (in order of keys) that contains pageInfo
+func findConnection(v interface{}) map[string]interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if _, ok := v["pageInfo"]; ok {
+			return v
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if connection := findConnection(v[key]); connection != nil {
+				return connection
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if connection := findConnection(item); connection != nil {
+				return connection
+			}
+		}
+	}
+	return nil
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func init() {
+	graphqlCmd.Init()
+}
@@ -0,0 +1,91 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("graphql command", func() {
+
+	var (
+		mux       *http.ServeMux
+		server    *httptest.Server
+		queryFile *os.File
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(graphqlCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "my-token")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+		queryFile, _ = ioutil.TempFile("", "golab-query")
+		queryFile.WriteString(`query($fullPath: ID!, $first: Int, $endCursor: String) { project(fullPath: $fullPath) { mergeRequests(first: $first, after: $endCursor) { nodes { iid } pageInfo { hasNextPage endCursor } } } }`)
+		queryFile.Close()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Remove(queryFile.Name())
+	})
+
+	It("fetches all pages with --paginate", func() {
+		var requests []map[string]interface{}
+		mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+			testMethod(r, "POST")
+			Expect(r.Header.Get("PRIVATE-TOKEN")).To(Equal("my-token"))
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			requests = append(requests, body)
+			if len(requests) == 1 {
+				fmt.Fprint(w, `{"data":{"project":{"mergeRequests":{"nodes":[{"iid":"1"}],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"project":{"mergeRequests":{"nodes":[{"iid":"2"}],"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}}`)
+		})
+		stdout, _, err := executeCommand(RootCmd, "graphql", "--query", queryFile.Name(), "--var", "fullPath=my-group/my-project", "--var", "first=1", "--paginate")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(MatchJSON(`{"data":{"project":{"mergeRequests":{"nodes":[{"iid":"1"},{"iid":"2"}],"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}}`))
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0]["variables"]).To(Equal(map[string]interface{}{"fullPath": "my-group/my-project", "first": float64(1)}))
+		Expect(requests[1]["variables"]).To(HaveKeyWithValue("endCursor", "c1"))
+	})
+
+	It("returns the errors of the query", func() {
+		mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errors":[{"message":"Field 'foo' doesn't exist on type 'Project'"}]}`)
+		})
+		_, _, err := executeCommand(RootCmd, "graphql", "--query", queryFile.Name())
+		Expect(err).To(MatchError("GraphQL query failed: Field 'foo' doesn't exist on type 'Project'"))
+	})
+
+})
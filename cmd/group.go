@@ -44,7 +44,7 @@ type groupLsFlags struct {
 	AllAvailable *bool     `flag_name:"all_available" type:"bool" required:"no" description:"Show all the groups you have access to (defaults to false for authenticated users)"`
 	Search       *string   `flag_name:"search" type:"string" required:"no" description:"Return the list of authorized groups matching the search criteria"`
 	OrderBy      *string   `flag_name:"order_by" type:"string" required:"no" description:"Order groups by name or path. Default is name"`
-	Sort         *string   `flag_name:"sort" type:"string" required:"no" description:"Order groups in asc or desc order. Default is asc"`
+	Sort         *string   `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Order groups in asc or desc order. Default is asc"`
 	Statistics   *bool     `flag_name:"statistics" type:"bool" required:"no" description:"Include group statistics (admins only)"`
 	Owned        *bool     `flag_name:"owned" type:"boolean" required:"no" description:"Limit to groups owned by the current user"`
 }
@@ -72,9 +72,9 @@ var groupLsCmd = &golabCommand{
 type listGroupProjectsFlags struct {
 	Id         *string `flag_name:"id" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the group owned by the authenticated user"`
 	Archived   *bool   `flag_name:"archived" type:"bool" required:"no" description:"Limit by archived status"`
-	Visibility *string `flag_name:"visibility" type:"string" transform:"str2Visibility" required:"no" description:"Limit by visibility public, internal, or private"`
+	Visibility *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"Limit by visibility public, internal, or private"`
 	OrderBy    *string `flag_name:"order_by" type:"string" required:"no" description:"Return projects ordered by id, name, path, created_at, updated_at, or last_activity_at fields. Default is created_at"`
-	Sort       *string `flag_name:"sort" type:"string" required:"no" description:"Return projects sorted in asc or desc order. Default is desc"`
+	Sort       *string `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return projects sorted in asc or desc order. Default is desc"`
 	Search     *string `flag_name:"search" type:"string" required:"no" description:"Return list of authorized projects matching the search criteria"`
 	Simple     *bool   `flag_name:"simple" type:"bool" required:"no" description:"Return only the ID, URL, name, and path of each project"`
 	Owned      *bool   `flag_name:"owned" type:"bool" required:"no" description:"Limit by projects owned by the current user"`
@@ -129,7 +129,7 @@ type groupCreateFlags struct {
 	Name                 *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the group"`
 	Path                 *string `flag_name:"path" short:"p" type:"string" required:"yes" description:"The path of the group"`
 	Description          *string `flag_name:"description" type:"string" required:"no" description:"The group's description"`
-	Visibility           *string `flag_name:"visibility" type:"string" transform:"str2Visibility" required:"no" description:"The group's visibility. Can be private, internal, or public."`
+	Visibility           *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"The group's visibility. Can be private, internal, or public."`
 	LfsEnabled           *bool   `flag_name:"lfs_enabled" type:"bool" required:"no" description:"Enable/disable Large File Storage (LFS) for the projects in this group"`
 	RequestAccessEnabled *bool   `flag_name:"request_access_enabled" type:"bool" required:"no" description:"- Allow users to request member access."`
 	ParentId             *int    `flag_name:"parent_id" type:"int" required:"no" description:"The parent group id for creating nested group."`
@@ -187,7 +187,7 @@ type groupUpdateFlags struct {
 	Name                 *string `flag_name:"name" type:"string" required:"no" description:"The name of the group"`
 	Path                 *string `flag_name:"path" type:"string" required:"no" description:"The path of the group"`
 	Description          *string `flag_name:"description" type:"string" required:"no" description:"The description of the group"`
-	Visibility           *string `flag_name:"visibility" type:"string" transform:"str2Visibility" required:"no" description:"The visibility level of the group. Can be private, internal, or public."`
+	Visibility           *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"The visibility level of the group. Can be private, internal, or public."`
 	LfsEnabled           *bool   `flag_name:"lfs_enabled" type:"boolean" required:"no" description:"Enable/disable Large File Storage (LFS) for the projects in this group"`
 	RequestAccessEnabled *bool   `flag_name:"request_access_enabled" type:"boolean" required:"no" description:"Allow users to request member access."`
 }
@@ -22,6 +22,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 
 	. "github.com/michaellihs/golab/cmd/helpers"
 
@@ -29,33 +30,44 @@ import (
 	"github.com/xanzy/go-gitlab"
 )
 
-var accessLevel, source, target int
-
-var expiresAt string
-
-var remove bool
-
-var groupMembersCmd = &cobra.Command{
-	Use:   "group-members",
-	Short: "Access group members",
-	Long:  `Show members and access level of groups`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+// see https://docs.gitlab.com/ce/api/members.html
+var groupMembersCmd = &golabCommand{
+	Parent: RootCmd,
+	Cmd: &cobra.Command{
+		Use:   "group-members",
+		Short: "Access group members",
+		Long:  `Show members and access level of groups`,
+	},
+	Run: func(cmd golabCommand) error {
 		return errors.New("check usage of `group-members` with `golab group-members -h`")
 	},
 }
 
-var groupMembersLsCmd = &cobra.Command{
-	Use:   "ls",
-	Short: "List all members of a group",
-	Long:  `Gets a list of groupmembers viewable by the authenticated user`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if id == 0 {
-			return errors.New("required parameter `-i` or `--id`not given - exiting")
-		}
-		opts := &gitlab.ListGroupMembersOptions{
-			ListOptions: gitlab.ListOptions{Page: 1, PerPage: 1000},
+// see https://docs.gitlab.com/ce/api/members.html#list-all-members-of-a-group-or-project
+type groupMembersLsFlags struct {
+	Id *int `flag_name:"id" short:"i" type:"integer" required:"yes" description:"id of group to show members for"`
+}
+
+var groupMembersLsCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMembersLsFlags{},
+	Opts:   &gitlab.ListGroupMembersOptions{},
+	Paged:  true,
+	Cmd: &cobra.Command{
+		Use:   "ls",
+		Short: "List all members of a group",
+		Long:  `Gets a list of groupmembers viewable by the authenticated user, all pages are returned unless --page is given`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMembersLsFlags)
+		opts := cmd.Opts.(*gitlab.ListGroupMembersOptions)
+		var members []*gitlab.GroupMember
+		var err error
+		if opts.Page > 0 {
+			members, _, err = gitlabClient.Groups.ListGroupMembers(*flags.Id, opts)
+		} else {
+			members, err = listAllGroupMembers(*flags.Id, opts.PerPage)
 		}
-		members, _, err := gitlabClient.Groups.ListGroupMembers(id, opts)
 		if err != nil {
 			return err
 		}
@@ -63,18 +75,23 @@ var groupMembersLsCmd = &cobra.Command{
 	},
 }
 
-var groupMemberGetCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Get a member of a group",
-	Long:  `Get a member of a group`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if id == 0 {
-			return errors.New("required parameter `-i` or `--id`not given - exiting")
-		}
-		if userId == 0 {
-			return errors.New("required parameter `-u` or `--user_id`not given - exiting")
-		}
-		member, _, err := gitlabClient.GroupMembers.GetGroupMember(id, userId)
+// see https://docs.gitlab.com/ce/api/members.html#get-a-member-of-a-group-or-project
+type groupMemberGetFlags struct {
+	Id     *int `flag_name:"id" short:"i" type:"integer" required:"yes" description:"id of group to get member from"`
+	UserId *int `flag_name:"user_id" short:"u" type:"integer" required:"yes" description:"id of user to get group member infos"`
+}
+
+var groupMemberGetCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMemberGetFlags{},
+	Cmd: &cobra.Command{
+		Use:   "get",
+		Short: "Get a member of a group",
+		Long:  `Get a member of a group`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMemberGetFlags)
+		member, _, err := gitlabClient.GroupMembers.GetGroupMember(*flags.Id, *flags.UserId)
 		if err != nil {
 			return err
 		}
@@ -82,10 +99,7 @@ var groupMemberGetCmd = &cobra.Command{
 	},
 }
 
-var groupMemberAddCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add a member to a group",
-	Long: `Add a member to a group
+const accessLevelsUsage = `
 
   Access Levels:
 
@@ -93,25 +107,29 @@ var groupMemberAddCmd = &cobra.Command{
 	20 = Reporter Permissions
 	30 = Developer Permissions
 	40 = Master Permissions
-	50 = Owner Permissions`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if id == 0 {
-			return errors.New("required parameter `-i` or `--id` not given - exiting")
-		}
-		if userId == 0 {
-			return errors.New("required parameter `-u` or `--user_id` not given - exiting")
-		}
-		if accessLevel == 0 {
-			return errors.New("required parameter `-a` or `--access_level` not given - exiting")
-		}
-		opts := &gitlab.AddGroupMemberOptions{
-			UserID:      &userId,
-			AccessLevel: int2AccessLevel(accessLevel),
-		}
-		if expiresAt != "" {
-			opts.ExpiresAt = &expiresAt
-		}
-		member, _, err := gitlabClient.GroupMembers.AddGroupMember(id, opts)
+	50 = Owner Permissions`
+
+// see https://docs.gitlab.com/ce/api/members.html#add-a-member-to-a-group-or-project
+type groupMemberAddFlags struct {
+	Id          *int    `flag_name:"id" short:"i" type:"integer" required:"yes" description:"id of group to add new member to"`
+	UserID      *int    `flag_name:"user_id" short:"u" type:"integer" required:"yes" description:"id of user to be added as new group member"`
+	AccessLevel *string `flag_name:"access_level" short:"a" type:"integer" transform:"str2AccessLevel" required:"yes" description:"access level of new group member"`
+	ExpiresAt   *string `flag_name:"expires_at" short:"e" type:"string" required:"no" description:"expiry date of membership (yyyy-mm-dd)"`
+}
+
+var groupMemberAddCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMemberAddFlags{},
+	Opts:   &gitlab.AddGroupMemberOptions{},
+	Cmd: &cobra.Command{
+		Use:   "add",
+		Short: "Add a member to a group",
+		Long:  `Add a member to a group` + accessLevelsUsage,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMemberAddFlags)
+		opts := cmd.Opts.(*gitlab.AddGroupMemberOptions)
+		member, _, err := gitlabClient.GroupMembers.AddGroupMember(*flags.Id, opts)
 		if err != nil {
 			return err
 		}
@@ -119,35 +137,27 @@ var groupMemberAddCmd = &cobra.Command{
 	},
 }
 
-var groupMemberEditCmd = &cobra.Command{
-	Use:   "edit",
-	Short: "Edit a member of a group or project",
-	Long: `Updates a member of a group or project.
-
-  Access Levels:
+// see https://docs.gitlab.com/ce/api/members.html#edit-a-member-of-a-group-or-project
+type groupMemberEditFlags struct {
+	Id          *int    `flag_name:"id" short:"i" type:"integer" required:"yes" description:"id of group to change membership for"`
+	UserId      *int    `flag_name:"user_id" short:"u" type:"integer" required:"yes" description:"id the user to change membership for"`
+	AccessLevel *string `flag_name:"access_level" short:"a" type:"integer" transform:"str2AccessLevel" required:"yes" description:"a valid access level"`
+	ExpiresAt   *string `flag_name:"expires_at" short:"e" type:"string" required:"no" description:"expiry date of membership (yyyy-mm-dd)"`
+}
 
-	10 = Guest Permissions
-	20 = Reporter Permissions
-	30 = Developer Permissions
-	40 = Master Permissions
-	50 = Owner Permissions`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if id == 0 {
-			return errors.New("required parameter `-i` or `--id` not given - exiting")
-		}
-		if userId == 0 {
-			return errors.New("required parameter `-u` or `-user_id` not given - exiting")
-		}
-		if accessLevel == 0 {
-			return errors.New("required parameter `-a` or `-access_level` not given - exiting")
-		}
-		opts := &gitlab.EditGroupMemberOptions{
-			AccessLevel: int2AccessLevel(accessLevel),
-		}
-		if expiresAt != "" {
-			opts.ExpiresAt = &expiresAt
-		}
-		member, _, err := gitlabClient.GroupMembers.EditGroupMember(id, userId, opts)
+var groupMemberEditCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMemberEditFlags{},
+	Opts:   &gitlab.EditGroupMemberOptions{},
+	Cmd: &cobra.Command{
+		Use:   "edit",
+		Short: "Edit a member of a group",
+		Long:  `Updates a member of a group.` + accessLevelsUsage,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMemberEditFlags)
+		opts := cmd.Opts.(*gitlab.EditGroupMemberOptions)
+		member, _, err := gitlabClient.GroupMembers.EditGroupMember(*flags.Id, *flags.UserId, opts)
 		if err != nil {
 			return err
 		}
@@ -155,51 +165,57 @@ var groupMemberEditCmd = &cobra.Command{
 	},
 }
 
-var groupMemberDeleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Remove a member from a group or project",
-	Long:  `Removes a user from a group or project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if id == 0 {
-			return errors.New("required parameter `-i` or `--id` not given - exiting")
-		}
-		if userId == 0 {
-			return errors.New("required parameter `-u` or `--user_id` not given - exiting")
-		}
-		_, err := gitlabClient.GroupMembers.RemoveGroupMember(id, userId)
+// see https://docs.gitlab.com/ce/api/members.html#remove-a-member-from-a-group-or-project
+type groupMemberDeleteFlags struct {
+	Id     *int `flag_name:"id" short:"i" type:"integer" required:"yes" description:"the id of the group to delete user from"`
+	UserId *int `flag_name:"user_id" short:"u" type:"integer" required:"yes" description:"the id of the user to be removed from group"`
+}
+
+var groupMemberDeleteCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMemberDeleteFlags{},
+	Cmd: &cobra.Command{
+		Use:   "delete",
+		Short: "Remove a member from a group",
+		Long:  `Removes a user from a group.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMemberDeleteFlags)
+		_, err := gitlabClient.GroupMembers.RemoveGroupMember(*flags.Id, *flags.UserId)
 		return err
 	},
 }
 
-var groupMemberSyncCmd = &cobra.Command{
-	Use:   "sync",
-	Short: "Synchronizes members of 2 groups",
-	Long: `Synchronizes the members of 2 groups, by either
+type groupMemberSyncFlags struct {
+	Source *int  `flag_name:"source" short:"s" type:"integer" required:"yes" description:"id of group to copy members from"`
+	Target *int  `flag_name:"target" short:"t" type:"integer" required:"yes" description:"id of group to copy members to"`
+	Remove *bool `flag_name:"remove" short:"r" type:"bool" required:"no" description:"remove members in target group that don't exist in source group"`
+}
+
+var groupMemberSyncCmd = &golabCommand{
+	Parent: groupMembersCmd.Cmd,
+	Flags:  &groupMemberSyncFlags{},
+	Cmd: &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronizes members of 2 groups",
+		Long: `Synchronizes the members of 2 groups, by either
 
 * merging them (default) - members that exist in target group but not in source group are kept
 * removing them (--remove) - members that exist in target group but not in source group are deleted`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if source == 0 {
-			return errors.New("required parameter `--source` not given - exiting")
-		}
-		if target == 0 {
-			return errors.New("required parameter `--target` not given - exiting")
-		}
-
-		opts := &gitlab.ListGroupMembersOptions{
-			ListOptions: gitlab.ListOptions{Page: 1, PerPage: 1000},
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*groupMemberSyncFlags)
+		if err := createNonExistingTargetUsers(*flags.Source, *flags.Target); err != nil {
+			return err
 		}
 
-		createNonExistingTargetUsers(source, target, opts)
-
-		if remove {
-			err := removeTargetMembers(target, source, opts)
-			if err != nil {
+		if flags.Remove != nil && *flags.Remove {
+			if err := removeTargetMembers(*flags.Target, *flags.Source); err != nil {
 				return err
 			}
 		}
 
-		members, _, err := gitlabClient.Groups.ListGroupMembers(target, opts)
+		members, err := listAllGroupMembers(*flags.Target, 0)
 		if err != nil {
 			return err
 		}
@@ -207,14 +223,31 @@ var groupMemberSyncCmd = &cobra.Command{
 	},
 }
 
-func createNonExistingTargetUsers(source int, target int, opts *gitlab.ListGroupMembersOptions) error {
-	sourceMembers, _, err := gitlabClient.Groups.ListGroupMembers(source, opts)
+// listAllGroupMembers returns the members of the group, going through all pages
+func listAllGroupMembers(group int, perPage int) ([]*gitlab.GroupMember, error) {
+	if perPage == 0 {
+		perPage = 100
+	}
+	members := []*gitlab.GroupMember{}
+	for page := 1; page > 0; {
+		result, resp, err := gitlabClient.Groups.ListGroupMembers(group, &gitlab.ListGroupMembersOptions{ListOptions: gitlab.ListOptions{Page: page, PerPage: perPage}})
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, result...)
+		page = resp.NextPage
+	}
+	return members, nil
+}
+
+func createNonExistingTargetUsers(source int, target int) error {
+	sourceMembers, err := listAllGroupMembers(source, 0)
 	if err != nil {
 		return err
 	}
 	for _, sourceMember := range sourceMembers {
 		_, resp, err := gitlabClient.GroupMembers.GetGroupMember(target, sourceMember.ID)
-		if resp.StatusCode == 404 { // 404 means "Not Found" --> does not exist yet
+		if resp != nil && resp.StatusCode == 404 { // 404 means "Not Found" --> does not exist yet
 			newMemberOpts := &gitlab.AddGroupMemberOptions{
 				UserID:      &sourceMember.ID,
 				AccessLevel: &sourceMember.AccessLevel,
@@ -226,7 +259,9 @@ func createNonExistingTargetUsers(source int, target int, opts *gitlab.ListGroup
 				}
 				newMemberOpts.ExpiresAt = &expires
 			}
-			gitlabClient.GroupMembers.AddGroupMember(target, newMemberOpts)
+			if _, _, err := gitlabClient.GroupMembers.AddGroupMember(target, newMemberOpts); err != nil {
+				return fmt.Errorf("could not add user %s to group %d: %s", sourceMember.Username, target, err)
+			}
 		} else if err != nil {
 			return err
 		}
@@ -234,14 +269,14 @@ func createNonExistingTargetUsers(source int, target int, opts *gitlab.ListGroup
 	return nil
 }
 
-func removeTargetMembers(target int, source int, opts *gitlab.ListGroupMembersOptions) error {
-	targetMembers, _, err := gitlabClient.Groups.ListGroupMembers(target, opts)
+func removeTargetMembers(target int, source int) error {
+	targetMembers, err := listAllGroupMembers(target, 0)
 	if err != nil {
 		return err
 	}
 	for _, targetMember := range targetMembers {
 		_, resp, err := gitlabClient.GroupMembers.GetGroupMember(source, targetMember.ID)
-		if resp.StatusCode == 404 {
+		if resp != nil && resp.StatusCode == 404 {
 			_, err := gitlabClient.GroupMembers.RemoveGroupMember(target, targetMember.ID)
 			if err != nil {
 				return err
@@ -253,69 +288,12 @@ func removeTargetMembers(target int, source int, opts *gitlab.ListGroupMembersOp
 	return nil
 }
 
-func int2AccessLevel(accessLevel int) *gitlab.AccessLevelValue {
-	switch accessLevel {
-	case 10:
-		return gitlab.AccessLevel(gitlab.GuestPermissions)
-	case 20:
-		return gitlab.AccessLevel(gitlab.ReporterPermissions)
-	case 30:
-		return gitlab.AccessLevel(gitlab.DeveloperPermissions)
-	case 40:
-		return gitlab.AccessLevel(gitlab.MasterPermissions)
-	case 50:
-		return gitlab.AccessLevel(gitlab.OwnerPermission)
-	default:
-		panic("Unrecognized value for AccessLevel")
-	}
-}
-
 func init() {
-	initGroupMembersLsCmd()
-	initGroupMembersGetCmd()
-	initGroupMemberAddCmd()
-	initGroupMemberUpdateCmd()
-	initGroupMemberDeleteCmd()
-	initGroupMemberSyncCmd()
-	RootCmd.AddCommand(groupMembersCmd)
-}
-
-func initGroupMembersLsCmd() {
-	groupMembersLsCmd.PersistentFlags().IntVarP(&id, "id", "i", 0, "(required) id of group to show members for")
-	groupMembersCmd.AddCommand(groupMembersLsCmd)
-}
-
-func initGroupMembersGetCmd() {
-	groupMemberGetCmd.PersistentFlags().IntVarP(&id, "id", "i", 0, "(required) id of group to get member from")
-	groupMemberGetCmd.PersistentFlags().IntVarP(&userId, "user_id", "u", 0, "(required) id of user to get group member infos")
-	groupMembersCmd.AddCommand(groupMemberGetCmd)
-}
-
-func initGroupMemberAddCmd() {
-	groupMemberAddCmd.PersistentFlags().IntVarP(&id, "id", "i", 0, "(required) id of group to add new member to")
-	groupMemberAddCmd.PersistentFlags().IntVarP(&userId, "user_id", "u", 0, "(required) id of user to be added as new group member")
-	groupMemberAddCmd.PersistentFlags().IntVarP(&accessLevel, "access_level", "a", 0, "(required) access level of new group member")
-	groupMemberAddCmd.PersistentFlags().StringVarP(&expiresAt, "expires_at", "e", "", "(optional) expiry date of membership (yyyy-mm-dd)")
-	groupMembersCmd.AddCommand(groupMemberAddCmd)
-}
-
-func initGroupMemberUpdateCmd() {
-	groupMemberEditCmd.PersistentFlags().IntVarP(&id, "id", "i", 0, "(required) id of group to change membership for")
-	groupMemberEditCmd.PersistentFlags().IntVarP(&userId, "user_id", "u", 0, "(required) id the user to change membership for")
-	groupMemberEditCmd.PersistentFlags().IntVarP(&accessLevel, "access_level", "a", 0, "(required) a valid access level")
-	groupMemberEditCmd.PersistentFlags().StringVarP(&expiresAt, "expires_at", "e", "", "(optional) expiry date of membership (yyy-mm-dd)")
-	groupMembersCmd.AddCommand(groupMemberEditCmd)
-}
-
-func initGroupMemberDeleteCmd() {
-	groupMemberDeleteCmd.PersistentFlags().IntVarP(&id, "id", "i", 0, "(required) the id of the group to delete user from")
-	groupMemberDeleteCmd.PersistentFlags().IntVarP(&userId, "user_id", "u", 0, "(required) the id of the user to be removed from group")
-	groupMembersCmd.AddCommand(groupMemberDeleteCmd)
-}
-
-func initGroupMemberSyncCmd() {
-	groupMemberSyncCmd.PersistentFlags().IntVarP(&source, "source", "s", 0, "(required) id of group to copy members from")
-	groupMemberSyncCmd.PersistentFlags().IntVarP(&target, "target", "t", 0, "(required) id of group to copy members to")
-	groupMemberSyncCmd.PersistentFlags().BoolVarP(&remove, "remove", "r", false, "(optional) remove members in target group that don't exist in source group")
-	groupMembersCmd.AddCommand(groupMemberSyncCmd)
+	groupMembersCmd.Init()
+	groupMembersLsCmd.Init()
+	groupMemberGetCmd.Init()
+	groupMemberAddCmd.Init()
+	groupMemberEditCmd.Init()
+	groupMemberDeleteCmd.Init()
+	groupMemberSyncCmd.Init()
 }
@@ -22,25 +22,15 @@ package cmd
 
 import (
 	"fmt"
-	"net/http/httptest"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/xanzy/go-gitlab"
-	"io/ioutil"
 )
 
-var _ = Describe("int2AccessLevel", func() {
-	It("returns expected access level", func() {
-		Expect(int2AccessLevel(10)).To(Equal(gitlab.AccessLevel(gitlab.GuestPermissions)))
-		Expect(int2AccessLevel(20)).To(Equal(gitlab.AccessLevel(gitlab.ReporterPermissions)))
-		Expect(int2AccessLevel(30)).To(Equal(gitlab.AccessLevel(gitlab.DeveloperPermissions)))
-		Expect(int2AccessLevel(40)).To(Equal(gitlab.AccessLevel(gitlab.MasterPermissions)))
-		Expect(int2AccessLevel(50)).To(Equal(gitlab.AccessLevel(gitlab.OwnerPermission)))
-	})
-})
-
 var _ = Describe("group-members command", func() {
 
 	var (
@@ -61,26 +51,26 @@ var _ = Describe("group-members command", func() {
 		// client is the Gitlab client being tested.
 		gitlabClient = gitlab.NewClient(nil, "")
 		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		for _, cmd := range []*golabCommand{groupMembersLsCmd, groupMemberGetCmd, groupMemberAddCmd, groupMemberSyncCmd} {
+			resetFlags(cmd)
+		}
 	})
 
 	Context("when the `get` sub command is executed", func() {
 		Context("if no `--id` or `--user-id` parameters are given", func() {
 			It("should exit with error", func() {
-				// TODO think about a better way to reset vars from previous runs...
-				id = 0;
-				userId = 0
 				_, _, err := executeCommand(RootCmd, "group-members", "get")
 				if err == nil {
 					Fail("No error was thrown, when no --id was given")
 				}
-				Expect(err.Error()).To(Equal("required parameter `-i` or `--id`not given - exiting"))
+				Expect(err.Error()).To(Equal("required flag --id was empty"))
 
 				_, _, err = executeCommand(RootCmd, "group-members", "get", "-i", "19")
 				if err == nil {
 					Fail("No error was thrown, when --user-id was given")
 				}
-				fmt.Println(err.Error())
-				Expect(err.Error()).To(Equal("required parameter `-u` or `--user_id`not given - exiting"))
+				Expect(err.Error()).To(Equal("required flag --user_id was empty"))
 			})
 		})
 
@@ -90,7 +80,7 @@ var _ = Describe("group-members command", func() {
 			expected := readFixture("group-members-get")
 			mux.HandleFunc("/api/v4/groups/30/members/40", func(w http.ResponseWriter, r *http.Request) {
 				method = r.Method
-				fmt.Fprintf(w, expected)
+				fmt.Fprint(w, expected)
 			})
 			stdout, _, err := executeCommand(RootCmd, "group-members", "get", "-i", "30", "-u", "40")
 			Expect(err).To(BeNil())
@@ -103,12 +93,9 @@ var _ = Describe("group-members command", func() {
 	Context("when the `ls` sub command is executed", func() {
 		Context("if no `--id` parameter is given", func() {
 			It("should exit with error", func() {
-				// TODO think about a better way to reset vars from previous runs...
-				id = 0
-				userId = 0
 				_, _, err := executeCommand(RootCmd, "group-members", "ls")
 				Expect(err).NotTo(BeNil(), "No error was raised when missing -i parameter")
-				Expect(err.Error()).To(Equal("required parameter `-i` or `--id`not given - exiting"))
+				Expect(err.Error()).To(Equal("required flag --id was empty"))
 			})
 		})
 
@@ -118,7 +105,7 @@ var _ = Describe("group-members command", func() {
 			expected := readFixture("group-members-ls")
 			mux.HandleFunc("/api/v4/groups/30/members", func(w http.ResponseWriter, r *http.Request) {
 				method = r.Method
-				fmt.Fprintf(w, expected)
+				fmt.Fprint(w, expected)
 			})
 			stdout, _, err := executeCommand(RootCmd, "group-members", "ls", "-i", "30")
 			Expect(err).To(BeNil())
@@ -126,32 +113,85 @@ var _ = Describe("group-members command", func() {
 			Expect(method).To(Equal("GET"))
 			Expect(stdout).To(Equal(expected))
 		})
+
+		It("returns the members of all pages unless --page is given", func() {
+			defer server.Close()
+			queries := []string{}
+			mux.HandleFunc("/api/v4/groups/30/members", func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.RawQuery)
+				if r.URL.Query().Get("page") == "1" {
+					w.Header().Set("Link", `<`+server.URL+`/api/v4/groups/30/members?page=2&per_page=100>; rel="next"`)
+				}
+				fmt.Fprintf(w, `[{"id":%s,"username":"user-%s"}]`, r.URL.Query().Get("page"), r.URL.Query().Get("page"))
+			})
+
+			stdout, _, err := executeCommand(RootCmd, "group-members", "ls", "-i", "30")
+			Expect(err).To(BeNil())
+			Expect(queries).To(Equal([]string{"page=1&per_page=100", "page=2&per_page=100"}))
+			Expect(stdout).To(ContainSubstring("user-1"))
+			Expect(stdout).To(ContainSubstring("user-2"))
+
+			queries = []string{}
+			stdout, _, err = executeCommand(RootCmd, "group-members", "ls", "-i", "30", "--page", "2")
+			Expect(err).To(BeNil())
+			Expect(queries).To(Equal([]string{"page=2"}))
+			Expect(stdout).NotTo(ContainSubstring("user-1"))
+		})
 	})
 
-	Context("when teh `add` sub command is executed", func() {
+	Context("when the `sync` sub command is executed", func() {
+		It("adds the members of all pages and returns errors of adding a member", func() {
+			defer server.Close()
+			mux.HandleFunc("/api/v4/groups/10/members", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "1" {
+					w.Header().Set("Link", `<`+server.URL+`/api/v4/groups/10/members?page=2&per_page=100>; rel="next"`)
+					fmt.Fprint(w, `[]`)
+					return
+				}
+				fmt.Fprint(w, `[{"id":7,"username":"jdoe","access_level":30}]`)
+			})
+			mux.HandleFunc("/api/v4/groups/20/members/7", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message":"404 Not found"}`)
+			})
+			mux.HandleFunc("/api/v4/groups/20/members", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message":"403 Forbidden"}`)
+			})
+
+			_, _, err := executeCommand(RootCmd, "group-members", "sync", "-s", "10", "-t", "20")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(HavePrefix("could not add user jdoe to group 20: "))
+			Expect(err.Error()).To(ContainSubstring("403"))
+		})
+	})
+
+	Context("when the `add` sub command is executed", func() {
 		Context("if no `--id`, `--user_id` or `--access_level` parameter is given", func() {
 			It("should exit with error", func() {
-				// TODO think about a better way to reset vars from previous runs...
-				id = 0
-				userId = 0
-				accessLevel = 0
 				_, _, err := executeCommand(RootCmd, "group-members", "add")
 				Expect(err).NotTo(BeNil(), "No error was raised when missing -i parameter")
-				Expect(err.Error()).To(Equal("required parameter `-i` or `--id` not given - exiting"))
+				Expect(err.Error()).To(Equal("required flag --id was empty"))
 
 				_, _, err = executeCommand(RootCmd, "group-members", "add", "-i", "30")
 				Expect(err).NotTo(BeNil(), "No error was raised when missing -u parameter")
-				Expect(err.Error()).To(Equal("required parameter `-u` or `--user_id` not given - exiting"))
+				Expect(err.Error()).To(Equal("required flag --user_id was empty"))
 
 				_, _, err = executeCommand(RootCmd, "group-members", "add", "-i", "30", "-u", "30")
-				Expect(err).NotTo(BeNil(), "No error was raised when missing -u parameter")
-				Expect(err.Error()).To(Equal("required parameter `-a` or `--access_level` not given - exiting"))
+				Expect(err).NotTo(BeNil(), "No error was raised when missing -a parameter")
+				Expect(err.Error()).To(Equal("required flag --access_level was empty"))
 			})
 		})
 
+		It("returns an error for an invalid access level", func() {
+			_, _, err := executeCommand(RootCmd, "group-members", "add", "-i", "30", "-u", "40", "-a", "42")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal(`invalid value "42" for flag --access_level, expected an access level of 10 (guest), 20 (reporter), 30 (developer), 40 (master) or 50 (owner)`))
+			Expect(exitCode(err)).To(Equal(exitUsageError))
+		})
+
 		It("creates group member as expected", func() {
 			defer server.Close()
-			// TODO think about a better way to reset vars from previous runs...
 			method := ""
 			body := ""
 			expected := readFixture("group-members-add")
@@ -159,7 +199,7 @@ var _ = Describe("group-members command", func() {
 				method = r.Method
 				bodyBytes, _ := ioutil.ReadAll(r.Body)
 				body = string(bodyBytes)
-				fmt.Fprintf(w, expected)
+				fmt.Fprint(w, expected)
 			})
 			stdout, _, err := executeCommand(RootCmd, "group-members", "add", "-i", "30", "-u", "40", "-a", "50", "-e", "2016-09-23")
 			Expect(err).To(BeNil())
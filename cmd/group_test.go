@@ -0,0 +1,90 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("group command", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		for _, cmd := range []*golabCommand{groupCreateCmd, groupUpdateCmd} {
+			resetFlags(cmd)
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the `create` sub command is executed", func() {
+		It("should send the given visibility", func() {
+			var body map[string]interface{}
+			mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(r, "POST")
+				json.NewDecoder(r.Body).Decode(&body)
+				fmt.Fprint(w, `{"id": 1, "name": "my-group", "path": "my-group", "visibility": "private"}`)
+			})
+			_, _, err := executeCommand(RootCmd, "group", "create", "-n", "my-group", "-p", "my-group", "--visibility", "private")
+			Expect(err).To(BeNil())
+			Expect(body["visibility"]).To(Equal("private"))
+		})
+
+		It("should exit with error for invalid visibilities", func() {
+			_, _, err := executeCommand(RootCmd, "group", "create", "-n", "my-group", "-p", "my-group", "--visibility", "secret")
+			Expect(err).To(MatchError(`invalid value "secret" for flag --visibility, expected one of private, internal or public`))
+		})
+	})
+
+	Context("when the `update` sub command is executed", func() {
+		It("should send the given visibility", func() {
+			var body map[string]interface{}
+			mux.HandleFunc("/api/v4/groups/30", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(r, "PUT")
+				json.NewDecoder(r.Body).Decode(&body)
+				fmt.Fprint(w, `{"id": 30, "visibility": "internal"}`)
+			})
+			_, _, err := executeCommand(RootCmd, "group", "update", "--id", "30", "--visibility", "internal")
+			Expect(err).To(BeNil())
+			Expect(body["visibility"]).To(Equal("internal"))
+		})
+	})
+
+})
@@ -0,0 +1,227 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the diff of a single file as returned by the Gitlab API
+type FileDiff struct {
+	OldPath     string
+	NewPath     string
+	AMode       string
+	BMode       string
+	Diff        string
+	NewFile     bool
+	RenamedFile bool
+	DeletedFile bool
+}
+
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// DiffRenderer renders diffs of the Gitlab API like git diff does
+type DiffRenderer struct {
+	Out   io.Writer
+	Color bool
+}
+
+// Render writes the unified diffs with file headers
+func (r DiffRenderer) Render(diffs []FileDiff) {
+	for _, diff := range diffs {
+		r.line(colorBold, "diff --git a/"+diff.OldPath+" b/"+diff.NewPath)
+		oldPath, newPath := "a/"+diff.OldPath, "b/"+diff.NewPath
+		switch {
+		case diff.NewFile:
+			r.line(colorBold, "new file mode "+diff.BMode)
+			oldPath = "/dev/null"
+		case diff.DeletedFile:
+			r.line(colorBold, "deleted file mode "+diff.AMode)
+			newPath = "/dev/null"
+		case diff.RenamedFile:
+			r.line(colorBold, "rename from "+diff.OldPath)
+			r.line(colorBold, "rename to "+diff.NewPath)
+		}
+		if diff.Diff == "" {
+			continue
+		}
+		r.line(colorBold, "--- "+oldPath)
+		r.line(colorBold, "+++ "+newPath)
+		for _, line := range diffLines(diff.Diff) {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				r.line(colorCyan, line)
+			case strings.HasPrefix(line, "+"):
+				r.line(colorGreen, line)
+			case strings.HasPrefix(line, "-"):
+				r.line(colorRed, line)
+			default:
+				r.line("", line)
+			}
+		}
+	}
+}
+
+// RenderStat writes the number of changed lines per file and a summary, like git diff --stat
+func (r DiffRenderer) RenderStat(diffs []FileDiff) {
+	width, maxChanges := 0, 0
+	stats := make([][2]int, len(diffs))
+	for i, diff := range diffs {
+		stats[i] = countChanges(diff.Diff)
+		width = max(width, len(statName(diff)))
+		maxChanges = max(maxChanges, stats[i][0]+stats[i][1])
+	}
+
+	insertions, deletions := 0, 0
+	for i, diff := range diffs {
+		added, deleted := stats[i][0], stats[i][1]
+		insertions, deletions = insertions+added, deletions+deleted
+		// scale the graph to at most 50 characters, like git does for narrow terminals
+		if maxChanges > 50 {
+			added, deleted = scale(added, maxChanges), scale(deleted, maxChanges)
+		}
+		line := fmt.Sprintf(" %-*s | %*d", width, statName(diff), len(fmt.Sprint(maxChanges)), stats[i][0]+stats[i][1])
+		if added+deleted > 0 {
+			line += " " + r.colored(colorGreen, strings.Repeat("+", added)) + r.colored(colorRed, strings.Repeat("-", deleted))
+		}
+		fmt.Fprintln(r.Out, line)
+	}
+	fmt.Fprintf(r.Out, " %d %s changed, %d %s(+), %d %s(-)\n",
+		len(diffs), plural(len(diffs), "file", "files"), insertions, plural(insertions, "insertion", "insertions"), deletions, plural(deletions, "deletion", "deletions"))
+}
+
+// RenderNames writes the paths of the changed files, like git diff --name-only
+func (r DiffRenderer) RenderNames(diffs []FileDiff) {
+	for _, diff := range diffs {
+		fmt.Fprintln(r.Out, diff.NewPath)
+	}
+}
+
+// DiffLine is a line of a unified diff with its numbers in the old and the new file,
+// the old number is 0 for added lines, the new number is 0 for removed lines
+type DiffLine struct {
+	Old int
+	New int
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// FindDiffLine returns the line of the diff with the given number in the new file or, if newLine is 0,
+// with the given number in the old file. It returns false, if the line is not part of the diff.
+func FindDiffLine(diff string, oldLine int, newLine int) (DiffLine, bool) {
+	oldNumber, newNumber := 0, 0
+	for _, line := range diffLines(diff) {
+		if match := hunkHeader.FindStringSubmatch(line); match != nil {
+			oldNumber, _ = strconv.Atoi(match[1])
+			newNumber, _ = strconv.Atoi(match[2])
+			continue
+		}
+		var current DiffLine
+		switch {
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+			continue
+		case strings.HasPrefix(line, "+"):
+			current = DiffLine{New: newNumber}
+			newNumber++
+		case strings.HasPrefix(line, "-"):
+			current = DiffLine{Old: oldNumber}
+			oldNumber++
+		default:
+			current = DiffLine{Old: oldNumber, New: newNumber}
+			oldNumber, newNumber = oldNumber+1, newNumber+1
+		}
+		if newLine > 0 && current.New == newLine && (oldLine == 0 || current.Old == oldLine) ||
+			newLine == 0 && oldLine > 0 && current.Old == oldLine {
+			return current, true
+		}
+	}
+	return DiffLine{}, false
+}
+
+func (r DiffRenderer) line(color string, line string) {
+	fmt.Fprintln(r.Out, r.colored(color, line))
+}
+
+func (r DiffRenderer) colored(color string, text string) string {
+	if !r.Color || color == "" || text == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func diffLines(diff string) []string {
+	return strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+}
+
+// countChanges returns the number of added and deleted lines of a diff
+func countChanges(diff string) [2]int {
+	var changes [2]int
+	for _, line := range diffLines(diff) {
+		if strings.HasPrefix(line, "+") {
+			changes[0]++
+		} else if strings.HasPrefix(line, "-") {
+			changes[1]++
+		}
+	}
+	return changes
+}
+
+func statName(diff FileDiff) string {
+	if diff.RenamedFile {
+		return diff.OldPath + " => " + diff.NewPath
+	}
+	return diff.NewPath
+}
+
+func scale(changes int, maxChanges int) int {
+	if changes == 0 {
+		return 0
+	}
+	if scaled := changes * 50 / maxChanges; scaled > 0 {
+		return scaled
+	}
+	return 1
+}
+
+func plural(count int, singular string, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
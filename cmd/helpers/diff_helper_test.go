@@ -0,0 +1,115 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package helpers
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffRenderer", func() {
+
+	diffs := []FileDiff{
+		{OldPath: "README.md", NewPath: "README.md", Diff: "@@ -1,2 +1,2 @@\n golab\n-old line\n+new line\n"},
+		{OldPath: "cmd/diff.go", NewPath: "cmd/diff.go", BMode: "100644", NewFile: true, Diff: "@@ -0,0 +1,2 @@\n+package cmd\n+\n"},
+		{OldPath: "old.go", NewPath: "new.go", RenamedFile: true},
+	}
+
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+	})
+
+	It("renders unified diffs with file headers", func() {
+		DiffRenderer{Out: out}.Render(diffs)
+		Expect(out.String()).To(Equal(`diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1,2 +1,2 @@
+ golab
+-old line
++new line
+diff --git a/cmd/diff.go b/cmd/diff.go
+new file mode 100644
+--- /dev/null
++++ b/cmd/diff.go
+@@ -0,0 +1,2 @@
++package cmd
++
+diff --git a/old.go b/new.go
+rename from old.go
+rename to new.go
+`))
+	})
+
+	It("colors added and deleted lines", func() {
+		DiffRenderer{Out: out, Color: true}.Render(diffs[:1])
+		Expect(out.String()).To(ContainSubstring("\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n golab\n\x1b[31m-old line\x1b[0m\n\x1b[32m+new line\x1b[0m\n"))
+	})
+
+	It("renders the stats", func() {
+		DiffRenderer{Out: out}.RenderStat(diffs)
+		Expect(out.String()).To(Equal(` README.md        | 2 +-
+ cmd/diff.go      | 2 ++
+ old.go => new.go | 0
+ 3 files changed, 3 insertions(+), 1 deletion(-)
+`))
+	})
+
+	It("renders the names of the files", func() {
+		DiffRenderer{Out: out}.RenderNames(diffs)
+		Expect(out.String()).To(Equal("README.md\ncmd/diff.go\nnew.go\n"))
+	})
+
+})
+
+var _ = Describe("FindDiffLine", func() {
+
+	diff := "@@ -1,3 +1,3 @@\n golab\n-old line\n+new line\n unchanged\n@@ -10,2 +10,3 @@\n context\n+added\n end\n\\ No newline at end of file\n"
+
+	It("finds added and unchanged lines by their number in the new file", func() {
+		line, found := FindDiffLine(diff, 0, 2)
+		Expect(found).To(BeTrue())
+		Expect(line).To(Equal(DiffLine{New: 2}))
+
+		line, found = FindDiffLine(diff, 0, 12)
+		Expect(found).To(BeTrue())
+		Expect(line).To(Equal(DiffLine{Old: 11, New: 12}))
+	})
+
+	It("finds removed lines by their number in the old file", func() {
+		line, found := FindDiffLine(diff, 2, 0)
+		Expect(found).To(BeTrue())
+		Expect(line).To(Equal(DiffLine{Old: 2}))
+	})
+
+	It("does not find lines outside of the hunks", func() {
+		_, found := FindDiffLine(diff, 0, 5)
+		Expect(found).To(BeFalse())
+
+		_, found = FindDiffLine(diff, 1, 2)
+		Expect(found).To(BeFalse())
+	})
+
+})
@@ -21,70 +21,230 @@
 package helpers
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os/exec"
-	"regexp"
 	"strings"
 )
 
 type gitHelper struct {
 }
 
+// GitCommit holds the message of a commit
+type GitCommit struct {
+	Subject string
+	Body    string
+}
+
+// GitRemote is the parsed URL of a git remote
+type GitRemote struct {
+	Scheme    string // scheme of the web URL, https unless the remote is an http remote
+	Host      string // hostname without port
+	Port      string // port of http(s) remotes, ssh ports are not relevant for the web URL
+	Namespace string // group including all subgroups, e.g. my-group/my-subgroup
+	Project   string // path of the project without .git suffix
+}
+
+// Path returns the full path of the project, e.g. my-group/my-subgroup/my-project
+func (r GitRemote) Path() string {
+	return r.Namespace + "/" + r.Project
+}
+
+// WebUrl returns the URL of the project page
+func (r GitRemote) WebUrl() string {
+	host := r.Host
+	if r.Port != "" {
+		host = net.JoinHostPort(r.Host, r.Port)
+	}
+	return r.Scheme + "://" + host + "/" + r.Path()
+}
+
 func GitHelper() gitHelper {
 	return gitHelper{}
 }
 
-func (g gitHelper) GetRemotes() (string, error) {
-	out, err := exec.Command("git", "remote", "-v").Output()
-	return string(out), err
+// CurrentBranch returns the name of the checked out branch, or HEAD if no branch is checked out
+func (g gitHelper) CurrentBranch() (string, error) {
+	return g.git("rev-parse", "--abbrev-ref", "HEAD")
 }
 
-func (g gitHelper) GetRemoteUrl(remotes string) (string, error) {
-	re := regexp.MustCompile("origin\\s*(.+?)\\s*\\(fetch\\)")
-	match := re.FindStringSubmatch(remotes)
-	if len(match) > 1 {
-		return match[1], nil
+// RevParse returns the full SHA of the given revision
+func (g gitHelper) RevParse(revision string) (string, error) {
+	return g.git("rev-parse", "--verify", "--quiet", revision+"^{commit}")
+}
+
+// PathPrefix returns the path of the working directory relative to the root of the repository
+func (g gitHelper) PathPrefix() (string, error) {
+	return g.git("rev-parse", "--show-prefix")
+}
+
+// TopLevel returns the root directory of the repository
+func (g gitHelper) TopLevel() (string, error) {
+	return g.git("rev-parse", "--show-toplevel")
+}
+
+// Commits returns the commits of the given revision range, oldest first
+func (g gitHelper) Commits(revisionRange ...string) ([]GitCommit, error) {
+	out, err := g.git(append([]string{"log", "--reverse", "--format=%s%x1f%b%x1e"}, revisionRange...)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []GitCommit
+	for _, entry := range strings.Split(out, "\x1e") {
+		if fields := strings.SplitN(strings.TrimSpace(entry), "\x1f", 2); len(fields) == 2 {
+			commits = append(commits, GitCommit{Subject: fields[0], Body: strings.TrimSpace(fields[1])})
+		}
 	}
-	return "", errors.New("Could not find URL in " + remotes)
+	return commits, nil
 }
 
-func (g gitHelper) GetWebUrl(remoteUrl string) (string, error) {
-	if strings.HasPrefix(remoteUrl, "http") {
-		return webifyHttpRemote(remoteUrl)
-	} else if strings.HasPrefix(remoteUrl, "git@") {
-		return webifyGitRemote(remoteUrl)
-	} else if strings.HasPrefix(remoteUrl, "ssh") {
-		return webifySshRemote(remoteUrl)
-	} else {
-		return "", errors.New("Cannot parse remote URL: " + remoteUrl)
+// Push pushes the branch to the remote and sets the upstream of the branch, the output of git is written to out
+func (g gitHelper) Push(remote string, branch string, out io.Writer) error {
+	push := exec.Command("git", "push", "--set-upstream", remote, branch)
+	push.Stdout, push.Stderr = out, out
+	if err := push.Run(); err != nil {
+		return fmt.Errorf("git push %s %s failed: %s", remote, branch, err)
+	}
+	return nil
+}
+
+// Fetch fetches the given refspec from the remote, which can be the name or the URL of a remote
+func (g gitHelper) Fetch(remote string, refspec string) error {
+	_, err := g.git("fetch", "--quiet", remote, refspec)
+	return err
+}
+
+// Checkout checks out the given branch
+func (g gitHelper) Checkout(branch string) error {
+	_, err := g.git("checkout", "--quiet", branch)
+	return err
+}
+
+// MergeFastForward fast-forwards the checked out branch to the given revision
+func (g gitHelper) MergeFastForward(revision string) error {
+	_, err := g.git("merge", "--quiet", "--ff-only", revision)
+	return err
+}
+
+// SetUpstream sets the remote (name or URL) and the remote branch that are used by git pull and git push for the branch
+func (g gitHelper) SetUpstream(branch string, remote string, remoteBranch string) error {
+	if _, err := g.git("config", "branch."+branch+".remote", remote); err != nil {
+		return err
 	}
+	_, err := g.git("config", "branch."+branch+".merge", "refs/heads/"+remoteBranch)
+	return err
 }
 
-func webifySshRemote(remoteUrl string) (string, error) {
-	u, err := url.Parse(remoteUrl)
+func (g gitHelper) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
 	if err != nil {
-		return "", err
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), msg)
+		}
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), err)
 	}
-	host := ""
-	if strings.Contains(u.Host, ":") {
-		host, _, err = net.SplitHostPort(u.Host)
-	} else {
-		host = u.Host
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetRemote returns the parsed URL of the remote with the given name in the
+// working directory, url.<base>.insteadOf rewrites are applied
+func (g gitHelper) GetRemote(name string) (GitRemote, error) {
+	remoteUrl, err := g.RemoteUrl(name)
+	if err != nil {
+		return GitRemote{}, err
 	}
-	return "https://" + host + strings.TrimSuffix(u.Path, ".git"), nil
+	return ParseRemoteUrl(remoteUrl)
 }
 
-func webifyGitRemote(remoteUrl string) (string, error) {
-	url := strings.TrimRight(remoteUrl[4:], ".git")
-	url = strings.Replace(url, ":", "/", 1)
-	return "https://" + url, nil
+// RemoteUrl returns the URL of the remote with the given name in the
+// working directory, url.<base>.insteadOf rewrites are applied
+func (g gitHelper) RemoteUrl(name string) (string, error) {
+	if _, err := g.git("rev-parse", "--git-dir"); err != nil {
+		return "", errors.New("working directory is not a git repository")
+	}
+	// other than git remote -v, the git config returns the URL without the rewrites
+	remoteUrl, err := g.git("config", "--get", "remote."+name+".url")
+	if err != nil {
+		return "", fmt.Errorf("git remote %s not found", name)
+	}
+	return ApplyInsteadOf(remoteUrl, g.GetInsteadOfRewrites()), nil
 }
 
-func webifyHttpRemote(remoteUrl string) (string, error) {
-	if strings.HasSuffix(remoteUrl, ".git") {
-		return strings.TrimSuffix(remoteUrl, ".git"), nil
+// GetInsteadOfRewrites returns the url.<base>.insteadOf settings of the git config as prefix => base
+func (g gitHelper) GetInsteadOfRewrites() map[string]string {
+	rewrites := map[string]string{}
+	// an exit code of 1 means that there are no such settings
+	out, _ := exec.Command("git", "config", "--get-regexp", "^url\\..*\\.insteadof$").Output()
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimPrefix(fields[0], "url."), ".insteadof")
+		rewrites[fields[1]] = base
 	}
-	return remoteUrl, nil
+	return rewrites
+}
+
+// ApplyInsteadOf rewrites the given URL with the longest matching insteadOf prefix, like git does
+func ApplyInsteadOf(remoteUrl string, rewrites map[string]string) string {
+	longest := ""
+	for prefix := range rewrites {
+		if strings.HasPrefix(remoteUrl, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return remoteUrl
+	}
+	return rewrites[longest] + strings.TrimPrefix(remoteUrl, longest)
+}
+
+// ParseRemoteUrl parses scp-like (git@host:group/project.git), ssh://, git:// and http(s):// remote URLs
+func ParseRemoteUrl(remoteUrl string) (GitRemote, error) {
+	remote := GitRemote{Scheme: "https"}
+	var path string
+	if scpLike(remoteUrl) {
+		hostAndPath := strings.SplitN(remoteUrl, ":", 2)
+		remote.Host = hostAndPath[0][strings.LastIndex(hostAndPath[0], "@")+1:]
+		path = hostAndPath[1]
+	} else {
+		u, err := url.Parse(remoteUrl)
+		if err != nil || u.Host == "" {
+			return GitRemote{}, errors.New("Cannot parse remote URL: " + remoteUrl)
+		}
+		remote.Host = u.Hostname()
+		switch u.Scheme {
+		case "http", "https":
+			remote.Scheme = u.Scheme
+			remote.Port = u.Port()
+		case "ssh", "git", "git+ssh", "ssh+git":
+		default:
+			return GitRemote{}, errors.New("Cannot parse remote URL: " + remoteUrl)
+		}
+		path = u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if remote.Host == "" || i <= 0 || i == len(path)-1 {
+		return GitRemote{}, errors.New("Cannot parse remote URL: " + remoteUrl)
+	}
+	remote.Namespace, remote.Project = path[:i], path[i+1:]
+	return remote, nil
+}
+
+// scpLike checks for the [user@]host:path syntax, which git assumes if
+// there is a colon and no slash in front of it
+func scpLike(remoteUrl string) bool {
+	colon := strings.Index(remoteUrl, ":")
+	slash := strings.Index(remoteUrl, "/")
+	return colon > 0 && (slash < 0 || colon < slash) && !strings.Contains(remoteUrl, "://")
 }
@@ -0,0 +1,40 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package helpers
+
+import "regexp"
+
+// Redacted replaces secrets in logs and recorded cassettes
+const Redacted = "[REDACTED]"
+
+// secretName matches names like token, runners_token, client_secret or password
+const secretName = `[a-z_]*(?:token|secret|password)`
+
+var (
+	secretParams    = regexp.MustCompile(`((?:^|[?&\s])(?:` + secretName + `|code|code_verifier|device_code)=)[^&\s]*`)
+	secretJsonValue = regexp.MustCompile(`("` + secretName + `"\s*:\s*)"[^"]*"`)
+)
+
+// Redact removes tokens and passwords from URLs, form and JSON bodies
+func Redact(s string) string {
+	s = secretParams.ReplaceAllString(s, "${1}"+Redacted)
+	return secretJsonValue.ReplaceAllString(s, `${1}"`+Redacted+`"`)
+}
@@ -0,0 +1,42 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package helpers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redact", func() {
+
+	It("redacts secrets in URLs and forms", func() {
+		Expect(Redact("https://gitlab.com/api/v4/user?private_token=abc&per_page=10")).To(Equal("https://gitlab.com/api/v4/user?private_token=[REDACTED]&per_page=10"))
+		Expect(Redact("grant_type=password&password=secret&username=root")).To(Equal("grant_type=password&password=[REDACTED]&username=root"))
+		Expect(Redact("postcode=12345")).To(Equal("postcode=12345"))
+		Expect(Redact("token=abc&runners_token=def&trigger_token=ghi")).To(Equal("token=[REDACTED]&runners_token=[REDACTED]&trigger_token=[REDACTED]"))
+	})
+
+	It("redacts secrets in JSON bodies", func() {
+		Expect(Redact(`{"token_name":"golab","webhook_secret":"abc","new_password":"def"}`)).To(Equal(`{"token_name":"golab","webhook_secret":"[REDACTED]","new_password":"[REDACTED]"}`))
+		Expect(Redact(`{"name": "golab", "runners_token": "def"}`)).To(Equal(`{"name": "golab", "runners_token": "[REDACTED]"}`))
+	})
+
+})
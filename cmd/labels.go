@@ -45,7 +45,7 @@ var labelsCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#list-labels
 type labelsListFlag struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 }
 
 var labelsListCmd = &golabCommand{
@@ -69,7 +69,7 @@ var labelsListCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#create-a-new-label
 type labelsCreateFlags struct {
-	Id          *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id          *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name        *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the label"`
 	Color       *string `flag_name:"color" short:"c" type:"string" required:"yes" description:"The color of the label given in 6-digit hex notation with leading '#' sign (e.g. #FFAABB) or one of the CSS color names"`
 	Description *string `flag_name:"description" short:"d" type:"string" required:"no" description:"The description of the label"`
@@ -99,7 +99,7 @@ var labelsCreateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#delete-a-label
 type labelsDeleteFlags struct {
-	Id   *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id   *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the label"`
 }
 
@@ -123,7 +123,7 @@ var labelsDeleteCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#edit-an-existing-label
 type labelsEditFlags struct {
-	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the existing label"`
 	// TODO think about an optional tag, that provides the "required / optional" message
 	NewName     *string `flag_name:"new_name" short:"u" type:"string" required:"no" description:"(required, if color is not provided) The new name of the label"`
@@ -159,7 +159,7 @@ var labelsEditCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#subscribe-to-a-label
 type labelsSubscribeFlags struct {
-	Id      *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id      *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	LabelId *string `flag_name:"label_id" short:"l" type:"string" required:"yes" description:"The ID or title of a project's label"`
 }
 
@@ -183,7 +183,7 @@ var labelsSubsribeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/labels.html#unsubscribe-from-a-label
 type labelsUnsubscribeFlags struct {
-	Id      *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id      *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	LabelId *string `flag_name:"label_id" short:"l" type:"string" required:"yes" description:"The ID or title of a project's label"`
 }
 
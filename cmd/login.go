@@ -21,6 +21,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -30,7 +31,11 @@ import (
 
 	"github.com/howeyc/gopass"
 	. "github.com/michaellihs/gogpat/gogpat"
+	"github.com/michaellihs/golab/cmd/credentials"
+	"github.com/michaellihs/golab/cmd/helpers"
+	"github.com/michaellihs/golab/cmd/oauth"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
 // loginCmd implements a user login with username and password
@@ -38,9 +43,17 @@ import (
 // Gitlab UI and some hacks to scrape a personal access token
 // for a user identified by username and password.
 type loginFlags struct {
-	Host     *string `flag_name:"host" short:"s" type:"string" required:"yes" description:"Hostname (http://gitlab.my-domain.com) of the gitlab server"`
-	User     *string `flag_name:"user" short:"u" type:"string" required:"yes" description:"Username for the login"`
-	Password *string `flag_name:"password" short:"p" type:"string" required:"no" description:"Password for the login"`
+	Host             *string `flag_name:"host" short:"s" type:"string" required:"yes" description:"Hostname (http://gitlab.my-domain.com) of the gitlab server"`
+	User             *string `flag_name:"user" short:"u" type:"string" required:"no" description:"Username for the login (required unless logging in with --oauth_flow code or device)"`
+	Password         *string `flag_name:"password" short:"p" type:"string" required:"no" description:"Password for the login"`
+	Store            *string `flag_name:"store" type:"string" required:"no" enum:"keyring,pass,file,helper,plaintext" description:"Where to store the access token, file is an encrypted file, plaintext writes the token into .golab.yml (default: keyring)"`
+	CredentialHelper *string `flag_name:"credential_helper" type:"string" required:"no" description:"Command of an external credential helper (git credential helper protocol), used with --store helper"`
+	CredentialFile   *string `flag_name:"credential_file" type:"string" required:"no" description:"Path of the encrypted credentials file, used with --store file (default: $HOME/.golab.credentials.gpg)"`
+	OAuthFlow        *string `flag_name:"oauth_flow" type:"string" required:"no" enum:"password,code,device" description:"Login with an OAuth application instead of scraping a personal access token, code opens the browser"`
+	ClientId         *string `flag_name:"client_id" type:"string" required:"no" description:"Application ID of the OAuth application, required for --oauth_flow"`
+	ClientSecret     *string `flag_name:"client_secret" type:"string" required:"no" description:"Secret of the OAuth application, only required for confidential applications"`
+	Scopes           *string `flag_name:"scopes" type:"string" required:"no" description:"Comma separated list of OAuth scopes (default: api)"`
+	RedirectPort     *int    `flag_name:"redirect_port" type:"int" required:"no" description:"Port of the local redirect listener for --oauth_flow code, the application needs the callback URL http://127.0.0.1:<port>/callback (default: 7171)"`
 }
 
 var loginCmd = &golabCommand{
@@ -49,18 +62,24 @@ var loginCmd = &golabCommand{
 	Cmd: &cobra.Command{
 		Use:   "login",
 		Short: "Login to Gitlab",
-		Long:  `Login to Gitlab using username and password`,
+		Long: `Login to Gitlab using username and password or an OAuth application.
+
+Without --oauth_flow, a personal access token is created by logging in to the Gitlab UI.
+With --oauth_flow, an OAuth token is requested for the application given by --client_id:
+
+* password - with username and password (resource owner password credentials)
+* code     - in the browser, the application needs the callback URL http://127.0.0.1:7171/callback
+* device   - in the browser on any device, by entering a code (Gitlab >= 17.2)
+
+OAuth tokens are refreshed automatically, once they are expired.`,
 	},
 	Run: func(cmd golabCommand) error {
 		flags := cmd.Flags.(*loginFlags)
-		fmt.Println(*flags.User)
-		if flags.Password == nil {
-			var err error
-			password, err = askForPassword()
-			if err != nil {
-				return err
-			}
-			flags.Password = &password
+		if flags.OAuthFlow != nil {
+			return oauthLogin(flags)
+		}
+		if err := loginPassword(flags); err != nil {
+			return err
 		}
 		req := GitLabTokenRequest{
 			URL:      *flags.Host,
@@ -72,8 +91,12 @@ var loginCmd = &golabCommand{
 		if err != nil {
 			return err
 		}
+		conf, err := storeToken(flags, token, "")
+		if err != nil {
+			return err
+		}
 		// TODO add path to write config to
-		err = writeGolabConf(*flags.Host, token)
+		err = writeGolabConf(conf)
 		if err != nil {
 			return err
 		}
@@ -82,6 +105,127 @@ var loginCmd = &golabCommand{
 	},
 }
 
+func loginPassword(flags *loginFlags) error {
+	if flags.User == nil {
+		return errors.New("required flag --user was empty")
+	}
+	if flags.Password == nil {
+		password, err := askForPassword()
+		if err != nil {
+			return err
+		}
+		flags.Password = &password
+	}
+	return nil
+}
+
+// oauthLogin requests an OAuth token with the flow selected by --oauth_flow,
+// see https://docs.gitlab.com/ce/api/oauth2.html
+func oauthLogin(flags *loginFlags) error {
+	if flags.ClientId == nil {
+		return errors.New("required flag --client_id was empty")
+	}
+	httpClient, err := initHttpClient()
+	if err != nil {
+		return err
+	}
+	config := oauth.Config{
+		BaseURL:    *flags.Host,
+		ClientID:   *flags.ClientId,
+		HTTPClient: httpClient,
+	}
+	if flags.ClientSecret != nil {
+		config.ClientSecret = *flags.ClientSecret
+	}
+	if flags.Scopes != nil {
+		config.Scopes = strings.Split(*flags.Scopes, ",")
+	}
+	if flags.RedirectPort != nil {
+		config.RedirectPort = *flags.RedirectPort
+	}
+
+	var token *oauth.Token
+	switch *flags.OAuthFlow {
+	case oauth.PasswordFlow:
+		if err = loginPassword(flags); err != nil {
+			return err
+		}
+		token, err = config.PasswordToken(*flags.User, *flags.Password)
+	case oauth.CodeFlow:
+		token, err = config.CodeToken(func(authorizeURL string) error {
+			fmt.Printf("** open the following URL in your browser to authorize golab:\n%s\n", authorizeURL)
+			helpers.NewBrowserHelper().Open(authorizeURL)
+			return nil
+		})
+	case oauth.DeviceFlow:
+		token, err = config.DeviceToken(func(userCode string, verificationURI string) {
+			fmt.Printf("** open %s in your browser and enter the code %s\n", verificationURI, userCode)
+		})
+	default:
+		return errors.New("unknown OAuth flow '" + *flags.OAuthFlow + "', use one of password, code or device")
+	}
+	if err != nil {
+		return err
+	}
+
+	encoded, err := token.Encode()
+	if err != nil {
+		return err
+	}
+	conf, err := storeToken(flags, encoded, authOAuth)
+	if err != nil {
+		return err
+	}
+	if err = writeGolabConf(conf); err != nil {
+		return err
+	}
+	fmt.Printf("** successfully logged in to %s with OAuth\n", *flags.Host)
+	return nil
+}
+
+// storeToken writes the token into the credential store selected by the
+// login flags and returns the configuration that points golab to this store.
+func storeToken(flags *loginFlags, token string, auth string) (golabConf, error) {
+	conf := golabConf{Url: *flags.Host, Auth: auth}
+	if flags.CredentialHelper != nil {
+		conf.CredentialHelper = *flags.CredentialHelper
+	}
+	if flags.CredentialFile != nil {
+		conf.CredentialFile = *flags.CredentialFile
+	}
+
+	kind := credentials.Keyring
+	if flags.Store != nil {
+		kind = *flags.Store
+	}
+	if kind == credentials.Plaintext {
+		fmt.Println("** WARNING: the access token will be written in plaintext into the golab config")
+		conf.CredentialStore = credentials.Plaintext
+		conf.Token = token
+		return conf, nil
+	}
+
+	store, err := credentialStore(kind, conf)
+	if err != nil && flags.Store == nil {
+		// no store was explicitly requested, so we fall back to the encrypted file
+		fmt.Printf("** %s - falling back to encrypted credentials file\n", err)
+		kind = credentials.File
+		store, err = credentialStore(kind, conf)
+	}
+	if err != nil {
+		return conf, err
+	}
+	if err = store.Set(credentials.Key(conf.Url), token); err != nil {
+		return conf, err
+	}
+	conf.CredentialStore = kind
+	if kind == credentials.File {
+		conf.CredentialFile = credentialFile(conf.CredentialFile)
+	}
+	fmt.Printf("** access token written to %s credential store\n", kind)
+	return conf, nil
+}
+
 // personalAccessTokenCmd creates a personal access token for
 // a user identified by username and password. This is not
 // available from the Gitlab API so we login to the Gitlab UI
@@ -93,7 +237,7 @@ type personalAccessTokenFlags struct {
 	API          *bool   `flag_name:"api" short:"a" type:"bool" required:"no" description:"Access the authenticated user's API (default: false)"`
 	ReadUser     *bool   `flag_name:"read_user" type:"bool" required:"no" description:"Read the authenticated user's personal information (default: false)"`
 	ReadRegistry *bool   `flag_name:"read_registry" type:"bool" required:"no" description:"Grant access to the docker registry (default: false)"`
-	Sudo         *bool   `flag_name:"sudo" type:"bool" required:"no" description:"Perform API actions as any user in the system (default: false)"`
+	Sudo         *bool   `flag_name:"sudo_scope" type:"bool" required:"no" description:"Perform API actions as any user in the system (default: false), use the global --sudo to act as another user"`
 	Date         *string `flag_name:"expires" type:"string" required:"no" description:"Expiration date of token"`
 	TokenName    *string `flag_name:"token_name" type:"string" required:"no" description:"Name of token"`
 }
@@ -111,8 +255,7 @@ var personalAccessTokenCmd = &golabCommand{
 		flags := cmd.Flags.(*personalAccessTokenFlags)
 
 		if *flags.Password == "" {
-			var err error
-			password, err = askForPassword()
+			password, err := askForPassword()
 			if err != nil {
 				return err
 			}
@@ -141,6 +284,9 @@ var personalAccessTokenCmd = &golabCommand{
 		if flags.Sudo != nil {
 			req.Scope.Sudo = *flags.Sudo
 		}
+		if cmd.Cmd.Flags().Changed("sudo") {
+			req.Scope.Sudo, _ = cmd.Cmd.Flags().GetBool("sudo")
+		}
 
 		if flags.Date != nil {
 			req.Date = *flags.Date
@@ -162,11 +308,23 @@ var personalAccessTokenCmd = &golabCommand{
 	},
 }
 
-func writeGolabConf(host string, token string) error {
-	conf := []byte(fmt.Sprintf("---\nurl: \"%s\"\ntoken: \"%s\"", host, token))
+type golabConf struct {
+	Url              string `yaml:"url"`
+	Auth             string `yaml:"auth,omitempty"`
+	Token            string `yaml:"token,omitempty"`
+	CredentialStore  string `yaml:"credential_store,omitempty"`
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+	CredentialFile   string `yaml:"credential_file,omitempty"`
+}
+
+func writeGolabConf(conf golabConf) error {
+	content, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
 	pwd, err := os.Getwd()
 	filename := path2.Join(pwd, ".golab.yml")
-	err = ioutil.WriteFile(filename, conf, 0600)
+	err = ioutil.WriteFile(filename, append([]byte("---\n"), content...), 0600)
 	if err == nil {
 		fmt.Printf("** golab config written to %s\n", filename)
 	}
@@ -183,4 +341,7 @@ func askForPassword() (string, error) {
 func init() {
 	loginCmd.Init()
 	personalAccessTokenCmd.Init()
+	// --sudo was renamed to --sudo_scope, when --sudo became a global flag, the local flag shadows the global one
+	personalAccessTokenCmd.Cmd.Flags().Bool("sudo", false, "Perform API actions as any user in the system (default: false)")
+	personalAccessTokenCmd.Cmd.Flags().MarkDeprecated("sudo", "use --sudo_scope instead")
 }
@@ -0,0 +1,82 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mapper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// defaultKey returns the key of a flag in the defaults of the golab config, e.g. merge-requests.create.target_branch
+func (m FlagMapper) defaultKey(flagName string) string {
+	path := strings.Fields(m.cmd.CommandPath())
+	return strings.Join(append(path[1:], flagName), ".")
+}
+
+// defaultEnv returns the environment variable for the default of a flag, e.g. GOLAB_MERGE_REQUESTS_CREATE_TARGET_BRANCH
+func (m FlagMapper) defaultEnv(flagName string) string {
+	return "GOLAB_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(m.defaultKey(flagName)))
+}
+
+// flagDefault returns the default value(s) of a flag that was not given on the command line,
+// environment variables take precedence over the defaults of the golab config
+//
+//	defaults:
+//	  merge-requests.create.target_branch: develop
+func (m FlagMapper) flagDefault(flagName string) ([]string, bool) {
+	if value, ok := os.LookupEnv(m.defaultEnv(flagName)); ok {
+		return []string{value}, true
+	}
+	key := m.defaultKey(flagName)
+	value, ok := viper.GetStringMap("defaults")[strings.ToLower(key)]
+	if !ok || value == nil {
+		// the defaults might be nested as well, e.g. merge-requests: {create: {target_branch: develop}}
+		if value = viper.Get("defaults." + key); value == nil {
+			return nil, false
+		}
+	}
+	if values, ok := value.([]interface{}); ok {
+		var result []string
+		for _, v := range values {
+			result = append(result, fmt.Sprint(v))
+		}
+		return result, true
+	}
+	return []string{fmt.Sprint(value)}, true
+}
+
+// setDefault sets the flag to its default, so that it is mapped like a flag given on the command line
+func (m FlagMapper) setDefault(flagName string) (bool, error) {
+	values, ok := m.flagDefault(flagName)
+	if !ok {
+		return false, nil
+	}
+	for _, value := range values {
+		if err := m.cmd.PersistentFlags().Set(flagName, value); err != nil {
+			return false, &FlagError{Flag: flagName, Value: value, Err: fmt.Errorf("invalid default \"%s\" for flag --%s (%s or %s in the golab config): %s",
+				value, flagName, m.defaultEnv(flagName), "defaults."+m.defaultKey(flagName), err)}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mapper
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("flag defaults", func() {
+
+	type createFlags struct {
+		Target *string   `flag_name:"target_branch" type:"string" required:"yes" description:"Target branch"`
+		Labels *[]string `flag_name:"labels" type:"array" required:"no" description:"Labels"`
+		Squash *bool     `flag_name:"squash" type:"bool" required:"no" description:"Squash commits"`
+	}
+
+	var root, cmd *Command
+
+	BeforeEach(func() {
+		root = &Command{Use: "golab"}
+		cmd = mockCmd()
+		root.AddCommand(cmd)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("GOLAB_MOCK_TARGET_BRANCH")
+		viper.Reset()
+	})
+
+	It("takes the default from the environment", func() {
+		os.Setenv("GOLAB_MOCK_TARGET_BRANCH", "develop")
+		flags := &createFlags{}
+		mapper := InitializedMapper(cmd, flags, nil)
+
+		_, _, err := executeCommand(root, "mock")
+		Expect(err).To(BeNil())
+		_, _, err = mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*flags.Target).To(Equal("develop"))
+	})
+
+	It("takes the defaults from the golab config", func() {
+		viper.Set("defaults", map[string]interface{}{
+			"mock.target_branch": "develop",
+			"mock.labels":        []interface{}{"bug", "ui"},
+			"mock.squash":        true,
+		})
+		flags := &createFlags{}
+		mapper := InitializedMapper(cmd, flags, nil)
+
+		executeCommand(root, "mock")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*flags.Target).To(Equal("develop"))
+		Expect(*flags.Labels).To(Equal([]string{"bug", "ui"}))
+		Expect(*flags.Squash).To(BeTrue())
+	})
+
+	It("prefers the environment over the golab config", func() {
+		viper.Set("defaults", map[string]interface{}{"mock.target_branch": "from-config"})
+		os.Setenv("GOLAB_MOCK_TARGET_BRANCH", "from-env")
+		flags := &createFlags{}
+		mapper := InitializedMapper(cmd, flags, nil)
+
+		executeCommand(root, "mock")
+		mapper.AutoMap()
+
+		Expect(*flags.Target).To(Equal("from-env"))
+	})
+
+	It("prefers the command line over the defaults", func() {
+		viper.Set("defaults", map[string]interface{}{"mock.target_branch": "from-config"})
+		os.Setenv("GOLAB_MOCK_TARGET_BRANCH", "from-env")
+		flags := &createFlags{}
+		mapper := InitializedMapper(cmd, flags, nil)
+
+		executeCommand(root, "mock", "--target_branch", "from-flag")
+		mapper.AutoMap()
+
+		Expect(*flags.Target).To(Equal("from-flag"))
+	})
+
+	It("does not satisfy required flags without a default", func() {
+		mapper := InitializedMapper(cmd, &createFlags{}, nil)
+
+		executeCommand(root, "mock")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("returns an error for invalid defaults", func() {
+		viper.Set("defaults", map[string]interface{}{"mock.squash": "maybe"})
+		os.Setenv("GOLAB_MOCK_TARGET_BRANCH", "develop")
+		mapper := InitializedMapper(cmd, &createFlags{}, nil)
+
+		executeCommand(root, "mock")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("invalid default \"maybe\" for flag --squash (GOLAB_MOCK_SQUASH or defaults.mock.squash in the golab config)"))
+	})
+})
@@ -22,6 +22,7 @@ package mapper
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -37,6 +38,45 @@ type FlagMapper struct {
 	cmd   *cobra.Command
 	flags interface{}
 	opts  interface{}
+	err   error // error of SetFlags, returned when the flags are mapped
+}
+
+// FlagError is returned if a required flag is missing or the value of a flag cannot be converted
+type FlagError struct {
+	Flag     string // name of the flag
+	Value    string // value given for the flag
+	Expected string // description of the expected format, empty for missing flags
+	Err      error
+}
+
+func (e *FlagError) Error() string {
+	if e.Expected != "" {
+		return fmt.Sprintf("invalid value \"%s\" for flag --%s, expected %s", e.Value, e.Flag, e.Expected)
+	}
+	return e.Err.Error()
+}
+
+func requiredFlagError(flagName string, err error) *FlagError {
+	message := "required flag --" + flagName + " was empty"
+	if err != nil {
+		message += " and could not be resolved: " + err.Error()
+	}
+	return &FlagError{Flag: flagName, Err: errors.New(message)}
+}
+
+// EnumCompletionFunction is the bash function that completes the values of flags with an `enum` tag,
+// it has to be added to the BashCompletionFunction of the root command
+const EnumCompletionFunction = `__golab_complete_enum() {
+    COMPREPLY=( $(compgen -W "$*" -- "$cur") )
+}`
+
+// resolvers provide values for flags with a `resolve` tag that are not given
+// on the command line, e.g. the project of the git repository in the working directory
+var resolvers = map[string]func() (string, error){}
+
+// RegisterResolver registers a resolver for flags tagged with `resolve:"<name>"`
+func RegisterResolver(name string, resolver func() (string, error)) {
+	resolvers[name] = resolver
 }
 
 func New(cmd *cobra.Command) FlagMapper {
@@ -49,11 +89,12 @@ func InitializedMapper(cmd *cobra.Command, flags interface{}, opts interface{})
 		flags: flags,
 		opts:  opts,
 	}
-	mapper.SetFlags(flags)
+	mapper.err = mapper.SetFlags(flags)
+	mapper.setFromFileFlag()
 	return mapper
 }
 
-func (m FlagMapper) SetFlags(flags interface{}) {
+func (m FlagMapper) SetFlags(flags interface{}) error {
 	if flags != nil {
 		v := reflect.ValueOf(flags).Elem()
 		for i := 0; i < v.NumField(); i++ {
@@ -65,30 +106,47 @@ func (m FlagMapper) SetFlags(flags interface{}) {
 			switch f.Type().String() {
 			case "*int":
 				m.cmd.PersistentFlags().IntP(flagName, shortHand, 0, flagUsage(tag))
-			case "*string":
+			case "*int64":
+				m.cmd.PersistentFlags().Int64P(flagName, shortHand, 0, flagUsage(tag))
+			case "*float64":
+				m.cmd.PersistentFlags().Float64P(flagName, shortHand, 0, flagUsage(tag))
+			case "*string", "*time.Time":
 				m.cmd.PersistentFlags().StringP(flagName, shortHand, "", flagUsage(tag))
 			case "*bool":
 				m.cmd.PersistentFlags().BoolP(flagName, shortHand, false, flagUsage(tag))
-			case "*[]string":
-				m.cmd.PersistentFlags().StringArrayP(flagName, shortHand, nil, flagUsage(tag))
-			case "[]int":
+			case "*time.Duration":
+				m.cmd.PersistentFlags().DurationP(flagName, shortHand, 0, flagUsage(tag))
+			case "*[]string", "[]int", "*map[string]string":
 				m.cmd.PersistentFlags().StringArrayP(flagName, shortHand, nil, flagUsage(tag))
 			default:
-				panic("Unknown type " + f.Type().String())
+				return errors.New("flag --" + flagName + " has unsupported type " + f.Type().String())
+			}
+			if enum := tag.Get("enum"); enum != "" {
+				// see EnumCompletionFunction
+				completion := "__golab_complete_enum " + strings.Replace(enum, ",", " ", -1)
+				m.cmd.PersistentFlags().SetAnnotation(flagName, cobra.BashCompCustom, []string{completion})
 			}
 		}
 	}
+	return nil
 }
 
 func flagUsage(tag reflect.StructTag) string {
 	description := tag.Get("description")
 	required := tag.Get("required")
+	resolve := tag.Get("resolve")
 	usage := ""
-	if required == "yes" {
+	if required == "yes" && resolve == "" {
 		usage = "(required) "
 	} else {
 		usage = "(optional) "
 	}
+	if enum := tag.Get("enum"); enum != "" {
+		description += " (one of " + strings.Replace(enum, ",", ", ", -1) + ")"
+	}
+	if resolve != "" {
+		description += " - if omitted, the " + resolve + " is taken from the git repository in the working directory"
+	}
 	return usage + description
 }
 
@@ -106,6 +164,9 @@ func (m FlagMapper) MappedFlags() interface{} {
 }
 
 func (m FlagMapper) Map(flags interface{}, opts interface{}) error {
+	if m.err != nil {
+		return m.err
+	}
 	if flags == nil {
 		return nil
 	}
@@ -113,6 +174,9 @@ func (m FlagMapper) Map(flags interface{}, opts interface{}) error {
 	flagsReflected := reflect.ValueOf(flags).Elem()
 	if opts != nil {
 		optsReflected = reflect.ValueOf(opts).Elem()
+		if err := m.readOptsFile(opts); err != nil {
+			return err
+		}
 	}
 
 	for i := 0; i < flagsReflected.NumField(); i++ {
@@ -120,208 +184,419 @@ func (m FlagMapper) Map(flags interface{}, opts interface{}) error {
 		tag := flagsReflected.Type().Field(i).Tag
 
 		flagName := tag.Get("flag_name")
+		fieldName := flagsReflected.Type().Field(i).Name
+		var opt reflect.Value
+		if opts != nil {
+			opt = optsReflected.FieldByName(fieldName)
+		}
+
+		// precedence: command line, --from-file, environment, golab config, resolver
 		flagChanged := m.cmd.PersistentFlags().Changed(flagName) // flagChanged --> value for flag has been set on command line
+		fromFile := opt.IsValid() && !isZero(opt)
+		if !flagChanged && !fromFile {
+			changed, err := m.setDefault(flagName)
+			if err != nil {
+				return err
+			}
+			flagChanged = changed
+		}
+		if resolve := tag.Get("resolve"); !flagChanged && !fromFile && resolve != "" {
+			err := m.resolve(flagName, resolve)
+			if err != nil && tag.Get("required") == "yes" {
+				return requiredFlagError(flagName, err)
+			}
+			flagChanged = err == nil
+		}
 
 		// see https://stackoverflow.com/questions/6395076/using-reflect-how-do-you-set-the-value-of-a-struct-field
 		// see https://stackoverflow.com/questions/40060131/reflect-assign-a-pointer-struct-value
 		if flagChanged {
-			fieldName := flagsReflected.Type().Field(i).Name
+			if err := m.validateEnum(flagName, tag.Get("enum")); err != nil {
+				return err
+			}
 			if opts != nil {
-				opt := optsReflected.FieldByName(fieldName)
-				mapOpt(opt, tag, m, flagName, flag, fieldName)
+				if err := mapOpt(opt, tag, m, flagName, flag, fieldName); err != nil {
+					return err
+				}
+			}
+			if err := mapFlag(flag, m, flagName); err != nil {
+				return err
+			}
+		} else if fromFile {
+			if err := setFromOpt(flag, opt, flagName); err != nil {
+				return err
+			}
+			if err := checkEnum(flagName, tag.Get("enum"), flagValues(flag)); err != nil {
+				return err
 			}
-			mapFlag(flag, m, flagName)
 		} else {
 			if required := tag.Get("required"); required == "yes" {
-				return errors.New("required flag --" + flagName + " was empty")
+				return requiredFlagError(flagName, nil)
 			}
 		}
 	}
 	return nil
 }
 
-func mapFlag(value reflect.Value, mapper FlagMapper, tagName string) {
-	mapValue(value, mapper, tagName, value)
+// resolve sets the flag to the value of the resolver, so that it is mapped like a flag given on the command line
+func (m FlagMapper) resolve(flagName string, resolverName string) error {
+	resolver, ok := resolvers[resolverName]
+	if !ok {
+		return errors.New("no resolver registered for " + resolverName)
+	}
+	value, err := resolver()
+	if err != nil {
+		return err
+	}
+	return m.cmd.PersistentFlags().Set(flagName, value)
 }
 
-func mapOpt(opt reflect.Value, tag reflect.StructTag, mapper FlagMapper, flagName string, value reflect.Value, fieldName string) {
-	if opt.IsValid() {
-		// A Value can be changed only if it is addressable and was not obtained by the use of unexported struct fields.
-		if opt.CanSet() {
-			if transform := tag.Get("transform"); transform != "" {
-				value, err := mapper.cmd.PersistentFlags().GetString(flagName)
-				if err != nil {
-					panic(err.Error())
-				}
-				transformAndSet(transform, opt, value)
-			} else {
-				mapValue(value, mapper, flagName, opt)
+// setFromOpt sets the flag to the value of the option given in the --from-file. Options of another type than
+// their flag are converted, string flags get the value in the format of the command line (e.g. 30 for an access
+// level or a,b for labels), so that commands can rely on the flags of all options that are set
+func setFromOpt(flag reflect.Value, opt reflect.Value, flagName string) error {
+	if opt.Type() == flag.Type() {
+		flag.Set(opt)
+		return nil
+	}
+	value := reflect.Indirect(opt)
+	flagType := flag.Type().Elem()
+	if value.Kind() == flagType.Kind() && value.Type().ConvertibleTo(flagType) {
+		converted := reflect.New(flagType)
+		converted.Elem().Set(value.Convert(flagType))
+		flag.Set(converted)
+		return nil
+	}
+	if flagType.Kind() != reflect.String {
+		return &FlagError{Flag: flagName, Err: fmt.Errorf("option %s of --from-file cannot be used for flag --%s of type %s", value.Type(), flagName, flagType)}
+	}
+
+	var s string
+	switch v := value.Interface().(type) {
+	case gitlab.Labels:
+		s = strings.Join(v, ",")
+	case gitlab.ISOTime:
+		s = time.Time(v).Format("2006-01-02")
+	case time.Time:
+		s = v.Format("2006-01-02")
+	default:
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s = strconv.FormatInt(value.Int(), 10)
+		case reflect.Bool:
+			s = strconv.FormatBool(value.Bool())
+		default:
+			bytes, err := json.Marshal(value.Interface())
+			if err != nil {
+				return &FlagError{Flag: flagName, Err: fmt.Errorf("option %s of --from-file cannot be used for flag --%s: %s", value.Type(), flagName, err)}
 			}
-		} else {
-			panic(fieldName + " can not be set")
+			s = string(bytes)
 		}
+	}
+	flag.Set(reflect.ValueOf(&s))
+	return nil
+}
+
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return value.IsNil()
+	}
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+// validateEnum checks the value(s) of a flag with an `enum:"a,b,c"` tag
+func (m FlagMapper) validateEnum(flagName string, enum string) error {
+	if enum == "" {
+		return nil
+	}
+	values := []string{}
+	if flag := m.cmd.PersistentFlags().Lookup(flagName); flag.Value.Type() == "stringArray" {
+		values, _ = m.cmd.PersistentFlags().GetStringArray(flagName)
 	} else {
+		values = append(values, flag.Value.String())
+	}
+	return checkEnum(flagName, enum, values)
+}
+
+// checkEnum returns a flag error for the first value that is not one of the comma separated values of the enum
+func checkEnum(flagName string, enum string, values []string) error {
+	if enum == "" {
+		return nil
+	}
+	allowed := strings.Split(enum, ",")
+	for _, value := range values {
+		if !contains(allowed, value) {
+			return &FlagError{Flag: flagName, Value: value, Expected: "one of " + strings.Join(allowed, ", ")}
+		}
+	}
+	return nil
+}
+
+// flagValues returns the values of string and string array flags, which are the only flags with enums
+func flagValues(flag reflect.Value) []string {
+	switch v := flag.Interface().(type) {
+	case *string:
+		return []string{*v}
+	case *[]string:
+		return *v
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mapFlag(value reflect.Value, mapper FlagMapper, tagName string) error {
+	return mapValue(value, mapper, tagName, value)
+}
+
+func mapOpt(opt reflect.Value, tag reflect.StructTag, mapper FlagMapper, flagName string, value reflect.Value, fieldName string) error {
+	if !opt.IsValid() {
 		// for the moment, we want to ignore flags, that are not available in opts
-		// panic(fieldName + " is not valid")
+		return nil
+	}
+	// A Value can be changed only if it is addressable and was not obtained by the use of unexported struct fields.
+	if !opt.CanSet() {
+		return errors.New(fieldName + " can not be set")
+	}
+	if transform := tag.Get("transform"); transform != "" {
+		value, err := mapper.cmd.PersistentFlags().GetString(flagName)
+		if err != nil {
+			return err
+		}
+		return transformAndSet(transform, opt, flagName, value)
 	}
+	return mapValue(value, mapper, flagName, opt)
 }
 
-func mapValue(value reflect.Value, mapper FlagMapper, flagName string, opt reflect.Value) {
+func mapValue(value reflect.Value, mapper FlagMapper, flagName string, opt reflect.Value) error {
 	switch value.Type().String() {
 	case "*int":
-		mapInt(mapper, flagName, opt)
+		return mapInt(mapper, flagName, opt)
+	case "*int64":
+		return mapInt64(mapper, flagName, opt)
+	case "*float64":
+		return mapFloat64(mapper, flagName, opt)
+	case "*time.Duration":
+		return mapDuration(mapper, flagName, opt)
+	case "*time.Time":
+		return mapTime(mapper, flagName, opt)
+	case "*map[string]string":
+		return mapStringMap(mapper, flagName, opt)
 	case "*string":
-		mapString(mapper, flagName, opt)
+		return mapString(mapper, flagName, opt)
 	case "*bool":
-		mapBool(mapper, flagName, opt)
+		return mapBool(mapper, flagName, opt)
 	case "*[]string":
-		mapStringArray(mapper, flagName, opt)
+		return mapStringArray(mapper, flagName, opt)
 	case "[]int":
-		mapIntArray(mapper, flagName, opt)
-	default:
-		panic("Unknown type " + value.Type().String())
+		return mapIntArray(mapper, flagName, opt)
 	}
+	return errors.New("flag --" + flagName + " has unsupported type " + value.Type().String())
 }
 
-func mapInt(m FlagMapper, flagName string, opt reflect.Value) {
+func mapInt(m FlagMapper, flagName string, opt reflect.Value) error {
 	value, err := m.cmd.PersistentFlags().GetInt(flagName)
 	if err != nil {
-		panic(err.Error())
-	}
-	if typesMatch(opt, &value) {
-		opt.Set(reflect.ValueOf(&value))
+		return err
 	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
 }
 
-func mapString(m FlagMapper, flagName string, opt reflect.Value) {
+func mapString(m FlagMapper, flagName string, opt reflect.Value) error {
 	value, err := m.cmd.PersistentFlags().GetString(flagName)
 	if err != nil {
-		panic(err.Error())
-	}
-	if typesMatch(opt, &value) {
-		opt.Set(reflect.ValueOf(&value))
+		return err
 	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
 }
 
-func mapStringArray(m FlagMapper, flagName string, opt reflect.Value) {
+func mapStringArray(m FlagMapper, flagName string, opt reflect.Value) error {
 	value, err := m.cmd.PersistentFlags().GetStringArray(flagName)
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
-	if typesMatch(opt, &value) {
-		opt.Set(reflect.ValueOf(&value))
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
+}
+
+func mapInt64(m FlagMapper, flagName string, opt reflect.Value) error {
+	value, err := m.cmd.PersistentFlags().GetInt64(flagName)
+	if err != nil {
+		return err
 	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
 }
 
-func mapIntArray(m FlagMapper, flagName string, opt reflect.Value) {
-	value, err := m.cmd.PersistentFlags().GetStringArray(flagName)
+func mapFloat64(m FlagMapper, flagName string, opt reflect.Value) error {
+	value, err := m.cmd.PersistentFlags().GetFloat64(flagName)
+	if err != nil {
+		return err
+	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
+}
+
+func mapDuration(m FlagMapper, flagName string, opt reflect.Value) error {
+	value, err := m.cmd.PersistentFlags().GetDuration(flagName)
+	if err != nil {
+		return err
+	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
+}
+
+func mapTime(m FlagMapper, flagName string, opt reflect.Value) error {
+	s, err := m.cmd.PersistentFlags().GetString(flagName)
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
-	// TODO cobra does not parse "1,2,3,4" into an array
-	sarr := strings.Split(value[0], ",")
-	arr := stringArray2IntArray(sarr)
-	if typesMatch(opt, arr) {
-		opt.Set(reflect.ValueOf(arr))
+	value, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return &FlagError{Flag: flagName, Value: s, Expected: "a time in RFC3339 format like 2018-12-31T23:59:00Z", Err: err}
 	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
 }
 
-func stringArray2IntArray(s []string) []int {
+// mapStringMap maps repeated key=value flags like --var a=1 --var b=2 to a map
+func mapStringMap(m FlagMapper, flagName string, opt reflect.Value) error {
+	pairs, err := m.cmd.PersistentFlags().GetStringArray(flagName)
+	if err != nil {
+		return err
+	}
+	value := map[string]string{}
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return &FlagError{Flag: flagName, Value: pair, Expected: "a key=value pair"}
+		}
+		value[kv[0]] = kv[1]
+	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
+}
+
+// mapIntArray maps repeated and comma-separated flags like --ids 1,2 --ids 3 to a slice
+func mapIntArray(m FlagMapper, flagName string, opt reflect.Value) error {
+	values, err := m.cmd.PersistentFlags().GetStringArray(flagName)
+	if err != nil {
+		return err
+	}
+	arr := []int{}
+	for _, value := range values {
+		ints, err := stringArray2IntArray(strings.Split(value, ","))
+		if err != nil {
+			return &FlagError{Flag: flagName, Value: value, Expected: "a comma-separated list of numbers", Err: err}
+		}
+		arr = append(arr, ints...)
+	}
+	return setOpt(opt, flagName, reflect.ValueOf(arr))
+}
+
+func stringArray2IntArray(s []string) ([]int, error) {
 	var result = []int{}
 	for _, i := range s {
-		j, err := strconv.Atoi(i)
+		j, err := strconv.Atoi(strings.TrimSpace(i))
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		result = append(result, j)
 	}
-	return result
+	return result, nil
 }
 
-func mapBool(m FlagMapper, flagName string, opt reflect.Value) {
+func mapBool(m FlagMapper, flagName string, opt reflect.Value) error {
 	value, err := m.cmd.PersistentFlags().GetBool(flagName)
 	if err != nil {
-		panic(err.Error())
-	}
-	if typesMatch(opt, &value) {
-		opt.Set(reflect.ValueOf(&value))
+		return err
 	}
+	return setOpt(opt, flagName, reflect.ValueOf(&value))
 }
 
-func transformAndSet(transform string, opt reflect.Value, value string) {
-	fieldType := opt.Type()
-
+// transformAndSet calls the transform function, which returns the transformed value and an error
+// describing the expected format of the value
+func transformAndSet(transform string, opt reflect.Value, flagName string, value string) error {
 	transformedValue, err := call(funcs, transform, value)
 	if err != nil {
-		panic(err.Error())
+		return err
+	}
+	if err, _ := transformedValue[1].Interface().(error); err != nil {
+		return &FlagError{Flag: flagName, Value: value, Expected: err.Error(), Err: err}
 	}
 
-	opt.Set(transformedValue[0].Convert(fieldType))
+	opt.Set(transformedValue[0].Convert(opt.Type()))
+	return nil
 }
 
-func str2Visibility(s string) *gitlab.VisibilityValue {
-	if s == "private" {
-		return gitlab.Visibility(gitlab.PrivateVisibility)
-	}
-	if s == "internal" {
-		return gitlab.Visibility(gitlab.InternalVisibility)
-	}
-	if s == "public" {
-		return gitlab.Visibility(gitlab.PublicVisibility)
+const dateFormat = "a date like 2018-12-31"
+
+func str2Visibility(s string) (*gitlab.VisibilityValue, error) {
+	switch s {
+	case "private":
+		return gitlab.Visibility(gitlab.PrivateVisibility), nil
+	case "internal":
+		return gitlab.Visibility(gitlab.InternalVisibility), nil
+	case "public":
+		return gitlab.Visibility(gitlab.PublicVisibility), nil
 	}
-	return nil
+	return nil, errors.New("one of private, internal or public")
 }
 
-func string2IsoTime(s string) *gitlab.ISOTime {
+func string2IsoTime(s string) (*gitlab.ISOTime, error) {
 	isotime, err := time.Parse("2006-01-02", s)
 	if err != nil {
-		panic(err.Error())
+		return nil, errors.New(dateFormat)
 	}
 	t := gitlab.ISOTime(isotime)
-	return &t
+	return &t, nil
 }
 
-func str2AccessLevel(s string) *gitlab.AccessLevelValue {
-	if s == "10" {
-		return gitlab.AccessLevel(gitlab.GuestPermissions)
-	}
-	if s == "20" {
-		return gitlab.AccessLevel(gitlab.ReporterPermissions)
-	}
-	if s == "30" {
-		return gitlab.AccessLevel(gitlab.DeveloperPermissions)
-	}
-	if s == "40" {
-		return gitlab.AccessLevel(gitlab.MasterPermissions)
-	}
-	if s == "50" {
-		return gitlab.AccessLevel(gitlab.OwnerPermission)
-	}
-	panic("Unknown access level: " + s)
+func str2AccessLevel(s string) (*gitlab.AccessLevelValue, error) {
+	switch s {
+	case "10":
+		return gitlab.AccessLevel(gitlab.GuestPermissions), nil
+	case "20":
+		return gitlab.AccessLevel(gitlab.ReporterPermissions), nil
+	case "30":
+		return gitlab.AccessLevel(gitlab.DeveloperPermissions), nil
+	case "40":
+		return gitlab.AccessLevel(gitlab.MasterPermissions), nil
+	case "50":
+		return gitlab.AccessLevel(gitlab.OwnerPermission), nil
+	}
+	return nil, errors.New("an access level of 10 (guest), 20 (reporter), 30 (developer), 40 (master) or 50 (owner)")
 }
 
-func string2TimeVal(s string) time.Time {
+func string2TimeVal(s string) (time.Time, error) {
 	t, err := time.Parse("2006-01-02", s)
 	if err != nil {
-		panic(err.Error())
+		return t, errors.New(dateFormat)
 	}
-	return t
+	return t, nil
 }
 
-func string2Time(s string) *time.Time {
-	t := string2TimeVal(s)
-	return &t
+func string2Time(s string) (*time.Time, error) {
+	t, err := string2TimeVal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
 }
 
-func string2Labels(s string) gitlab.Labels {
+func string2Labels(s string) (gitlab.Labels, error) {
 	stringSlice := strings.Split(s, ",")
-	return stringSlice
+	return stringSlice, nil
 }
 
-func json2CommitActions(s string) []*gitlab.CommitAction {
+func json2CommitActions(s string) ([]*gitlab.CommitAction, error) {
 	var v []*gitlab.CommitAction
-	json.Unmarshal([]byte(s), &v)
-	return v
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, errors.New("a JSON array of commit actions (" + err.Error() + ")")
+	}
+	return v, nil
 }
 
 var funcs = map[string]interface{}{
@@ -335,6 +610,9 @@ var funcs = map[string]interface{}{
 }
 
 func call(m map[string]interface{}, name string, params ...interface{}) (result []reflect.Value, err error) {
+	if _, ok := m[name]; !ok {
+		return nil, errors.New("unknown transform " + name)
+	}
 	f := reflect.ValueOf(m[name])
 	if len(params) != f.Type().NumIn() {
 		err = errors.New("the number of params is not adapted")
@@ -348,9 +626,11 @@ func call(m map[string]interface{}, name string, params ...interface{}) (result
 	return
 }
 
-func typesMatch(target reflect.Value, source interface{}) bool {
-	targetType := target.Type().String()
-	sourceType := reflect.ValueOf(source).Type().String()
-
-	return targetType == sourceType
+// setOpt sets the option to the value of the flag, flags of another type than their option need a transform
+func setOpt(opt reflect.Value, flagName string, value reflect.Value) error {
+	if opt.Type() != value.Type() {
+		return &FlagError{Flag: flagName, Err: fmt.Errorf("flag --%s of type %s cannot be mapped to an option of type %s", flagName, value.Type().Elem(), opt.Type())}
+	}
+	opt.Set(value)
+	return nil
 }
@@ -22,6 +22,7 @@ package mapper
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
 	"reflect"
@@ -199,7 +200,7 @@ var _ = Describe("FlagMapper", func() {
 		Expect(err).To(BeNil())
 	})
 
-	It("skips args with non-matching types as expected", func() {
+	It("returns a flag error for args with non-matching types", func() {
 		flags := &testFlags{}
 		opts := &testOptsNonMatching{}
 		mockCmd := mockCmd()
@@ -207,8 +208,10 @@ var _ = Describe("FlagMapper", func() {
 		flagMapper.SetFlags(flags)
 
 		executeCommand(mockCmd, "mock", "--flag1", "true", "--flag2", "string", "--flag3", "4", "--flag4", "v1, v2, v3")
-		flagMapper.Map(flags, opts)
+		err := flagMapper.Map(flags, opts)
 
+		Expect(err).To(BeAssignableToTypeOf(&FlagError{}))
+		Expect(err).To(MatchError("flag --flag4 of type []string cannot be mapped to an option of type *string"))
 		Expect(opts.Flag4).To(BeNil())
 	})
 
@@ -318,6 +321,143 @@ var _ = Describe("FlagMapper", func() {
 		Expect(len(opts.Actions)).To(Equal(4))
 	})
 
+	It("returns a flag error for values that cannot be transformed", func() {
+		type invalidValueFlags struct {
+			Time        *string `flag_name:"time" type:"string" required:"no" description:"time" transform:"string2IsoTime"`
+			AccessLevel *string `flag_name:"access_level" type:"string" required:"no" description:"access level" transform:"str2AccessLevel"`
+			Actions     *string `flag_name:"actions" type:"string" required:"no" description:"actions" transform:"json2CommitActions"`
+		}
+		type invalidValueOpts struct {
+			Time        *gitlab.ISOTime
+			AccessLevel *gitlab.AccessLevelValue
+			Actions     []*gitlab.CommitAction
+		}
+
+		for args, expected := range map[string]string{
+			"--time=13.12.2017":    `invalid value "13.12.2017" for flag --time, expected a date like 2018-12-31`,
+			"--access_level=60":    `invalid value "60" for flag --access_level, expected an access level of 10 (guest), 20 (reporter), 30 (developer), 40 (master) or 50 (owner)`,
+			"--actions=[{action:}": `invalid value "[{action:}" for flag --actions, expected a JSON array of commit actions (invalid character 'a' looking for beginning of object key string)`,
+		} {
+			cmd := mockCmd()
+			mapper := InitializedMapper(cmd, &invalidValueFlags{}, &invalidValueOpts{})
+			executeCommand(cmd, "mock", args)
+			_, _, err := mapper.AutoMap()
+			Expect(err).To(BeAssignableToTypeOf(&FlagError{}))
+			Expect(err).To(MatchError(expected))
+		}
+	})
+
+	It("returns a flag error for invalid int arrays", func() {
+		type intArrayFlags struct {
+			Ids []int `flag_name:"ids" type:"[]int" required:"no" description:"ids"`
+		}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &intArrayFlags{}, nil)
+		executeCommand(cmd, "mock", "--ids", "1,two")
+		_, _, err := mapper.AutoMap()
+		Expect(err).To(MatchError(`invalid value "1,two" for flag --ids, expected a comma-separated list of numbers`))
+	})
+
+	It("returns an error for flags of unsupported types instead of panicking", func() {
+		type unsupportedFlags struct {
+			Ratio *float32 `flag_name:"ratio" type:"float" required:"no" description:"ratio"`
+		}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &unsupportedFlags{}, nil)
+		_, _, err := mapper.AutoMap()
+		Expect(err).To(MatchError("flag --ratio has unsupported type *float32"))
+	})
+
+	It("maps durations, int64, float64, times, maps and repeated ints", func() {
+		type typedFlags struct {
+			Timeout *time.Duration     `flag_name:"timeout" type:"duration" required:"no" description:"timeout"`
+			Size    *int64             `flag_name:"size" type:"int64" required:"no" description:"size"`
+			Ratio   *float64           `flag_name:"ratio" type:"float64" required:"no" description:"ratio"`
+			Since   *time.Time         `flag_name:"since" type:"time" required:"no" description:"since"`
+			Vars    *map[string]string `flag_name:"var" type:"map" required:"no" description:"vars"`
+			Ids     []int              `flag_name:"ids" type:"[]int" required:"no" description:"ids"`
+		}
+		type typedOpts struct {
+			Timeout *time.Duration
+			Size    *int64
+			Ratio   *float64
+			Since   *time.Time
+			Vars    *map[string]string
+			Ids     []int
+		}
+		flags := &typedFlags{}
+		opts := &typedOpts{}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, flags, opts)
+
+		executeCommand(cmd, "mock", "--timeout", "1m30s", "--size", "8589934592", "--ratio", "0.75", "--since", "2018-12-31T23:59:00Z",
+			"--var", "a=1", "--var", "b=x=y", "--ids", "1,2", "--ids", "3")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*opts.Timeout).To(Equal(90 * time.Second))
+		Expect(*opts.Size).To(Equal(int64(8589934592)))
+		Expect(*opts.Ratio).To(Equal(0.75))
+		Expect(*opts.Since).To(Equal(time.Date(2018, 12, 31, 23, 59, 0, 0, time.UTC)))
+		Expect(*opts.Vars).To(Equal(map[string]string{"a": "1", "b": "x=y"}))
+		Expect(opts.Ids).To(Equal([]int{1, 2, 3}))
+		Expect(*flags.Vars).To(Equal(map[string]string{"a": "1", "b": "x=y"}))
+	})
+
+	It("returns flag errors for invalid times and key=value pairs", func() {
+		type typedFlags struct {
+			Since *time.Time         `flag_name:"since" type:"time" required:"no" description:"since"`
+			Vars  *map[string]string `flag_name:"var" type:"map" required:"no" description:"vars"`
+		}
+		for args, expected := range map[string]string{
+			"--since=2018-12-31": `invalid value "2018-12-31" for flag --since, expected a time in RFC3339 format like 2018-12-31T23:59:00Z`,
+			"--var=a":            `invalid value "a" for flag --var, expected a key=value pair`,
+		} {
+			cmd := mockCmd()
+			mapper := InitializedMapper(cmd, &typedFlags{}, nil)
+			executeCommand(cmd, "mock", args)
+			_, _, err := mapper.AutoMap()
+			Expect(err).To(MatchError(expected))
+		}
+	})
+
+	Describe("enums", func() {
+
+		type enumFlags struct {
+			Sort   *string   `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Sort order"`
+			Scopes *[]string `flag_name:"scope" type:"array" required:"no" enum:"api,read_user" description:"Scopes"`
+		}
+
+		It("validates the values of enum flags", func() {
+			cmd := mockCmd()
+			mapper := InitializedMapper(cmd, &enumFlags{}, nil)
+			executeCommand(cmd, "mock", "--sort", "up")
+			_, _, err := mapper.AutoMap()
+			Expect(err).To(MatchError(`invalid value "up" for flag --sort, expected one of asc, desc`))
+
+			cmd = mockCmd()
+			mapper = InitializedMapper(cmd, &enumFlags{}, nil)
+			executeCommand(cmd, "mock", "--scope", "api", "--scope", "sudo")
+			_, _, err = mapper.AutoMap()
+			Expect(err).To(MatchError(`invalid value "sudo" for flag --scope, expected one of api, read_user`))
+
+			cmd = mockCmd()
+			mapper = InitializedMapper(cmd, &enumFlags{}, nil)
+			executeCommand(cmd, "mock", "--sort", "desc", "--scope", "api")
+			_, _, err = mapper.AutoMap()
+			Expect(err).To(BeNil())
+		})
+
+		It("adds the values to the usage and the bash completion", func() {
+			cmd := mockCmd()
+			InitializedMapper(cmd, &enumFlags{}, nil)
+			flag := cmd.PersistentFlags().Lookup("sort")
+			Expect(flag.Usage).To(Equal("(optional) Sort order (one of asc, desc)"))
+			Expect(flag.Annotations[BashCompCustom]).To(Equal([]string{"__golab_complete_enum asc desc"}))
+		})
+
+	})
+
 	It("silently ignores properties in flags that are not available in opts", func() {
 		flags := &testFlagsWithPropertyNotInOpts{}
 		opts := &testOptsWithMissingProperty{}
@@ -368,6 +508,60 @@ var _ = Describe("FlagMapper", func() {
 		Expect(err.Error()).To(Equal("required flag --flag1 was empty"))
 	})
 
+	Context("with flags that can be resolved", func() {
+
+		type resolvedFlags struct {
+			Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"test-project" description:"The project"`
+		}
+		type resolvedOpts struct {
+			Id *string
+		}
+
+		It("marks resolved flags as optional", func() {
+			mockCmd := mockCmd()
+			InitializedMapper(mockCmd, &resolvedFlags{}, nil)
+			Expect(mockCmd.PersistentFlags().Lookup("id").Usage).To(Equal("(optional) The project - if omitted, the test-project is taken from the git repository in the working directory"))
+		})
+
+		It("uses the resolver, if the flag is not given", func() {
+			RegisterResolver("test-project", func() (string, error) { return "my-group/my-project", nil })
+			flags, opts := &resolvedFlags{}, &resolvedOpts{}
+			mockCmd := mockCmd()
+			mapper := InitializedMapper(mockCmd, flags, opts)
+
+			executeCommand(mockCmd, "mock")
+			_, _, err := mapper.AutoMap()
+
+			Expect(err).To(BeNil())
+			Expect(*flags.Id).To(Equal("my-group/my-project"))
+			Expect(*opts.Id).To(Equal("my-group/my-project"))
+		})
+
+		It("prefers the flag given on the command line", func() {
+			RegisterResolver("test-project", func() (string, error) { return "my-group/my-project", nil })
+			flags := &resolvedFlags{}
+			mockCmd := mockCmd()
+			mapper := InitializedMapper(mockCmd, flags, nil)
+
+			executeCommand(mockCmd, "mock", "-i", "42")
+			mapper.AutoMap()
+
+			Expect(*flags.Id).To(Equal("42"))
+		})
+
+		It("returns an error, if a required flag cannot be resolved", func() {
+			RegisterResolver("test-project", func() (string, error) { return "", errors.New("not a git repository") })
+			mockCmd := mockCmd()
+			mapper := InitializedMapper(mockCmd, &resolvedFlags{}, nil)
+
+			executeCommand(mockCmd, "mock")
+			_, _, err := mapper.AutoMap()
+
+			Expect(err).To(MatchError("required flag --id was empty and could not be resolved: not a git repository"))
+		})
+
+	})
+
 })
 
 // TODO put the following methods into a testhelper
@@ -0,0 +1,96 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+const fromFileFlag = "from-file"
+
+// setFromFileFlag adds --from-file to commands that map their flags into the options of a request
+func (m FlagMapper) setFromFileFlag() {
+	if m.opts == nil || reflect.TypeOf(m.opts) == reflect.TypeOf(m.flags) || m.cmd.PersistentFlags().Lookup(fromFileFlag) != nil {
+		return
+	}
+	m.cmd.PersistentFlags().String(fromFileFlag, "", "(optional) YAML or JSON file with the options of the request (use - to read from stdin), flags given on the command line take precedence")
+}
+
+// readOptsFile unmarshals the file given with --from-file into the options, the keys
+// of the file are the names of the options in the Gitlab API, e.g. issues_enabled
+func (m FlagMapper) readOptsFile(opts interface{}) error {
+	if m.cmd.PersistentFlags().Lookup(fromFileFlag) == nil || !m.cmd.PersistentFlags().Changed(fromFileFlag) {
+		return nil
+	}
+	file, err := m.cmd.PersistentFlags().GetString(fromFileFlag)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if file == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return &FlagError{Flag: fromFileFlag, Value: file, Err: fmt.Errorf("could not read --%s: %s", fromFileFlag, err)}
+	}
+
+	// YAML is a superset of JSON, the options are unmarshalled from JSON to use the json tags of go-gitlab
+	var values interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return &FlagError{Flag: fromFileFlag, Value: file, Expected: "a YAML or JSON file (" + err.Error() + ")", Err: err}
+	}
+	content, err = json.Marshal(jsonCompatible(values))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(opts); err != nil {
+		return &FlagError{Flag: fromFileFlag, Value: file, Expected: "the options of the request (" + err.Error() + ")", Err: err}
+	}
+	return nil
+}
+
+// jsonCompatible converts the map[interface{}]interface{} of yaml.v2 into map[string]interface{}
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, value := range v {
+			result[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return result
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonCompatible(value)
+		}
+	}
+	return value
+}
@@ -0,0 +1,165 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mapper
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("--from-file", func() {
+
+	type projectFlags struct {
+		Name          *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"Name of the project"`
+		Visibility    *string `flag_name:"visibility" type:"string" required:"no" transform:"string2visibility" description:"Visibility of the project"`
+		IssuesEnabled *bool   `flag_name:"issues_enabled" type:"bool" required:"no" description:"Enable issues"`
+	}
+
+	var file *os.File
+
+	BeforeEach(func() {
+		file, _ = ioutil.TempFile("", "golab-opts")
+	})
+
+	AfterEach(func() {
+		os.Remove(file.Name())
+	})
+
+	It("reads the options from a YAML file and lets flags override them", func() {
+		file.WriteString("name: from-file\nvisibility: internal\nissues_enabled: true\ntag_list: [golab, cli]\n")
+		flags := &projectFlags{}
+		opts := &gitlab.CreateProjectOptions{}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, flags, opts)
+
+		executeCommand(cmd, "mock", "--from-file", file.Name(), "--visibility", "private")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*opts.Name).To(Equal("from-file"))
+		Expect(*flags.Name).To(Equal("from-file"))
+		Expect(*opts.Visibility).To(Equal(gitlab.PrivateVisibility))
+		Expect(*opts.IssuesEnabled).To(BeTrue())
+		Expect(*opts.TagList).To(Equal([]string{"golab", "cli"}))
+	})
+
+	It("reads JSON files", func() {
+		file.WriteString(`{"name": "from-json"}`)
+		opts := &gitlab.CreateProjectOptions{}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &projectFlags{}, opts)
+
+		executeCommand(cmd, "mock", "--from-file", file.Name(), "--name", "from-flag")
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*opts.Name).To(Equal("from-flag"))
+	})
+
+	It("returns an error for unknown options", func() {
+		file.WriteString("name: from-file\nissues: true\n")
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &projectFlags{}, &gitlab.CreateProjectOptions{})
+
+		executeCommand(cmd, "mock", "--from-file", file.Name())
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeAssignableToTypeOf(&FlagError{}))
+		Expect(err.Error()).To(ContainSubstring(`expected the options of the request (json: unknown field "issues")`))
+	})
+
+	It("sets string flags of options with another type", func() {
+		type memberFlags struct {
+			AccessLevel *string `flag_name:"access_level" short:"a" type:"integer" required:"yes" transform:"str2AccessLevel" description:"A valid access level"`
+		}
+		file.WriteString("access_level: 30\n")
+		flags := &memberFlags{}
+		opts := &gitlab.AddGroupMemberOptions{}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, flags, opts)
+
+		executeCommand(cmd, "mock", "--from-file", file.Name())
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*flags.AccessLevel).To(Equal("30"))
+		Expect(*opts.AccessLevel).To(Equal(gitlab.DeveloperPermissions))
+	})
+
+	It("sets labels and dates of options as strings in the format of the command line", func() {
+		type issueFlags struct {
+			Labels  *string `flag_name:"labels" type:"string" required:"no" transform:"string2Labels" description:"Labels"`
+			DueDate *string `flag_name:"due_date" type:"string" required:"no" transform:"string2IsoTime" description:"Due date"`
+		}
+		file.WriteString("labels: [bug, critical]\ndue_date: 2018-12-31\n")
+		flags := &issueFlags{}
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, flags, &gitlab.CreateIssueOptions{})
+
+		executeCommand(cmd, "mock", "--from-file", file.Name())
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeNil())
+		Expect(*flags.Labels).To(Equal("bug,critical"))
+		Expect(*flags.DueDate).To(Equal("2018-12-31"))
+	})
+
+	It("returns an error for options that cannot be used for their flag", func() {
+		type nameFlags struct {
+			Name *int `flag_name:"name" type:"integer" required:"yes" description:"Name of the project"`
+		}
+		file.WriteString("name: from-file\n")
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &nameFlags{}, &gitlab.CreateProjectOptions{})
+
+		executeCommand(cmd, "mock", "--from-file", file.Name())
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeAssignableToTypeOf(&FlagError{}))
+		Expect(err).To(MatchError("option string of --from-file cannot be used for flag --name of type int"))
+	})
+
+	It("validates the values of enum flags", func() {
+		type listFlags struct {
+			Sort *string `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Sort order"`
+		}
+		file.WriteString("sort: sideways\n")
+		cmd := mockCmd()
+		mapper := InitializedMapper(cmd, &listFlags{}, &gitlab.ListProjectsOptions{})
+
+		executeCommand(cmd, "mock", "--from-file", file.Name())
+		_, _, err := mapper.AutoMap()
+
+		Expect(err).To(BeAssignableToTypeOf(&FlagError{}))
+		Expect(err).To(MatchError(`invalid value "sideways" for flag --sort, expected one of asc, desc`))
+	})
+
+	It("is not added to commands without separate options", func() {
+		cmd := mockCmd()
+		InitializedMapper(cmd, &projectFlags{}, &projectFlags{})
+		Expect(cmd.PersistentFlags().Lookup("from-file")).To(BeNil())
+	})
+
+})
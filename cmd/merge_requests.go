@@ -22,6 +22,10 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
 
 	. "github.com/michaellihs/golab/cmd/helpers"
 
@@ -42,18 +46,18 @@ var mergeRequestsCmd = &cobra.Command{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#list-merge-requests
 type mergeRequestsListFlags struct {
-	State           *string `flag_name:"state" type:"string" required:"no" description:"Return all merge requests or just those that are opened, closed, or merged"`
-	OrderBy         *string `flag_name:"order_by" type:"string" required:"no" description:"Return requests ordered by created_at or updated_at fields. Default is created_at"`
-	Sort            *string `flag_name:"sort" type:"string" required:"no" description:"Return requests sorted in asc or desc order. Default is desc"`
-	Milestone       *string `flag_name:"milestone" type:"string" required:"no" description:"Return merge requests for a specific milestone"`
-	View            *string `flag_name:"view" type:"string" required:"no" description:"If simple, returns the iid, URL, title, description, and basic state of merge request"`
-	Labels          *string `flag_name:"labels" type:"string" required:"no" description:"Return merge requests matching a comma separated list of labels"`
-	CreatedAfter    *string `flag_name:"created_after" type:"datetime" required:"no" description:"Return merge requests created after the given time (inclusive)"`
-	CreatedBefore   *string `flag_name:"created_before" type:"datetime" required:"no" description:"Return merge requests created before the given time (inclusive)"`
-	Scope           *string `flag_name:"scope" type:"string" required:"no" description:"Return merge requests for the given scope: created-by-me, assigned-to-me or all. Defaults to created-by-me"`
-	AuthorId        *int    `flag_name:"author_id" type:"integer" required:"no" description:"Returns merge requests created by the given user id. Combine with scope=all or scope=assigned-to-me"`
-	AssigneeId      *int    `flag_name:"assignee_id" type:"integer" required:"no" description:"Returns merge requests assigned to the given user id"`
-	MyReactionEmoji *string `flag_name:"my_reaction_emoji" type:"string" required:"no" description:"Return merge requests reacted by the authenticated user by the given emoji (Introduced in GitLab 10.0)"`
+	State           *string    `flag_name:"state" type:"string" required:"no" description:"Return all merge requests or just those that are opened, closed, or merged"`
+	OrderBy         *string    `flag_name:"order_by" type:"string" required:"no" description:"Return requests ordered by created_at or updated_at fields. Default is created_at"`
+	Sort            *string    `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return requests sorted in asc or desc order. Default is desc"`
+	Milestone       *string    `flag_name:"milestone" type:"string" required:"no" description:"Return merge requests for a specific milestone"`
+	View            *string    `flag_name:"view" type:"string" required:"no" description:"If simple, returns the iid, URL, title, description, and basic state of merge request"`
+	Labels          *string    `flag_name:"labels" type:"[]string" transform:"string2Labels" required:"no" description:"Return merge requests matching a comma separated list of labels"`
+	CreatedAfter    *time.Time `flag_name:"created_after" type:"datetime" required:"no" description:"Return merge requests created after the given time (inclusive), e.g. 2018-12-31T23:59:00Z"`
+	CreatedBefore   *time.Time `flag_name:"created_before" type:"datetime" required:"no" description:"Return merge requests created before the given time (inclusive), e.g. 2018-12-31T23:59:00Z"`
+	Scope           *string    `flag_name:"scope" type:"string" required:"no" description:"Return merge requests for the given scope: created-by-me, assigned-to-me or all. Defaults to created-by-me"`
+	AuthorId        *int       `flag_name:"author_id" type:"integer" required:"no" description:"Returns merge requests created by the given user id. Combine with scope=all or scope=assigned-to-me"`
+	AssigneeId      *int       `flag_name:"assignee_id" type:"integer" required:"no" description:"Returns merge requests assigned to the given user id"`
+	MyReactionEmoji *string    `flag_name:"my_reaction_emoji" type:"string" required:"no" description:"Return merge requests reacted by the authenticated user by the given emoji (Introduced in GitLab 10.0)"`
 }
 
 var mergeRequestsListCmd = &golabCommand{
@@ -81,20 +85,20 @@ Note: the changes_count value in the response is a string, not an integer. This
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#list-project-merge-requests
 type mergeRequestsListForProjectFlags struct {
-	Id              *int    `flag_name:"id" type:"integer" required:"yes" description:"The ID of a project"`
-	IIDs            []int   `flag_name:"iids" type:"Array[integer]" required:"no" description:"Return the request having the given iid"`
-	State           *string `flag_name:"state" type:"string" required:"no" description:"Return all merge requests or just those that are opened, closed, or merged"`
-	OrderBy         *string `flag_name:"order_by" type:"string" required:"no" description:"Return requests ordered by created_at or updated_at fields. Default is created_at"`
-	Sort            *string `flag_name:"sort" type:"string" required:"no" description:"Return requests sorted in asc or desc order. Default is desc"`
-	Milestone       *string `flag_name:"milestone" type:"string" required:"no" description:"Return merge requests for a specific milestone"`
-	View            *string `flag_name:"view" type:"string" required:"no" description:"If simple, returns the iid, URL, title, description, and basic state of merge request"`
-	Labels          *string `flag_name:"labels" type:"[]string" transform:"string2Labels" required:"no" description:"Return merge requests matching a comma separated list of labels"`
-	CreatedAfter    *string `flag_name:"created_after" type:"datetime" required:"no" description:"Return merge requests created after the given time (inclusive)"`
-	CreatedBefore   *string `flag_name:"created_before" type:"datetime" required:"no" description:"Return merge requests created before the given time (inclusive)"`
-	Scope           *string `flag_name:"scope" type:"string" required:"no" description:"Return merge requests for the given scope: created-by-me, assigned-to-me or all (Introduced in GitLab 9.5)"`
-	AuthorID        *int    `flag_name:"author_id" type:"integer" required:"no" description:"Returns merge requests created by the given user id (Introduced in GitLab 9.5)"`
-	AssigneeID      *int    `flag_name:"assignee_id" type:"integer" required:"no" description:"Returns merge requests assigned to the given user id (Introduced in GitLab 9.5)"`
-	MyReactionEmoji *string `flag_name:"my_reaction_emoji" type:"string" required:"no" description:"Return merge requests reacted by the authenticated user by the given emoji (Introduced in GitLab 10.0)"`
+	Id              *string    `flag_name:"id" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	IIDs            []int      `flag_name:"iids" type:"Array[integer]" required:"no" description:"Return the request having the given iid"`
+	State           *string    `flag_name:"state" type:"string" required:"no" description:"Return all merge requests or just those that are opened, closed, or merged"`
+	OrderBy         *string    `flag_name:"order_by" type:"string" required:"no" description:"Return requests ordered by created_at or updated_at fields. Default is created_at"`
+	Sort            *string    `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return requests sorted in asc or desc order. Default is desc"`
+	Milestone       *string    `flag_name:"milestone" type:"string" required:"no" description:"Return merge requests for a specific milestone"`
+	View            *string    `flag_name:"view" type:"string" required:"no" description:"If simple, returns the iid, URL, title, description, and basic state of merge request"`
+	Labels          *string    `flag_name:"labels" type:"[]string" transform:"string2Labels" required:"no" description:"Return merge requests matching a comma separated list of labels"`
+	CreatedAfter    *time.Time `flag_name:"created_after" type:"datetime" required:"no" description:"Return merge requests created after the given time (inclusive), e.g. 2018-12-31T23:59:00Z"`
+	CreatedBefore   *time.Time `flag_name:"created_before" type:"datetime" required:"no" description:"Return merge requests created before the given time (inclusive), e.g. 2018-12-31T23:59:00Z"`
+	Scope           *string    `flag_name:"scope" type:"string" required:"no" description:"Return merge requests for the given scope: created-by-me, assigned-to-me or all (Introduced in GitLab 9.5)"`
+	AuthorID        *int       `flag_name:"author_id" type:"integer" required:"no" description:"Returns merge requests created by the given user id (Introduced in GitLab 9.5)"`
+	AssigneeID      *int       `flag_name:"assignee_id" type:"integer" required:"no" description:"Returns merge requests assigned to the given user id (Introduced in GitLab 9.5)"`
+	MyReactionEmoji *string    `flag_name:"my_reaction_emoji" type:"string" required:"no" description:"Return merge requests reacted by the authenticated user by the given emoji (Introduced in GitLab 10.0)"`
 }
 
 var mergeRequestsListForProjectCmd = &golabCommand{
@@ -119,7 +123,7 @@ var mergeRequestsListForProjectCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-single-mr
 type mergeRequestGetFlags struct {
-	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	Iid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -143,7 +147,7 @@ var mergeRequestGetCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-single-mr-commits
 type mergeRequestGetCommitsFlags struct {
-	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	Iid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -167,7 +171,7 @@ var mergeRequestsGetCommitsCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-single-mr-changes
 type mergeRequestsGetChangesFlags struct {
-	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id  *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	Iid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -189,18 +193,65 @@ var mergeRequestsGetChangesCmd = &golabCommand{
 	},
 }
 
+type mergeRequestsDiffFlags struct {
+	Id          *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	Stat        *bool   `flag_name:"stat" type:"boolean" required:"no" description:"Show the number of changed lines per file instead of the diff (default: false)"`
+	NameOnly    *bool   `flag_name:"name_only" type:"boolean" required:"no" description:"Show the names of the changed files instead of the diff (default: false)"`
+	Color       *string `flag_name:"color" type:"string" required:"no" enum:"auto,always,never" description:"When to color the diff, auto colors the diff if the output is a terminal (default: auto)"`
+	FromVersion *int    `flag_name:"from_version" type:"integer" required:"no" description:"ID of a diff version (see get-diff-versions) to compare with the version given with --to_version or the latest version"`
+	ToVersion   *int    `flag_name:"to_version" type:"integer" required:"no" description:"ID of the diff version to show, default is the latest version"`
+}
+
+var mergeRequestsDiffCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Flags:  &mergeRequestsDiffFlags{},
+	Cmd: &cobra.Command{
+		Use:   "diff <merge request iid>",
+		Short: "Show merge request diff",
+		Long: `Shows the changes of a merge request as unified diff, like git diff does.
+
+Use --from_version to see what changed between two versions of the merge request, e.g. since the last review.`,
+		Args: cobra.ExactArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsDiffFlags)
+		iid, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return errors.New("merge request iid must be a number: " + cmd.Args[0])
+		}
+		diffs, err := mergeRequestDiffs(*flags.Id, iid, flags.FromVersion, flags.ToVersion)
+		if err != nil {
+			return err
+		}
+		renderer := DiffRenderer{Out: os.Stdout, Color: useColor(flags.Color)}
+		switch {
+		case flags.NameOnly != nil && *flags.NameOnly:
+			renderer.RenderNames(diffs)
+		case flags.Stat != nil && *flags.Stat:
+			renderer.RenderStat(diffs)
+		default:
+			renderer.Render(diffs)
+		}
+		return nil
+	},
+}
+
 // see https://docs.gitlab.com/ce/api/merge_requests.html#create-mr
 type mergeRequestsCreateFlags struct {
-	Id                 *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
-	SourceBranch       *string `flag_name:"source_branch" short:"s" type:"string" required:"yes" description:"The source branch"`
-	TargetBranch       *string `flag_name:"target_branch" short:"t" type:"string" required:"yes" description:"The target branch"`
-	Title              *string `flag_name:"title" short:"n" type:"string" required:"yes" description:"Title of MR"`
+	Id                 *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	SourceBranch       *string `flag_name:"source_branch" short:"s" type:"string" required:"no" description:"The source branch, default is the branch checked out in the working directory"`
+	TargetBranch       *string `flag_name:"target_branch" short:"t" type:"string" required:"no" description:"(required with --source_branch) The target branch, default is the default branch of the project"`
+	Title              *string `flag_name:"title" short:"n" type:"string" required:"no" description:"(required with --source_branch) Title of MR, default is taken from the commits of the source branch"`
 	AssigneeId         *int    `flag_name:"assignee_id" short:"a" type:"integer" required:"no" description:"Assignee user ID"`
-	Description        *string `flag_name:"description" short:"d" type:"string" required:"no" description:"Description of MR"`
+	Description        *string `flag_name:"description" short:"d" type:"string" required:"no" description:"Description of MR, default is taken from the template or the commits of the source branch"`
 	TargetProjectId    *int    `flag_name:"target_project_id" type:"integer" required:"no" description:"The target project (numeric id)"`
 	Labels             *string `flag_name:"labels" type:"[]string" transform:"string2Labels" required:"no" description:"Labels for MR as a comma-separated list"`
 	MilestoneId        *int    `flag_name:"milestone_id" type:"integer" required:"no" description:"The ID of a milestone"`
 	RemoveSourceBranch *bool   `flag_name:"remove_source_branch" type:"boolean" required:"no" description:"Flag indicating if a merge request should remove the source branch when merging"`
+	Fill               *bool   `flag_name:"fill" type:"boolean" required:"no" description:"Take target branch, title and description from git for a given --source_branch as well (default: false)"`
+	Template           *string `flag_name:"template" type:"string" required:"no" description:"Name of the template in .gitlab/merge_request_templates to use for the description (implies --fill), default is the template Default"`
+	NoEdit             *bool   `flag_name:"no_edit" type:"boolean" required:"no" description:"Do not open $EDITOR for title and description, it is only opened in a terminal (default: false)"`
+	Push               *bool   `flag_name:"push" type:"boolean" required:"no" description:"Push the source branch to the git remote, if it is not up to date (default: false)"`
 }
 
 var mergeRequestsCreateCmd = &golabCommand{
@@ -210,11 +261,20 @@ var mergeRequestsCreateCmd = &golabCommand{
 	Cmd: &cobra.Command{
 		Use:   "create",
 		Short: "Create merge request",
-		Long:  `Creates a new merge request.`,
+		Long: `Creates a new merge request.
+
+Without --source_branch, the merge request is created for the branch checked out in the working directory:
+the target branch defaults to the default branch of the project, title and description are taken from the
+commits of the branch or from the merge request template and can be edited in $EDITOR, if golab runs in a
+terminal. Use --fill to do the same for a given --source_branch and --push to push the source branch to the
+git remote (see --remote) first.`,
 	},
 	Run: func(cmd golabCommand) error {
 		flags := cmd.Flags.(*mergeRequestsCreateFlags)
 		opts := cmd.Opts.(*gitlab.CreateMergeRequestOptions)
+		if err := prepareMergeRequest(flags, opts); err != nil {
+			return err
+		}
 		mr, _, err := gitlabClient.MergeRequests.CreateMergeRequest(*flags.Id, opts)
 		if err != nil {
 			return err
@@ -223,9 +283,47 @@ var mergeRequestsCreateCmd = &golabCommand{
 	},
 }
 
+type mergeRequestsCheckoutFlags struct {
+	Id     *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Branch *string `flag_name:"branch" short:"b" type:"string" required:"no" description:"Name of the local branch, default is the source branch of the MR (prefixed with the namespace of the fork for MRs from forks)"`
+	Update *bool   `flag_name:"update" type:"boolean" required:"no" description:"Fast-forward the local branch, if it exists already (default: false)"`
+}
+
+var mergeRequestsCheckoutCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Flags:  &mergeRequestsCheckoutFlags{},
+	Cmd: &cobra.Command{
+		Use:   "checkout <merge request iid>",
+		Short: "Check out merge request",
+		Long: `Fetches the head of a merge request (refs/merge-requests/<iid>/head) from the git remote (see --remote)
+into a local branch and checks it out. The upstream of the branch is set to the source branch of the MR, in the
+fork for MRs from forks, so that git pull and git push work as expected.
+
+If the branch exists already, it is checked out and fast-forwarded with --update.`,
+		Args: cobra.ExactArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsCheckoutFlags)
+		iid, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return errors.New("merge request iid must be a number: " + cmd.Args[0])
+		}
+		mr, _, err := gitlabClient.MergeRequests.GetMergeRequest(*flags.Id, iid)
+		if err != nil {
+			return err
+		}
+		branch, err := checkoutMergeRequest(mr, flags.Branch, flags.Update != nil && *flags.Update)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Switched to branch %s for merge request !%d\n", branch, mr.IID)
+		return nil
+	},
+}
+
 // see https://docs.gitlab.com/ce/api/merge_requests.html#update-mr
 type mergeRequestUpdateFlags struct {
-	Id                 *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id                 *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	MergeRequestIid    *int    `flag_name:"merge_request_iid" short:"m" type:"integer" required:"yes" description:"The ID of a merge request"`
 	TargetBranch       *string `flag_name:"target_branch" type:"string" required:"no" description:"The target branch"`
 	Title              *string `flag_name:"title" type:"string" required:"no" description:"Title of MR"`
@@ -260,7 +358,7 @@ var mergeRequestUpdateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#delete-a-merge-request
 type mergeRequestsDeleteFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -281,7 +379,7 @@ var mergeRequestsDeleteCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#accept-mr
 type mergeRequestAcceptFlags struct {
-	Id                        *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id                        *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	MergeRequestIid           *int    `flag_name:"merge_request_iid" short:"m" type:"int" required:"yes" description:"Internal ID of MR"`
 	MergeCommitMessage        *string `flag_name:"merge_commit_message" type:"string" required:"no" description:"Custom merge commit message"`
 	ShouldRemoveSourceBranch  *bool   `flag_name:"should_remove_source_branch" short:"d" type:"bool" required:"no" description:"if true removes the source branch"`
@@ -319,7 +417,7 @@ If you don't have permissions to accept this merge request - you'll get a 401`,
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#cancel-merge-when-pipeline-succeeds
 type mergeRequestsCancelPipelineSucceedsFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -347,7 +445,7 @@ In case the merge request is not set to be merged when the pipeline succeeds, yo
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#list-issues-that-will-close-on-merge
 type mergeRequestsClosedIssuesUponMergeFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -371,7 +469,7 @@ var mergeRequestsClosedIssuesUponMergeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#subscribe-to-a-merge-request
 type mergeRequestsSubscribeFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -398,7 +496,7 @@ var mergeRequestsSubscribeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#unsubscribe-from-a-merge-request
 type mergeRequestsUnsubscribeFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -425,7 +523,7 @@ var mergeRequestsUnsubscribeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#create-a-todo
 type mergeRequestsCreateTodoFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -453,7 +551,7 @@ var mergeRequestsCreateTodoCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-mr-diff-versions
 type mergeRequestListDiffVersionsFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -477,7 +575,7 @@ var mergeRequestListDiffVersionsCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-a-single-mr-diff-version
 type mergeRequestsGetSingleDiffVersionFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 	VersionId       *int    `flag_name:"version_id" short:"v" type:"integer" required:"yes" description:"The ID of the merge request diff version"`
 }
@@ -502,7 +600,7 @@ var mergeRequestGetSingleDiffVersionCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#set-a-time-estimate-for-a-merge-request
 type mergeRequestsSetTimeEstimateFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 	Duration        *string `flag_name:"duration" short:"d" type:"string" required:"yes" description:"The duration in human format. e.g: 3h30m"`
 }
@@ -529,7 +627,7 @@ var mergeRequestsSetTimeEstimateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#reset-the-time-estimate-for-a-merge-request
 type mergeRequestResetTimeEstimateFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -553,7 +651,7 @@ var mergeRequestsResetTimeEstimateCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#add-spent-time-for-a-merge-request
 type mergeRequestsAddSpentTimeFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 	Duration        *string `flag_name:"duration" short:"d" type:"string" required:"yes" description:"The duration in human format. e.g: 3h30m"`
 }
@@ -580,7 +678,7 @@ var mergeRequestsAddSpentTimeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#reset-spent-time-for-a-merge-request
 type mergeRequestsResetSpentTimeFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -604,7 +702,7 @@ var mergeRequestsResetSpentTimeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/merge_requests.html#get-time-tracking-stats
 type mergeRequestsGetTimeTrackingStatsFlags struct {
-	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL encoded path of a project"`
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
 	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
 }
 
@@ -632,7 +730,9 @@ func init() {
 	mergeRequestGetCmd.Init()
 	mergeRequestsGetCommitsCmd.Init()
 	mergeRequestsGetChangesCmd.Init()
+	mergeRequestsDiffCmd.Init()
 	mergeRequestsCreateCmd.Init()
+	mergeRequestsCheckoutCmd.Init()
 	mergeRequestUpdateCmd.Init()
 	mergeRequestsDeleteCmd.Init()
 	mergeRequestAcceptCmd.Init()
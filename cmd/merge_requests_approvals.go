@@ -0,0 +1,411 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
+)
+
+// go-gitlab only supports getting the approvals of a merge request,
+// the other endpoints of https://docs.gitlab.com/ee/api/merge_request_approvals.html are called directly
+
+type approveOptions struct {
+	Sha *string `url:"sha,omitempty" json:"sha,omitempty"`
+}
+
+// projectApprovals is the approval configuration of a project
+type projectApprovals struct {
+	ApprovalsBeforeMerge                      int  `json:"approvals_before_merge"`
+	ResetApprovalsOnPush                      bool `json:"reset_approvals_on_push"`
+	DisableOverridingApproversPerMergeRequest bool `json:"disable_overriding_approvers_per_merge_request"`
+	MergeRequestsAuthorApproval               bool `json:"merge_requests_author_approval"`
+	MergeRequestsDisableCommittersApproval    bool `json:"merge_requests_disable_committers_approval"`
+}
+
+type changeProjectApprovalsOptions struct {
+	ApprovalsBeforeMerge                      *int  `url:"approvals_before_merge,omitempty" json:"approvals_before_merge,omitempty"`
+	ResetApprovalsOnPush                      *bool `url:"reset_approvals_on_push,omitempty" json:"reset_approvals_on_push,omitempty"`
+	DisableOverridingApproversPerMergeRequest *bool `url:"disable_overriding_approvers_per_merge_request,omitempty" json:"disable_overriding_approvers_per_merge_request,omitempty"`
+	MergeRequestsAuthorApproval               *bool `url:"merge_requests_author_approval,omitempty" json:"merge_requests_author_approval,omitempty"`
+	MergeRequestsDisableCommittersApproval    *bool `url:"merge_requests_disable_committers_approval,omitempty" json:"merge_requests_disable_committers_approval,omitempty"`
+}
+
+// approvalRule requires a number of approvals of the given users or members of the given groups
+type approvalRule struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	RuleType          string          `json:"rule_type"`
+	ApprovalsRequired int             `json:"approvals_required"`
+	Users             []*gitlab.User  `json:"users"`
+	Groups            []*gitlab.Group `json:"groups"`
+}
+
+type approvalRuleOptions struct {
+	Name              *string `url:"name,omitempty" json:"name,omitempty"`
+	ApprovalsRequired *int    `url:"approvals_required,omitempty" json:"approvals_required,omitempty"`
+	UserIDs           *[]int  `url:"user_ids,omitempty" json:"user_ids,omitempty"`
+	GroupIDs          *[]int  `url:"group_ids,omitempty" json:"group_ids,omitempty"`
+}
+
+// see https://docs.gitlab.com/ee/api/merge_request_approvals.html#approve-merge-request
+type mergeRequestsApproveFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	Sha             *string `flag_name:"sha" type:"string" required:"no" description:"The HEAD of the merge request, the approval fails with 409 if the merge request was changed in the meantime"`
+}
+
+var mergeRequestsApproveCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Flags:  &mergeRequestsApproveFlags{},
+	Opts:   &approveOptions{},
+	Cmd: &cobra.Command{
+		Use:   "approve",
+		Short: "Approve merge request",
+		Long: `Approve a merge request as the authenticated user.
+
+If the user already approved the merge request or is not allowed to approve it - you'll get a 401
+
+If the sha parameter is passed and does not match the HEAD of the source - you'll get a 409`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsApproveFlags)
+		opts := cmd.Opts.(*approveOptions)
+		approvals, err := approveMergeRequest(*flags.Id, *flags.MergeRequestIid, opts)
+		if err != nil {
+			return err
+		}
+		return OutputJson(approvals)
+	},
+}
+
+// see https://docs.gitlab.com/ee/api/merge_request_approvals.html#unapprove-merge-request
+type mergeRequestsApprovalsFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+}
+
+var mergeRequestsUnapproveCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Flags:  &mergeRequestsApprovalsFlags{},
+	Cmd: &cobra.Command{
+		Use:   "unapprove",
+		Short: "Withdraw approval of merge request",
+		Long:  `Withdraw the approval of the authenticated user from a merge request and show the remaining approvals.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsApprovalsFlags)
+		path := fmt.Sprintf("projects/%s/merge_requests/%d/unapprove", url.QueryEscape(*flags.Id), *flags.MergeRequestIid)
+		// Gitlab answers without a body
+		if err := approvalsRequest("POST", path, nil, nil); err != nil {
+			return err
+		}
+		approvals, _, err := gitlabClient.MergeRequests.GetMergeRequestApprovals(*flags.Id, *flags.MergeRequestIid)
+		if err != nil {
+			return err
+		}
+		return OutputJson(approvals)
+	},
+}
+
+// see https://docs.gitlab.com/ee/api/merge_request_approvals.html#merge-request-level-mr-approvals
+var mergeRequestsApprovalsCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Flags:  &mergeRequestsApprovalsFlags{},
+	Cmd: &cobra.Command{
+		Use:   "approvals",
+		Short: "Get approvals of merge request",
+		Long:  `Get the approvals of a merge request, i.e. who approved it and how many approvals are missing.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsApprovalsFlags)
+		approvals, _, err := gitlabClient.MergeRequests.GetMergeRequestApprovals(*flags.Id, *flags.MergeRequestIid)
+		if err != nil {
+			return err
+		}
+		return OutputJson(approvals)
+	},
+}
+
+// see https://docs.gitlab.com/ee/api/merge_request_approvals.html#project-level-mr-approvals
+var projectApprovalsCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Cmd: &cobra.Command{
+		Use:   "approvals",
+		Short: "Manage the approval configuration of a project",
+		Long:  `Manage the approval configuration of a project, see approval-rules for the approvers.`,
+	},
+	Run: func(cmd golabCommand) error {
+		return errors.New("cannot run this command without further sub-commands")
+	},
+}
+
+var projectApprovalsGetCmd = &golabCommand{
+	Parent: projectApprovalsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "get",
+		Short: "Get approval configuration",
+		Long:  `Get the approval configuration of a project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		approvals := &projectApprovals{}
+		if err := approvalsRequest("GET", "projects/"+url.QueryEscape(*flags.Id)+"/approvals", nil, approvals); err != nil {
+			return err
+		}
+		return OutputJson(approvals)
+	},
+}
+
+type projectApprovalsSetFlags struct {
+	Id                                        *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	ApprovalsBeforeMerge                      *int    `flag_name:"approvals_before_merge" type:"integer" required:"no" description:"How many approvals are required before a merge request can be merged"`
+	ResetApprovalsOnPush                      *bool   `flag_name:"reset_approvals_on_push" type:"bool" required:"no" description:"Reset the approvals of a merge request when a new commit is pushed to it"`
+	DisableOverridingApproversPerMergeRequest *bool   `flag_name:"disable_overriding_approvers_per_merge_request" type:"bool" required:"no" description:"Do not allow to override the approvers per merge request"`
+	MergeRequestsAuthorApproval               *bool   `flag_name:"merge_requests_author_approval" type:"bool" required:"no" description:"Allow authors to approve their own merge requests"`
+	MergeRequestsDisableCommittersApproval    *bool   `flag_name:"merge_requests_disable_committers_approval" type:"bool" required:"no" description:"Do not allow committers to approve merge requests they committed to"`
+}
+
+var projectApprovalsSetCmd = &golabCommand{
+	Parent: projectApprovalsCmd.Cmd,
+	Flags:  &projectApprovalsSetFlags{},
+	Opts:   &changeProjectApprovalsOptions{},
+	Cmd: &cobra.Command{
+		Use:   "set",
+		Short: "Change approval configuration",
+		Long: `Change the approval configuration of a project, only the given settings are changed, e.g.
+
+    golab project approvals set --approvals_before_merge 2 --reset_approvals_on_push=true`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectApprovalsSetFlags)
+		opts := cmd.Opts.(*changeProjectApprovalsOptions)
+		if *opts == (changeProjectApprovalsOptions{}) {
+			return errors.New("at least one setting is required, see --help")
+		}
+		approvals := &projectApprovals{}
+		if err := approvalsRequest("POST", "projects/"+url.QueryEscape(*flags.Id)+"/approvals", opts, approvals); err != nil {
+			return err
+		}
+		return OutputJson(approvals)
+	},
+}
+
+// see https://docs.gitlab.com/ee/api/merge_request_approvals.html#get-project-level-rules
+var projectApprovalRulesCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Cmd: &cobra.Command{
+		Use:   "approval-rules",
+		Short: "Manage the approval rules of a project",
+		Long:  `Manage the rules, which approvers and how many approvals merge requests of a project need.`,
+	},
+	Run: func(cmd golabCommand) error {
+		return errors.New("cannot run this command without further sub-commands")
+	},
+}
+
+var projectApprovalRulesListCmd = &golabCommand{
+	Parent: projectApprovalRulesCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "ls",
+		Short: "List approval rules",
+		Long:  `Get the approval rules of a project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		rules := []*approvalRule{}
+		if err := approvalsRequest("GET", approvalRulesPath(*flags.Id, nil), nil, &rules); err != nil {
+			return err
+		}
+		return OutputJson(rules)
+	},
+}
+
+type projectApprovalRulesCreateFlags struct {
+	Id                *string   `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	Name              *string   `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the approval rule"`
+	ApprovalsRequired *int      `flag_name:"approvals_required" short:"a" type:"integer" required:"yes" description:"The number of approvals required by the rule"`
+	Users             *[]string `flag_name:"users" short:"u" type:"array" required:"no" description:"Usernames or IDs of the approvers, can be given multiple times"`
+	Groups            *[]string `flag_name:"groups" short:"g" type:"array" required:"no" description:"Paths or IDs of groups, whose members are approvers, can be given multiple times"`
+}
+
+var projectApprovalRulesCreateCmd = &golabCommand{
+	Parent: projectApprovalRulesCmd.Cmd,
+	Flags:  &projectApprovalRulesCreateFlags{},
+	Cmd: &cobra.Command{
+		Use:   "create",
+		Short: "Create approval rule",
+		Long: `Create an approval rule for a project, e.g.
+
+    golab project approval-rules create --name "Release managers" --approvals_required 1 --users alice --users bob`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectApprovalRulesCreateFlags)
+		opts := &approvalRuleOptions{Name: flags.Name, ApprovalsRequired: flags.ApprovalsRequired}
+		if err := setApprovers(opts, flags.Users, flags.Groups); err != nil {
+			return err
+		}
+		rule := &approvalRule{}
+		if err := approvalsRequest("POST", approvalRulesPath(*flags.Id, nil), opts, rule); err != nil {
+			return err
+		}
+		return OutputJson(rule)
+	},
+}
+
+type projectApprovalRulesUpdateFlags struct {
+	Id                *string   `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	RuleId            *int      `flag_name:"rule_id" short:"r" type:"integer" required:"yes" description:"The ID of the approval rule"`
+	Name              *string   `flag_name:"name" short:"n" type:"string" required:"no" description:"The new name of the approval rule"`
+	ApprovalsRequired *int      `flag_name:"approvals_required" short:"a" type:"integer" required:"no" description:"The number of approvals required by the rule"`
+	Users             *[]string `flag_name:"users" short:"u" type:"array" required:"no" description:"Usernames or IDs of the approvers, replace the current approvers. Use --users \"\" to remove all users"`
+	Groups            *[]string `flag_name:"groups" short:"g" type:"array" required:"no" description:"Paths or IDs of groups, whose members are approvers, replace the current groups. Use --groups \"\" to remove all groups"`
+}
+
+var projectApprovalRulesUpdateCmd = &golabCommand{
+	Parent: projectApprovalRulesCmd.Cmd,
+	Flags:  &projectApprovalRulesUpdateFlags{},
+	Cmd: &cobra.Command{
+		Use:   "update",
+		Short: "Update approval rule",
+		Long:  `Update an approval rule of a project, only the given settings are changed.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectApprovalRulesUpdateFlags)
+		opts := &approvalRuleOptions{Name: flags.Name, ApprovalsRequired: flags.ApprovalsRequired}
+		if err := setApprovers(opts, flags.Users, flags.Groups); err != nil {
+			return err
+		}
+		if *opts == (approvalRuleOptions{}) {
+			return errors.New("at least one of --name, --approvals_required, --users and --groups is required")
+		}
+		rule := &approvalRule{}
+		if err := approvalsRequest("PUT", approvalRulesPath(*flags.Id, flags.RuleId), opts, rule); err != nil {
+			return err
+		}
+		return OutputJson(rule)
+	},
+}
+
+type projectApprovalRulesDeleteFlags struct {
+	Id     *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	RuleId *int    `flag_name:"rule_id" short:"r" type:"integer" required:"yes" description:"The ID of the approval rule"`
+}
+
+var projectApprovalRulesDeleteCmd = &golabCommand{
+	Parent: projectApprovalRulesCmd.Cmd,
+	Flags:  &projectApprovalRulesDeleteFlags{},
+	Cmd: &cobra.Command{
+		Use:   "delete",
+		Short: "Delete approval rule",
+		Long:  `Delete an approval rule of a project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectApprovalRulesDeleteFlags)
+		return approvalsRequest("DELETE", approvalRulesPath(*flags.Id, flags.RuleId), nil, nil)
+	},
+}
+
+// approveMergeRequest approves the merge request as the authenticated user and returns its approvals
+func approveMergeRequest(pid string, iid int, opts *approveOptions) (*gitlab.MergeRequestApprovals, error) {
+	if opts == nil {
+		opts = &approveOptions{}
+	}
+	approvals := &gitlab.MergeRequestApprovals{}
+	path := fmt.Sprintf("projects/%s/merge_requests/%d/approve", url.QueryEscape(pid), iid)
+	if err := approvalsRequest("POST", path, opts, approvals); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// approvalsRequest sends a request to the approvals API and decodes the response into v, unless v is nil
+func approvalsRequest(method string, path string, opt interface{}, v interface{}) error {
+	req, err := gitlabClient.NewRequest(method, path, opt, nil)
+	if err != nil {
+		return err
+	}
+	_, err = gitlabClient.Do(req, v)
+	return err
+}
+
+func approvalRulesPath(pid string, ruleId *int) string {
+	path := "projects/" + url.QueryEscape(pid) + "/approval_rules"
+	if ruleId != nil {
+		path += "/" + strconv.Itoa(*ruleId)
+	}
+	return path
+}
+
+// setApprovers sets the IDs of the given users and groups, empty names are skipped to allow removing all approvers
+func setApprovers(opts *approvalRuleOptions, users *[]string, groups *[]string) error {
+	if users != nil {
+		ids := []int{}
+		for _, user := range *users {
+			if user == "" {
+				continue
+			}
+			id, err := userIdFromFlag(user)
+			if err != nil {
+				return fmt.Errorf("cannot find approver %s: %s", user, err)
+			}
+			ids = append(ids, id)
+		}
+		opts.UserIDs = &ids
+	}
+	if groups != nil {
+		ids := []int{}
+		for _, group := range *groups {
+			if group == "" {
+				continue
+			}
+			g, _, err := gitlabClient.Groups.GetGroup(group)
+			if err != nil {
+				return fmt.Errorf("cannot find approver group %s: %s", group, err)
+			}
+			ids = append(ids, g.ID)
+		}
+		opts.GroupIDs = &ids
+	}
+	return nil
+}
+
+func init() {
+	mergeRequestsApproveCmd.Init()
+	mergeRequestsUnapproveCmd.Init()
+	mergeRequestsApprovalsCmd.Init()
+	projectApprovalsCmd.Init()
+	projectApprovalsGetCmd.Init()
+	projectApprovalsSetCmd.Init()
+	projectApprovalRulesCmd.Init()
+	projectApprovalRulesListCmd.Init()
+	projectApprovalRulesCreateCmd.Init()
+	projectApprovalRulesUpdateCmd.Init()
+	projectApprovalRulesDeleteCmd.Init()
+}
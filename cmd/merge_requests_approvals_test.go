@@ -0,0 +1,160 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("project approvals", func() {
+
+	var (
+		mux      *http.ServeMux
+		server   *httptest.Server
+		requests []string
+		bodies   []map[string]interface{}
+	)
+
+	record := func(r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" || r.Method == "PUT" {
+			body := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(BeNil())
+			bodies = append(bodies, body)
+		}
+	}
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		for _, cmd := range []*golabCommand{projectApprovalsGetCmd, projectApprovalsSetCmd, projectApprovalRulesListCmd,
+			projectApprovalRulesCreateCmd, projectApprovalRulesUpdateCmd, projectApprovalRulesDeleteCmd} {
+			resetFlags(cmd)
+		}
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+		requests, bodies = nil, nil
+
+		mux.HandleFunc("/api/v4/projects/42/approvals", func(w http.ResponseWriter, r *http.Request) {
+			record(r)
+			fmt.Fprint(w, `{"approvals_before_merge": 2, "reset_approvals_on_push": true}`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/approval_rules", func(w http.ResponseWriter, r *http.Request) {
+			record(r)
+			if r.Method == "POST" {
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id": 1, "name": "Release managers", "approvals_required": 1}`)
+				return
+			}
+			fmt.Fprint(w, `[{"id": 1, "name": "Release managers", "approvals_required": 1, "users": [{"id": 5, "username": "jdoe"}]}]`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/approval_rules/1", func(w http.ResponseWriter, r *http.Request) {
+			record(r)
+			if r.Method == "DELETE" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			fmt.Fprint(w, `{"id": 1, "name": "Release managers", "approvals_required": 2}`)
+		})
+		mux.HandleFunc("/api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `[{"id": 5, "username": %q}]`, r.URL.Query().Get("username"))
+		})
+		mux.HandleFunc("/api/v4/groups/release-team", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id": 9, "path": "release-team"}`)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("gets the approval configuration of a project", func() {
+		stdout, _, err := executeCommand(RootCmd, "project", "approvals", "get", "-i", "42")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal([]string{"GET /api/v4/projects/42/approvals"}))
+		Expect(stdout).To(ContainSubstring(`"approvals_before_merge": 2`))
+		Expect(stdout).To(ContainSubstring(`"reset_approvals_on_push": true`))
+	})
+
+	It("changes only the given approval settings", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approvals", "set", "-i", "42", "--approvals_before_merge", "2", "--reset_approvals_on_push=false")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal([]string{"POST /api/v4/projects/42/approvals"}))
+		Expect(bodies[0]).To(Equal(map[string]interface{}{"approvals_before_merge": float64(2), "reset_approvals_on_push": false}))
+	})
+
+	It("requires at least one approval setting", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approvals", "set", "-i", "42")
+
+		Expect(err).NotTo(BeNil())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("lists the approval rules", func() {
+		stdout, _, err := executeCommand(RootCmd, "project", "approval-rules", "ls", "-i", "42")
+
+		Expect(err).To(BeNil())
+		Expect(stdout).To(ContainSubstring(`"name": "Release managers"`))
+		Expect(stdout).To(ContainSubstring(`"username": "jdoe"`))
+	})
+
+	It("creates an approval rule with approvers given by name", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approval-rules", "create", "-i", "42", "-n", "Release managers", "-a", "1",
+			"--users", "jdoe", "--users", "7", "--groups", "release-team")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal([]string{"POST /api/v4/projects/42/approval_rules"}))
+		Expect(bodies[0]).To(Equal(map[string]interface{}{"name": "Release managers", "approvals_required": float64(1),
+			"user_ids": []interface{}{float64(5), float64(7)}, "group_ids": []interface{}{float64(9)}}))
+	})
+
+	It("removes all users of an approval rule", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approval-rules", "update", "-i", "42", "-r", "1", "--users", "")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal([]string{"PUT /api/v4/projects/42/approval_rules/1"}))
+		Expect(bodies[0]).To(Equal(map[string]interface{}{"user_ids": []interface{}{}}))
+	})
+
+	It("requires a change to update an approval rule", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approval-rules", "update", "-i", "42", "-r", "1")
+
+		Expect(err).NotTo(BeNil())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("deletes an approval rule", func() {
+		_, _, err := executeCommand(RootCmd, "project", "approval-rules", "delete", "-i", "42", "-r", "1")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(Equal([]string{"DELETE /api/v4/projects/42/approval_rules/1"}))
+	})
+})
@@ -0,0 +1,98 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/xanzy/go-gitlab"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// mergeRequestDiffs returns the changes of the merge request, of a diff version
+// or between two diff versions, if from is given
+func mergeRequestDiffs(pid string, iid int, from *int, to *int) ([]FileDiff, error) {
+	if from == nil && to == nil {
+		mr, _, err := gitlabClient.MergeRequests.GetMergeRequestChanges(pid, iid)
+		if err != nil {
+			return nil, err
+		}
+		var diffs []FileDiff
+		for _, change := range mr.Changes {
+			diffs = append(diffs, FileDiff(change))
+		}
+		return diffs, nil
+	}
+
+	var toVersion *gitlab.MergeRequestDiffVersion
+	if to != nil {
+		version, _, err := gitlabClient.MergeRequests.GetSingleMergeRequestDiffVersion(pid, iid, *to)
+		if err != nil {
+			return nil, err
+		}
+		toVersion = version
+	} else {
+		versions, _, err := gitlabClient.MergeRequests.GetMergeRequestDiffVersions(pid, iid)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, errors.New("merge request has no diff versions")
+		}
+		// the latest version comes first
+		toVersion = versions[0]
+	}
+	if from == nil {
+		return fileDiffs(toVersion.Diffs), nil
+	}
+
+	fromVersion, _, err := gitlabClient.MergeRequests.GetSingleMergeRequestDiffVersion(pid, iid, *from)
+	if err != nil {
+		return nil, err
+	}
+	compare, _, err := gitlabClient.Repositories.Compare(pid, &gitlab.CompareOptions{
+		From: gitlab.String(fromVersion.HeadCommitSHA),
+		To:   gitlab.String(toVersion.HeadCommitSHA),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fileDiffs(compare.Diffs), nil
+}
+
+func fileDiffs(diffs []*gitlab.Diff) []FileDiff {
+	var fileDiffs []FileDiff
+	for _, diff := range diffs {
+		fileDiffs = append(fileDiffs, FileDiff{OldPath: diff.OldPath, NewPath: diff.NewPath, AMode: diff.AMode, BMode: diff.BMode,
+			Diff: diff.Diff, NewFile: diff.NewFile, RenamedFile: diff.RenamedFile, DeletedFile: diff.DeletedFile})
+	}
+	return fileDiffs
+}
+
+// useColor returns whether to color the output for the value of a --color flag
+func useColor(color *string) bool {
+	if color != nil && *color != "auto" {
+		return *color == "always"
+	}
+	return terminal.IsTerminal(int(os.Stdout.Fd()))
+}
@@ -0,0 +1,84 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("merge-requests diff", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(mergeRequestsDiffCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("renders the changes of the merge request", func() {
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/changes", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"iid": 7, "changes": [{"old_path": "README.md", "new_path": "README.md", "diff": "@@ -1 +1 @@\n-old\n+new\n"}]}`)
+		})
+		stdout, _, err := executeCommand(RootCmd, "mr", "diff", "7", "--id", "42")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(Equal("diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-old\n+new"))
+	})
+
+	It("compares two diff versions", func() {
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/versions", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"id": 2, "head_commit_sha": "bbb"}, {"id": 1, "head_commit_sha": "aaa"}]`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/versions/1", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id": 1, "head_commit_sha": "aaa"}`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/repository/compare", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("from")).To(Equal("aaa"))
+			Expect(r.URL.Query().Get("to")).To(Equal("bbb"))
+			fmt.Fprint(w, `{"diffs": [{"old_path": "main.go", "new_path": "main.go", "diff": "@@ -1 +1,2 @@\n+// fixed\n"}]}`)
+		})
+		stdout, _, err := executeCommand(RootCmd, "mr", "diff", "7", "--id", "42", "--from_version", "1", "--stat", "--color", "never")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(Equal(" main.go | 1 +\n 1 file changed, 1 insertion(+), 0 deletions(-)"))
+	})
+
+	It("validates the color flag", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "diff", "7", "--id", "42", "--color", "sometimes")
+		Expect(err).To(MatchError(`invalid value "sometimes" for flag --color, expected one of auto, always, never`))
+	})
+
+})
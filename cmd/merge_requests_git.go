@@ -0,0 +1,269 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/michaellihs/golab/cmd/mapper"
+	"github.com/xanzy/go-gitlab"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const mergeRequestTemplates = ".gitlab/merge_request_templates"
+
+// isInteractive returns true, if golab runs in a terminal, so that the editor can be opened
+var isInteractive = func() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// prepareMergeRequest sets the source branch of a merge request, pushes it with --push and - for the
+// branch checked out in the working directory or with --fill - fills in the target branch, title and
+// description that were not given as flags
+func prepareMergeRequest(flags *mergeRequestsCreateFlags, opts *gitlab.CreateMergeRequestOptions) error {
+	fromGit := (flags.Fill != nil && *flags.Fill) || flags.Template != nil
+	if flags.SourceBranch == nil {
+		branch, err := resolveBranch()
+		if err != nil {
+			return &mapper.FlagError{Flag: "source_branch", Err: fmt.Errorf("required flag --source_branch was empty and could not be resolved: %s", err)}
+		}
+		flags.SourceBranch, fromGit = &branch, true
+	}
+	git := GitHelper()
+	source := *flags.SourceBranch
+	opts.SourceBranch = gitlab.String(source)
+
+	if flags.Push != nil && *flags.Push {
+		if err := pushBranch(source); err != nil {
+			return err
+		}
+	}
+	if !fromGit {
+		if opts.TargetBranch == nil {
+			return &mapper.FlagError{Flag: "target_branch", Err: errors.New("required flag --target_branch was empty")}
+		}
+		if opts.Title == nil {
+			return &mapper.FlagError{Flag: "title", Err: errors.New("required flag --title was empty")}
+		}
+		return nil
+	}
+
+	if opts.TargetBranch == nil {
+		project, _, err := gitlabClient.Projects.GetProject(*flags.Id)
+		if err != nil {
+			return fmt.Errorf("could not get default branch of project: %s", err)
+		}
+		opts.TargetBranch = gitlab.String(project.DefaultBranch)
+	}
+
+	// the source branch might only exist on the remote
+	title, description := "", ""
+	if _, err := git.RevParse(source); err == nil {
+		if title, description, err = describeCommits(source, *opts.TargetBranch); err != nil {
+			return err
+		}
+	}
+	template, err := mergeRequestTemplate(flags.Template)
+	if err != nil {
+		return err
+	}
+	if template != "" {
+		description = template
+	}
+	if opts.Title == nil {
+		opts.Title = gitlab.String(title)
+	}
+	if opts.Description == nil {
+		opts.Description = gitlab.String(description)
+	}
+
+	if (flags.NoEdit == nil || !*flags.NoEdit) && (flags.Title == nil || flags.Description == nil) && isInteractive() {
+		edited, err := EditText(*opts.Title + "\n\n" + *opts.Description + "\n")
+		if err != nil {
+			return fmt.Errorf("could not edit merge request: %s", err)
+		}
+		opts.Title, opts.Description = splitMessage(edited)
+	}
+	if strings.TrimSpace(*opts.Title) == "" {
+		return errors.New("title of merge request is empty")
+	}
+	return nil
+}
+
+// pushBranch pushes the branch to the git remote, if the remote branch differs from the local one
+func pushBranch(branch string) error {
+	git := GitHelper()
+	local, err := git.RevParse(branch)
+	if err != nil {
+		return err
+	}
+	if remote, err := git.RevParse("refs/remotes/" + remoteName() + "/" + branch); err == nil && remote == local {
+		return nil
+	}
+	return git.Push(remoteName(), branch, os.Stderr)
+}
+
+// describeCommits returns a title and description for the commits of the source branch:
+// the message of a single commit or the list of the commit subjects for several commits
+func describeCommits(source string, target string) (string, string, error) {
+	git := GitHelper()
+	revisions := []string{"-1", source}
+	if _, err := git.RevParse(remoteName() + "/" + target); err == nil {
+		revisions = []string{remoteName() + "/" + target + ".." + source}
+	}
+	commits, err := git.Commits(revisions...)
+	if err != nil {
+		return "", "", err
+	}
+	switch len(commits) {
+	case 0:
+		return humanize(source), "", nil
+	case 1:
+		return commits[0].Subject, commits[0].Body, nil
+	}
+	var subjects []string
+	for _, commit := range commits {
+		subjects = append(subjects, "* "+commit.Subject)
+	}
+	return humanize(source), strings.Join(subjects, "\n"), nil
+}
+
+// humanize turns a branch name like feature/add-open-command into a title like Add open command
+func humanize(branch string) string {
+	title := strings.NewReplacer("-", " ", "_", " ").Replace(branch[strings.LastIndex(branch, "/")+1:])
+	if title == "" {
+		return branch
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// mergeRequestTemplate returns the content of the given template or the Default template, if it exists
+func mergeRequestTemplate(name *string) (string, error) {
+	root, err := GitHelper().TopLevel()
+	if err != nil {
+		if name != nil {
+			return "", errors.New("templates can only be used in a git repository")
+		}
+		return "", nil
+	}
+	templateName := "Default"
+	if name != nil {
+		templateName = *name
+	}
+	content, err := ioutil.ReadFile(filepath.Join(root, mergeRequestTemplates, templateName+".md"))
+	if err != nil {
+		if name == nil {
+			return "", nil
+		}
+		templates, _ := filepath.Glob(filepath.Join(root, mergeRequestTemplates, "*.md"))
+		for i, template := range templates {
+			templates[i] = strings.TrimSuffix(filepath.Base(template), ".md")
+		}
+		return "", fmt.Errorf("template %s not found in %s, available templates: %s", templateName, mergeRequestTemplates, strings.Join(templates, ", "))
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// splitMessage splits an edited message into the title (first line) and the description
+func splitMessage(message string) (*string, *string) {
+	parts := strings.SplitN(strings.TrimSpace(message), "\n", 2)
+	description := ""
+	if len(parts) > 1 {
+		description = strings.TrimSpace(parts[1])
+	}
+	return gitlab.String(strings.TrimSpace(parts[0])), gitlab.String(description)
+}
+
+// checkoutMergeRequest fetches the head of the merge request into a local branch and checks it out,
+// an existing branch is fast-forwarded if update is set
+func checkoutMergeRequest(mr *gitlab.MergeRequest, branch *string, update bool) (string, error) {
+	git := GitHelper()
+	head := fmt.Sprintf("refs/merge-requests/%d/head", mr.IID)
+
+	var source *gitlab.Project
+	if mr.SourceProjectID != mr.TargetProjectID {
+		var err error
+		if source, _, err = gitlabClient.Projects.GetProject(mr.SourceProjectID); err != nil {
+			return "", fmt.Errorf("could not get source project of merge request: %s", err)
+		}
+	}
+	localBranch := mr.SourceBranch
+	if branch != nil {
+		localBranch = *branch
+	} else if source != nil && source.Namespace != nil {
+		// the names of branches in forks collide easily, e.g. master
+		localBranch = source.Namespace.Path + "/" + mr.SourceBranch
+	}
+
+	if _, err := git.RevParse("refs/heads/" + localBranch); err == nil {
+		if update {
+			if current, _ := git.CurrentBranch(); current == localBranch {
+				if err := git.Fetch(remoteName(), head); err != nil {
+					return "", err
+				}
+				return localBranch, git.MergeFastForward("FETCH_HEAD")
+			}
+			// fetch refuses to update a branch that cannot be fast-forwarded
+			if err := git.Fetch(remoteName(), head+":refs/heads/"+localBranch); err != nil {
+				return "", err
+			}
+		}
+		return localBranch, git.Checkout(localBranch)
+	}
+
+	if err := git.Fetch(remoteName(), head+":refs/heads/"+localBranch); err != nil {
+		return "", err
+	}
+	upstream := remoteName()
+	if source != nil {
+		upstream = forkUrl(source)
+	}
+	if err := git.SetUpstream(localBranch, upstream, mr.SourceBranch); err != nil {
+		return "", err
+	}
+	return localBranch, git.Checkout(localBranch)
+}
+
+// forkUrl returns the ssh or http URL of the fork, depending on the URL of the git remote
+func forkUrl(fork *gitlab.Project) string {
+	if remoteUrl, err := GitHelper().RemoteUrl(remoteName()); err == nil && strings.HasPrefix(remoteUrl, "http") {
+		return fork.HTTPURLToRepo
+	}
+	return fork.SSHURLToRepo
+}
+
+// resolveBranch returns the branch checked out in the working directory
+func resolveBranch() (string, error) {
+	branch, err := GitHelper().CurrentBranch()
+	if err != nil {
+		return "", errors.New("working directory is not a git repository")
+	}
+	if branch == "HEAD" {
+		return "", errors.New("no branch is checked out")
+	}
+	return branch, nil
+}
@@ -0,0 +1,292 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("merge-requests create in a git repository", func() {
+
+	var (
+		mux                   *http.ServeMux
+		server                *httptest.Server
+		repo, remote, prevDir string
+		created               map[string]string
+		prevInteractive       = isInteractive
+	)
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-c", "user.name=golab", "-c", "user.email=golab@my-domain.com"}, args...)...).CombinedOutput()
+		Expect(err).To(BeNil(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(mergeRequestsCreateCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		created = nil
+		isInteractive = func() bool { return false }
+		mux.HandleFunc("/api/v4/projects/42", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id": 42, "default_branch": "main"}`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+			testMethod(r, "POST")
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"id": 1, "iid": 1}`)
+		})
+
+		prevDir, _ = os.Getwd()
+		remote, _ = ioutil.TempDir("", "golab-remote")
+		repo, _ = ioutil.TempDir("", "golab-repo")
+		git("init", "-q", "--bare", remote)
+		os.Chdir(repo)
+		git("init", "-q")
+		git("checkout", "-q", "-b", "main")
+		git("commit", "-q", "--allow-empty", "-m", "initial commit")
+		git("remote", "add", "origin", remote)
+		git("push", "-q", "origin", "main")
+		git("checkout", "-q", "-b", "feature/add-open-command")
+		git("commit", "-q", "--allow-empty", "-m", "Add open command", "-m", "Opens the project in the browser")
+	})
+
+	AfterEach(func() {
+		os.Chdir(prevDir)
+		os.RemoveAll(repo)
+		os.RemoveAll(remote)
+		server.Close()
+		isInteractive = prevInteractive
+	})
+
+	pushed := func() bool {
+		_, err := exec.Command("git", "--git-dir", remote, "rev-parse", "--verify", "feature/add-open-command").Output()
+		return err == nil
+	}
+
+	It("creates a merge request from the commit of the current branch and pushes the branch with --push", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "--no_edit", "--push")
+		Expect(err).To(BeNil())
+		Expect(created).To(Equal(map[string]string{
+			"source_branch": "feature/add-open-command",
+			"target_branch": "main",
+			"title":         "Add open command",
+			"description":   "Opens the project in the browser",
+		}))
+		Expect(git("--git-dir", remote, "rev-parse", "feature/add-open-command")).To(Equal(git("rev-parse", "HEAD")))
+	})
+
+	It("lists the commits and uses the Default template", func() {
+		git("commit", "-q", "--allow-empty", "-m", "Add tests")
+		os.MkdirAll(mergeRequestTemplates, 0755)
+		ioutil.WriteFile(filepath.Join(mergeRequestTemplates, "Default.md"), []byte("## What does this MR do?\n"), 0644)
+
+		title, description, err := describeCommits("feature/add-open-command", "main")
+		Expect(err).To(BeNil())
+		Expect(title).To(Equal("Add open command"))
+		Expect(description).To(Equal("* Add open command\n* Add tests"))
+
+		_, _, err = executeCommand(RootCmd, "mr", "create", "--id", "42", "--no_edit")
+		Expect(err).To(BeNil())
+		Expect(created["title"]).To(Equal("Add open command"))
+		Expect(created["description"]).To(Equal("## What does this MR do?"))
+		Expect(pushed()).To(BeFalse())
+	})
+
+	It("opens the editor for title and description in a terminal", func() {
+		editor := filepath.Join(repo, "editor.sh")
+		ioutil.WriteFile(editor, []byte("#!/bin/sh\nprintf 'Edited title\\n\\nEdited description\\n' > \"$1\"\n"), 0755)
+		os.Setenv("VISUAL", editor)
+		defer os.Unsetenv("VISUAL")
+		isInteractive = func() bool { return true }
+
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "--target_branch", "develop")
+		Expect(err).To(BeNil())
+		Expect(created["title"]).To(Equal("Edited title"))
+		Expect(created["description"]).To(Equal("Edited description"))
+		Expect(created["target_branch"]).To(Equal("develop"))
+	})
+
+	It("does not open the editor outside of a terminal", func() {
+		os.Setenv("VISUAL", "false")
+		defer os.Unsetenv("VISUAL")
+
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42")
+		Expect(err).To(BeNil())
+		Expect(created["title"]).To(Equal("Add open command"))
+	})
+
+	It("uses only the flags for a given source branch", func() {
+		os.Setenv("VISUAL", "false")
+		defer os.Unsetenv("VISUAL")
+		isInteractive = func() bool { return true }
+
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "-s", "feature/add-open-command", "-t", "develop", "-n", "My title")
+		Expect(err).To(BeNil())
+		Expect(created).To(Equal(map[string]string{
+			"source_branch": "feature/add-open-command",
+			"target_branch": "develop",
+			"title":         "My title",
+		}))
+		Expect(pushed()).To(BeFalse())
+	})
+
+	It("takes title and description from git for a given source branch with --fill", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "-s", "feature/add-open-command", "--fill")
+		Expect(err).To(BeNil())
+		Expect(created["target_branch"]).To(Equal("main"))
+		Expect(created["title"]).To(Equal("Add open command"))
+	})
+
+	It("requires target branch and title for a given source branch", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "-s", "feature/add-open-command", "-t", "main")
+		Expect(err).To(MatchError("required flag --title was empty"))
+		Expect(created).To(BeNil())
+	})
+
+	It("returns an error for unknown templates", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "create", "--id", "42", "--template", "Bug", "--no_edit")
+		Expect(err).To(MatchError("template Bug not found in .gitlab/merge_request_templates, available templates: "))
+	})
+
+})
+
+var _ = Describe("merge-requests checkout", func() {
+
+	var (
+		mux                           *http.ServeMux
+		server                        *httptest.Server
+		author, repo, remote, prevDir string
+	)
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-c", "user.name=golab", "-c", "user.email=golab@my-domain.com"}, args...)...).CombinedOutput()
+		Expect(err).To(BeNil(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	// pushCommit adds a commit to the merge request with iid 7 in the remote
+	pushCommit := func(message string) string {
+		git("-C", author, "commit", "-q", "--allow-empty", "-m", message)
+		git("-C", author, "push", "-q", "--force", remote, "HEAD:refs/merge-requests/7/head", "HEAD:refs/heads/feature")
+		return git("-C", author, "rev-parse", "HEAD")
+	}
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(mergeRequestsCheckoutCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		prevDir, _ = os.Getwd()
+		remote, _ = ioutil.TempDir("", "golab-remote")
+		author, _ = ioutil.TempDir("", "golab-author")
+		repo, _ = ioutil.TempDir("", "golab-repo")
+		git("init", "-q", "--bare", remote)
+		git("init", "-q", author)
+		git("-C", author, "commit", "-q", "--allow-empty", "-m", "initial commit")
+		git("-C", author, "push", "-q", remote, "HEAD:refs/heads/main")
+		os.Chdir(repo)
+		git("init", "-q")
+		git("remote", "add", "origin", remote)
+	})
+
+	AfterEach(func() {
+		os.Chdir(prevDir)
+		os.RemoveAll(repo)
+		os.RemoveAll(author)
+		os.RemoveAll(remote)
+		server.Close()
+	})
+
+	It("checks out the head of the merge request and updates it", func() {
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"iid": 7, "source_branch": "feature", "source_project_id": 42, "target_project_id": 42}`)
+		})
+		head := pushCommit("Add feature")
+
+		stdout, _, err := executeCommand(RootCmd, "mr", "checkout", "7", "--id", "42")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(Equal("Switched to branch feature for merge request !7"))
+		Expect(git("rev-parse", "--abbrev-ref", "HEAD")).To(Equal("feature"))
+		Expect(git("rev-parse", "HEAD")).To(Equal(head))
+		Expect(git("config", "branch.feature.remote")).To(Equal("origin"))
+		Expect(git("config", "branch.feature.merge")).To(Equal("refs/heads/feature"))
+
+		head = pushCommit("Fix feature")
+		resetFlags(mergeRequestsCheckoutCmd)
+		_, _, err = executeCommand(RootCmd, "mr", "checkout", "7", "--id", "42", "--update")
+		Expect(err).To(BeNil())
+		Expect(git("rev-parse", "HEAD")).To(Equal(head))
+	})
+
+	It("sets the fork as upstream for merge requests from forks", func() {
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"iid": 7, "source_branch": "feature", "source_project_id": 43, "target_project_id": 42}`)
+		})
+		mux.HandleFunc("/api/v4/projects/43", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id": 43, "ssh_url_to_repo": "git@gitlab.my-domain.com:jdoe/my-project.git", "namespace": {"path": "jdoe"}}`)
+		})
+		pushCommit("Add feature")
+
+		_, _, err := executeCommand(RootCmd, "mr", "checkout", "7", "--id", "42")
+		Expect(err).To(BeNil())
+		Expect(git("rev-parse", "--abbrev-ref", "HEAD")).To(Equal("jdoe/feature"))
+		Expect(git("config", "branch.jdoe/feature.remote")).To(Equal("git@gitlab.my-domain.com:jdoe/my-project.git"))
+		Expect(git("config", "branch.jdoe/feature.merge")).To(Equal("refs/heads/feature"))
+	})
+
+	It("uses the http URL of forks for http remotes", func() {
+		fork := &gitlab.Project{HTTPURLToRepo: "https://gitlab.my-domain.com/jdoe/my-project.git", SSHURLToRepo: "git@gitlab.my-domain.com:jdoe/my-project.git"}
+		Expect(forkUrl(fork)).To(Equal(fork.SSHURLToRepo))
+
+		git("remote", "set-url", "origin", "https://gitlab.my-domain.com/my-group/my-project.git")
+		Expect(forkUrl(fork)).To(Equal(fork.HTTPURLToRepo))
+
+		git("config", "url.https://gitlab.my-domain.com/.insteadOf", "gl:")
+		git("remote", "set-url", "origin", "gl:my-group/my-project.git")
+		Expect(forkUrl(fork)).To(Equal(fork.HTTPURLToRepo))
+	})
+
+	It("returns an error for invalid iids", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "checkout", "abc", "--id", "42")
+		Expect(err).To(MatchError("merge request iid must be a number: abc"))
+	})
+
+})
@@ -0,0 +1,460 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
+	"gopkg.in/yaml.v2"
+)
+
+// discussion is a thread of notes on a merge request, go-gitlab has no support for the discussions API
+type discussion struct {
+	ID             string            `json:"id"`
+	IndividualNote bool              `json:"individual_note"`
+	Notes          []*discussionNote `json:"notes"`
+}
+
+type discussionNote struct {
+	ID     int    `json:"id"`
+	Type   string `json:"type"`
+	Body   string `json:"body"`
+	Author struct {
+		ID       int    `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"author"`
+	CreatedAt  *time.Time    `json:"created_at"`
+	System     bool          `json:"system"`
+	Resolvable bool          `json:"resolvable"`
+	Resolved   bool          `json:"resolved"`
+	Position   *diffPosition `json:"position,omitempty"`
+}
+
+// diffPosition is the position of a comment in a diff version of a merge request
+type diffPosition struct {
+	BaseSha      string `json:"base_sha"`
+	StartSha     string `json:"start_sha"`
+	HeadSha      string `json:"head_sha"`
+	PositionType string `json:"position_type"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	OldLine      *int   `json:"old_line"`
+	NewLine      *int   `json:"new_line"`
+}
+
+type createDiscussionOptions struct {
+	Body     string        `url:"body" json:"body"`
+	Position *diffPosition `url:"-" json:"position,omitempty"`
+}
+
+type resolveDiscussionOptions struct {
+	Resolved bool `url:"resolved" json:"resolved"`
+}
+
+// reviewFile is the content of a review file, see mergeRequestsReviewSubmitCmd
+type reviewFile struct {
+	VersionId *int            `yaml:"version_id"`
+	Comments  []reviewComment `yaml:"comments"`
+}
+
+// reviewComment is a comment on a line of a file or, without a file, on the merge request as a whole
+type reviewComment struct {
+	File    string `yaml:"file"`
+	Line    int    `yaml:"line"`
+	OldLine int    `yaml:"old_line"`
+	Body    string `yaml:"body"`
+}
+
+// see https://docs.gitlab.com/ce/api/discussions.html#merge-requests
+var mergeRequestsReviewCmd = &golabCommand{
+	Parent: mergeRequestsCmd,
+	Cmd: &cobra.Command{
+		Use:   "review",
+		Short: "Review merge requests",
+		Long: `Review merge requests with comments on lines of the diff, reply to and resolve discussions.
+
+The line numbers of comments refer to a diff version of the merge request (see get-diff-versions), by default the latest one.
+Gitlab only accepts comments on lines that are part of the diff, i.e. changed lines and the lines around them.`,
+	},
+	Run: func(cmd golabCommand) error {
+		return errors.New("this command cannot be run without a sub-command")
+	},
+}
+
+type mergeRequestsReviewListFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	Unresolved      *bool   `flag_name:"unresolved" short:"u" type:"bool" required:"no" description:"Only list discussions that are not resolved yet (default: false)"`
+}
+
+var mergeRequestsReviewListCmd = &golabCommand{
+	Parent: mergeRequestsReviewCmd.Cmd,
+	Flags:  &mergeRequestsReviewListFlags{},
+	Cmd: &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list", "discussions"},
+		Short:   "List discussions",
+		Long:    `List the discussions of a merge request including their notes.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsReviewListFlags)
+		discussions, err := listDiscussions(*flags.Id, *flags.MergeRequestIid)
+		if err != nil {
+			return err
+		}
+		if flags.Unresolved != nil && *flags.Unresolved {
+			unresolved := []*discussion{}
+			for _, d := range discussions {
+				if !d.resolved() {
+					unresolved = append(unresolved, d)
+				}
+			}
+			discussions = unresolved
+		}
+		return OutputJson(discussions)
+	},
+}
+
+type mergeRequestsReviewCommentFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	Body            *string `flag_name:"body" short:"b" type:"string" required:"yes" description:"The text of the comment"`
+	File            *string `flag_name:"file" short:"f" type:"string" required:"no" description:"Path of the file to comment on, without a file the comment starts a discussion of the whole merge request"`
+	Line            *int    `flag_name:"line" short:"l" type:"integer" required:"no" description:"Number of the line in the new version of the file"`
+	OldLine         *int    `flag_name:"old_line" type:"integer" required:"no" description:"Number of the line in the old version of the file, for comments on removed lines"`
+	VersionId       *int    `flag_name:"version_id" short:"v" type:"integer" required:"no" description:"The ID of the diff version the line numbers refer to, default is the latest version"`
+}
+
+var mergeRequestsReviewCommentCmd = &golabCommand{
+	Parent: mergeRequestsReviewCmd.Cmd,
+	Flags:  &mergeRequestsReviewCommentFlags{},
+	Cmd: &cobra.Command{
+		Use:   "comment",
+		Short: "Comment on a line of the diff",
+		Long: `Start a discussion on a line of a file in the diff of a merge request, e.g.
+
+    golab mr review comment -m 7 --file cmd/root.go --line 42 --body "Please handle the error"
+
+Use --old_line to comment on a removed line.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsReviewCommentFlags)
+		comment := reviewComment{Body: *flags.Body}
+		if flags.File != nil {
+			comment.File = *flags.File
+		}
+		if flags.Line != nil {
+			comment.Line = *flags.Line
+		}
+		if flags.OldLine != nil {
+			comment.OldLine = *flags.OldLine
+		}
+		opts, err := reviewOptions(*flags.Id, *flags.MergeRequestIid, flags.VersionId, []reviewComment{comment})
+		if err != nil {
+			return err
+		}
+		created := &discussion{}
+		if err := discussionRequest("POST", discussionsPath(*flags.Id, *flags.MergeRequestIid), opts[0], created); err != nil {
+			return err
+		}
+		return OutputJson(created)
+	},
+}
+
+type mergeRequestsReviewSubmitFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	VersionId       *int    `flag_name:"version_id" short:"v" type:"integer" required:"no" description:"The ID of the diff version the line numbers refer to, default is the version_id of the review file or the latest version"`
+	DryRun          *bool   `flag_name:"dry_run" type:"bool" required:"no" description:"Only validate the comments and print the discussions that would be started (default: false)"`
+}
+
+var mergeRequestsReviewSubmitCmd = &golabCommand{
+	Parent: mergeRequestsReviewCmd.Cmd,
+	Flags:  &mergeRequestsReviewSubmitFlags{},
+	Cmd: &cobra.Command{
+		Use:   "submit <review file>",
+		Short: "Submit the comments of a review file",
+		Long: `Submit a batch of comments from a YAML or JSON review file (use - to read from stdin), e.g.
+
+    version_id: 123 # optional, default is the latest diff version
+    comments:
+      - file: cmd/root.go
+        line: 42
+        body: Please handle the error
+      - file: README.md
+        old_line: 7
+        body: Why was this removed?
+      - body: Thanks, looks good apart from that!
+
+All comments are validated before the first one is submitted, comments without a file start a discussion of the whole merge request.`,
+		Args: cobra.ExactArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsReviewSubmitFlags)
+		review, err := readReviewFile(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+		versionId := review.VersionId
+		if flags.VersionId != nil {
+			versionId = flags.VersionId
+		}
+		opts, err := reviewOptions(*flags.Id, *flags.MergeRequestIid, versionId, review.Comments)
+		if err != nil {
+			return err
+		}
+		if flags.DryRun != nil && *flags.DryRun {
+			return OutputJson(opts)
+		}
+		discussions := []*discussion{}
+		for i, opt := range opts {
+			created := &discussion{}
+			if err := discussionRequest("POST", discussionsPath(*flags.Id, *flags.MergeRequestIid), opt, created); err != nil {
+				return fmt.Errorf("submitting comment %d failed, %d of %d comments were submitted: %s", i+1, i, len(opts), err)
+			}
+			discussions = append(discussions, created)
+		}
+		return OutputJson(discussions)
+	},
+}
+
+type mergeRequestsReviewReplyFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	DiscussionId    *string `flag_name:"discussion_id" short:"d" type:"string" required:"yes" description:"The ID of the discussion"`
+	Body            *string `flag_name:"body" short:"b" type:"string" required:"yes" description:"The text of the reply"`
+}
+
+var mergeRequestsReviewReplyCmd = &golabCommand{
+	Parent: mergeRequestsReviewCmd.Cmd,
+	Flags:  &mergeRequestsReviewReplyFlags{},
+	Cmd: &cobra.Command{
+		Use:   "reply",
+		Short: "Reply to a discussion",
+		Long:  `Add a note to a discussion of a merge request.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsReviewReplyFlags)
+		note := &discussionNote{}
+		path := discussionsPath(*flags.Id, *flags.MergeRequestIid) + "/" + url.QueryEscape(*flags.DiscussionId) + "/notes"
+		if err := discussionRequest("POST", path, &createDiscussionOptions{Body: *flags.Body}, note); err != nil {
+			return err
+		}
+		return OutputJson(note)
+	},
+}
+
+type mergeRequestsReviewResolveFlags struct {
+	Id              *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL encoded path of a project"`
+	MergeRequestIid *int    `flag_name:"iid" short:"m" type:"integer" required:"yes" description:"The internal ID of the merge request"`
+	DiscussionId    *string `flag_name:"discussion_id" short:"d" type:"string" required:"yes" description:"The ID of the discussion"`
+	Unresolve       *bool   `flag_name:"unresolve" type:"bool" required:"no" description:"Reopen a resolved discussion instead (default: false)"`
+}
+
+var mergeRequestsReviewResolveCmd = &golabCommand{
+	Parent: mergeRequestsReviewCmd.Cmd,
+	Flags:  &mergeRequestsReviewResolveFlags{},
+	Cmd: &cobra.Command{
+		Use:   "resolve",
+		Short: "Resolve a discussion",
+		Long:  `Resolve or, with --unresolve, reopen a discussion of a merge request.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*mergeRequestsReviewResolveFlags)
+		resolved := flags.Unresolve == nil || !*flags.Unresolve
+		updated := &discussion{}
+		path := discussionsPath(*flags.Id, *flags.MergeRequestIid) + "/" + url.QueryEscape(*flags.DiscussionId)
+		if err := discussionRequest("PUT", path, &resolveDiscussionOptions{Resolved: resolved}, updated); err != nil {
+			return err
+		}
+		return OutputJson(updated)
+	},
+}
+
+func discussionsPath(pid string, iid int) string {
+	return fmt.Sprintf("projects/%s/merge_requests/%d/discussions", url.QueryEscape(pid), iid)
+}
+
+// discussionRequest sends a request to the discussions API and decodes the response into v
+func discussionRequest(method string, path string, opt interface{}, v interface{}) error {
+	req, err := gitlabClient.NewRequest(method, path, opt, nil)
+	if err != nil {
+		return err
+	}
+	_, err = gitlabClient.Do(req, v)
+	return err
+}
+
+// listDiscussions returns all discussions of the merge request, going through all pages
+func listDiscussions(pid string, iid int) ([]*discussion, error) {
+	discussions := []*discussion{}
+	for page := 1; page > 0; {
+		req, err := gitlabClient.NewRequest("GET", discussionsPath(pid, iid), nil, []gitlab.OptionFunc{perPage(100), queryParam("page", strconv.Itoa(page))})
+		if err != nil {
+			return nil, err
+		}
+		var result []*discussion
+		resp, err := gitlabClient.Do(req, &result)
+		if err != nil {
+			return nil, err
+		}
+		discussions = append(discussions, result...)
+		page = resp.NextPage
+	}
+	return discussions, nil
+}
+
+// resolved returns true, if all resolvable notes of the discussion are resolved
+func (d *discussion) resolved() bool {
+	for _, note := range d.Notes {
+		if note.Resolvable && !note.Resolved {
+			return false
+		}
+	}
+	return true
+}
+
+func readReviewFile(file string) (*reviewFile, error) {
+	var content []byte
+	var err error
+	if file == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read review file: %s", err)
+	}
+	review := &reviewFile{}
+	if err := yaml.Unmarshal(content, review); err != nil {
+		return nil, fmt.Errorf("review file %s is neither YAML nor JSON: %s", file, err)
+	}
+	if len(review.Comments) == 0 {
+		return nil, fmt.Errorf("review file %s has no comments", file)
+	}
+	return review, nil
+}
+
+// reviewOptions validates the comments and returns the options to start their discussions,
+// the diff version is only loaded, if a comment refers to a line
+func reviewOptions(pid string, iid int, versionId *int, comments []reviewComment) ([]*createDiscussionOptions, error) {
+	var version *gitlab.MergeRequestDiffVersion
+	var result []*createDiscussionOptions
+	for i, comment := range comments {
+		opts := &createDiscussionOptions{Body: comment.Body}
+		if comment.File != "" && version == nil {
+			v, err := diffVersion(pid, iid, versionId)
+			if err != nil {
+				return nil, err
+			}
+			version = v
+		}
+		position, err := comment.position(version)
+		if err != nil {
+			if len(comments) > 1 {
+				return nil, fmt.Errorf("comment %d: %s", i+1, err)
+			}
+			return nil, err
+		}
+		opts.Position = position
+		result = append(result, opts)
+	}
+	return result, nil
+}
+
+// diffVersion returns the diff version with the given ID or the latest version including the diffs
+func diffVersion(pid string, iid int, versionId *int) (*gitlab.MergeRequestDiffVersion, error) {
+	if versionId == nil {
+		versions, _, err := gitlabClient.MergeRequests.GetMergeRequestDiffVersions(pid, iid)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, errors.New("merge request has no diff versions")
+		}
+		// the latest version comes first, the list doesn't contain the diffs
+		versionId = &versions[0].ID
+	}
+	version, _, err := gitlabClient.MergeRequests.GetSingleMergeRequestDiffVersion(pid, iid, *versionId)
+	return version, err
+}
+
+// position returns the position of the comment in the diff version, nil for comments without a file
+func (c reviewComment) position(version *gitlab.MergeRequestDiffVersion) (*diffPosition, error) {
+	if strings.TrimSpace(c.Body) == "" {
+		return nil, errors.New("the comment has no body")
+	}
+	if c.File == "" {
+		if c.Line != 0 || c.OldLine != 0 {
+			return nil, errors.New("a comment on a line needs a file")
+		}
+		return nil, nil
+	}
+	if c.Line <= 0 && c.OldLine <= 0 {
+		return nil, fmt.Errorf("the comment on %s needs a line or an old_line", c.File)
+	}
+	for _, diff := range version.Diffs {
+		if diff.NewPath != c.File && diff.OldPath != c.File {
+			continue
+		}
+		line, found := FindDiffLine(diff.Diff, c.OldLine, c.Line)
+		if !found {
+			return nil, fmt.Errorf("%s of %s is not part of the diff of version %d", c.describeLine(), c.File, version.ID)
+		}
+		position := &diffPosition{BaseSha: version.BaseCommitSHA, StartSha: version.StartCommitSHA, HeadSha: version.HeadCommitSHA,
+			PositionType: "text", OldPath: diff.OldPath, NewPath: diff.NewPath}
+		if line.Old > 0 {
+			position.OldLine = gitlab.Int(line.Old)
+		}
+		if line.New > 0 {
+			position.NewLine = gitlab.Int(line.New)
+		}
+		return position, nil
+	}
+	return nil, fmt.Errorf("%s is not changed in version %d of the merge request", c.File, version.ID)
+}
+
+func (c reviewComment) describeLine() string {
+	if c.Line > 0 {
+		return fmt.Sprintf("line %d", c.Line)
+	}
+	return fmt.Sprintf("old line %d", c.OldLine)
+}
+
+func init() {
+	mergeRequestsReviewCmd.Init()
+	mergeRequestsReviewListCmd.Init()
+	mergeRequestsReviewCommentCmd.Init()
+	mergeRequestsReviewSubmitCmd.Init()
+	mergeRequestsReviewReplyCmd.Init()
+	mergeRequestsReviewResolveCmd.Init()
+}
@@ -0,0 +1,227 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("merge-requests review", func() {
+
+	var (
+		mux     *http.ServeMux
+		server  *httptest.Server
+		posted  []map[string]interface{}
+		tempDir string
+	)
+
+	version := `{"id": 3, "head_commit_sha": "head", "base_commit_sha": "base", "start_commit_sha": "start", "diffs": [
+		{"old_path": "README.md", "new_path": "README.md", "diff": "@@ -1,3 +1,3 @@\n golab\n-old line\n+new line\n unchanged\n"},
+		{"old_path": "old.go", "new_path": "new.go", "renamed_file": true, "diff": "@@ -1 +1,2 @@\n package cmd\n+// renamed\n"}]}`
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		for _, cmd := range []*golabCommand{mergeRequestsReviewListCmd, mergeRequestsReviewCommentCmd, mergeRequestsReviewSubmitCmd,
+			mergeRequestsReviewReplyCmd, mergeRequestsReviewResolveCmd} {
+			resetFlags(cmd)
+		}
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+		posted = nil
+		tempDir, _ = ioutil.TempDir("", "golab-review")
+
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/versions", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"id": 3, "head_commit_sha": "head"}, {"id": 2, "head_commit_sha": "previous"}]`)
+		})
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/versions/3", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, version)
+		})
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/discussions", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				fmt.Fprint(w, `[{"id": "abc", "notes": [{"id": 1, "body": "fix this", "resolvable": true, "resolved": false}]},
+					{"id": "def", "notes": [{"id": 2, "body": "done", "resolvable": true, "resolved": true}]},
+					{"id": "ghi", "individual_note": true, "notes": [{"id": 3, "body": "LGTM"}]}]`)
+				return
+			}
+			body := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(BeNil())
+			posted = append(posted, body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id": "d%d", "notes": [{"id": %d, "body": %q}]}`, len(posted), len(posted), body["body"])
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	It("comments on an added line of the latest diff version", func() {
+		stdout, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--file", "README.md", "--line", "2", "--body", "Why?")
+
+		Expect(err).To(BeNil())
+		Expect(posted).To(HaveLen(1))
+		Expect(posted[0]["body"]).To(Equal("Why?"))
+		Expect(posted[0]["position"]).To(Equal(map[string]interface{}{"base_sha": "base", "start_sha": "start", "head_sha": "head",
+			"position_type": "text", "old_path": "README.md", "new_path": "README.md", "old_line": nil, "new_line": float64(2)}))
+		Expect(stdout).To(ContainSubstring(`"id": "d1"`))
+	})
+
+	It("sets both line numbers for comments on unchanged lines", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--file", "README.md", "--line", "3", "--body", "ok")
+
+		Expect(err).To(BeNil())
+		position := posted[0]["position"].(map[string]interface{})
+		Expect(position["old_line"]).To(Equal(float64(3)))
+		Expect(position["new_line"]).To(Equal(float64(3)))
+	})
+
+	It("comments on a removed line with --old_line", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--file", "README.md", "--old_line", "2", "--body", "removed?")
+
+		Expect(err).To(BeNil())
+		position := posted[0]["position"].(map[string]interface{})
+		Expect(position["old_line"]).To(Equal(float64(2)))
+		Expect(position["new_line"]).To(BeNil())
+	})
+
+	It("starts a discussion of the whole merge request without a file", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--body", "Nice work")
+
+		Expect(err).To(BeNil())
+		Expect(posted).To(Equal([]map[string]interface{}{{"body": "Nice work"}}))
+	})
+
+	It("rejects comments on lines that are not part of the diff", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--file", "README.md", "--line", "10", "--body", "?")
+
+		Expect(err).To(MatchError("line 10 of README.md is not part of the diff of version 3"))
+		Expect(posted).To(BeEmpty())
+	})
+
+	It("rejects comments on files that are not changed", func() {
+		_, _, err := executeCommand(RootCmd, "mr", "review", "comment", "--id", "42", "-m", "7", "--file", "main.go", "--line", "1", "--body", "?")
+
+		Expect(err).To(MatchError("main.go is not changed in version 3 of the merge request"))
+	})
+
+	It("submits the comments of a review file", func() {
+		review := filepath.Join(tempDir, "review.yml")
+		ioutil.WriteFile(review, []byte(`version_id: 3
+comments:
+  - file: new.go
+    line: 2
+    body: Nice comment
+  - file: README.md
+    old_line: 2
+    body: Why was this removed?
+  - body: Looks good apart from that
+`), 0600)
+
+		stdout, _, err := executeCommand(RootCmd, "mr", "review", "submit", review, "--id", "42", "-m", "7")
+
+		Expect(err).To(BeNil())
+		Expect(posted).To(HaveLen(3))
+		Expect(posted[0]["position"]).To(HaveKeyWithValue("old_path", "old.go"))
+		Expect(posted[0]["position"]).To(HaveKeyWithValue("new_line", float64(2)))
+		Expect(posted[1]["position"]).To(HaveKeyWithValue("old_line", float64(2)))
+		Expect(posted[2]).NotTo(HaveKey("position"))
+		discussions := []*discussion{}
+		Expect(json.Unmarshal([]byte(stdout), &discussions)).To(BeNil())
+		Expect(discussions).To(HaveLen(3))
+	})
+
+	It("validates all comments of a review file before submitting any", func() {
+		review := filepath.Join(tempDir, "review.json")
+		ioutil.WriteFile(review, []byte(`{"comments": [{"file": "README.md", "line": 2, "body": "ok"}, {"file": "README.md", "body": "no line"}]}`), 0600)
+
+		_, _, err := executeCommand(RootCmd, "mr", "review", "submit", review, "--id", "42", "-m", "7")
+
+		Expect(err).To(MatchError("comment 2: the comment on README.md needs a line or an old_line"))
+		Expect(posted).To(BeEmpty())
+	})
+
+	It("prints the discussions of a review file with --dry_run", func() {
+		review := filepath.Join(tempDir, "review.yml")
+		ioutil.WriteFile(review, []byte("comments:\n  - file: README.md\n    line: 2\n    body: ok\n"), 0600)
+
+		stdout, _, err := executeCommand(RootCmd, "mr", "review", "submit", review, "--id", "42", "-m", "7", "--dry_run")
+
+		Expect(err).To(BeNil())
+		Expect(posted).To(BeEmpty())
+		Expect(stdout).To(ContainSubstring(`"head_sha": "head"`))
+	})
+
+	It("lists the unresolved discussions", func() {
+		stdout, _, err := executeCommand(RootCmd, "mr", "review", "ls", "--id", "42", "-m", "7", "--unresolved")
+
+		Expect(err).To(BeNil())
+		discussions := []*discussion{}
+		Expect(json.Unmarshal([]byte(stdout), &discussions)).To(BeNil())
+		Expect(discussions).To(HaveLen(1))
+		Expect(discussions[0].ID).To(Equal("abc"))
+	})
+
+	It("replies to a discussion", func() {
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/discussions/abc/notes", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal("POST"))
+			body, _ := ioutil.ReadAll(r.Body)
+			Expect(string(body)).To(Equal(`{"body":"fixed"}`))
+			fmt.Fprint(w, `{"id": 4, "body": "fixed"}`)
+		})
+
+		stdout, _, err := executeCommand(RootCmd, "mr", "review", "reply", "--id", "42", "-m", "7", "-d", "abc", "--body", "fixed")
+
+		Expect(err).To(BeNil())
+		Expect(stdout).To(ContainSubstring(`"body": "fixed"`))
+	})
+
+	It("resolves and reopens discussions", func() {
+		var bodies []string
+		mux.HandleFunc("/api/v4/projects/42/merge_requests/7/discussions/abc", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal("PUT"))
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			fmt.Fprint(w, `{"id": "abc"}`)
+		})
+
+		_, _, err := executeCommand(RootCmd, "mr", "review", "resolve", "--id", "42", "-m", "7", "-d", "abc")
+		Expect(err).To(BeNil())
+		resetFlags(mergeRequestsReviewResolveCmd)
+		_, _, err = executeCommand(RootCmd, "mr", "review", "resolve", "--id", "42", "-m", "7", "-d", "abc", "--unresolve")
+		Expect(err).To(BeNil())
+
+		Expect(bodies).To(Equal([]string{`{"resolved":true}`, `{"resolved":false}`}))
+	})
+
+})
@@ -0,0 +1,51 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/michaellihs/golab/cmd/cassette"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("namespaces command", func() {
+
+	var recorder *cassette.Recorder
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(namespacesSearchCmd)
+		recorder = useCassette("namespaces-search")
+	})
+
+	AfterEach(func() {
+		Expect(recorder.Stop()).To(BeNil())
+	})
+
+	It("searches namespaces", func() {
+		stdout, _, err := executeCommand(RootCmd, "namespaces", "search", "-s", "golab")
+
+		Expect(err).To(BeNil())
+		Expect(stdout).To(ContainSubstring(`"full_path": "golab-group"`))
+		Expect(stdout).To(ContainSubstring(`"kind": "user"`))
+		Expect(recorder.Unplayed()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,142 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultRedirectPort is used for the loopback listener, if no port is configured.
+// Gitlab compares redirect URIs including the port, so the port has to be fixed.
+const DefaultRedirectPort = 7171
+
+// codeTimeout is the time the user has to authorize golab in the browser
+var codeTimeout = 5 * time.Minute
+
+type codeResult struct {
+	code string
+	err  error
+}
+
+// RedirectURI returns the URI that has to be registered as callback URL for
+// the Gitlab application when using the authorization code flow.
+func (c Config) RedirectURI() string {
+	port := c.RedirectPort
+	if port == 0 {
+		port = DefaultRedirectPort
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+}
+
+// CodeToken requests a token with the authorization code flow using PKCE.
+// The authorization URL is handed to open, which should show it to the
+// user, e.g. in a browser. The redirect is received by a listener on the
+// loopback interface.
+func (c Config) CodeToken(open func(authorizeURL string) error) (*Token, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	redirectURI := c.RedirectURI()
+	redirect, _ := url.Parse(redirectURI)
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, errors.New("could not start listener for OAuth redirect: " + err.Error())
+	}
+
+	// only the first redirect with a valid state ends the login, reloads
+	// of the callback page must not block the handler
+	result := make(chan codeResult, 1)
+	deliver := func(res codeResult) {
+		select {
+		case result <- res:
+		default:
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			// anyone can send requests to the listener, so they must not abort the login
+			http.Error(w, "Invalid state - please restart the login", http.StatusBadRequest)
+		case query.Get("error") != "":
+			http.Error(w, "Login failed: "+query.Get("error_description"), http.StatusBadRequest)
+			deliver(codeResult{err: &Error{Code: query.Get("error"), Description: query.Get("error_description")}})
+		default:
+			fmt.Fprintln(w, "golab is now authorized - you can close this window.")
+			deliver(codeResult{code: query.Get("code")})
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authorizeURL := c.endpoint("/oauth/authorize") + "?" + url.Values{
+		"client_id":             {c.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"state":                 {state},
+		"scope":                 {c.scope()},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+	if err = open(authorizeURL); err != nil {
+		return nil, err
+	}
+
+	var res codeResult
+	select {
+	case res = <-result:
+	case <-time.After(codeTimeout):
+		return nil, errors.New("timed out waiting for OAuth authorization")
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return c.requestToken(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+func randomString(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
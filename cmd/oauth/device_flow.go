@@ -0,0 +1,79 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package oauth
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+type deviceResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceToken requests a token with the device authorization flow (Gitlab >= 17.2).
+// The user code and verification URI are handed to prompt, which has to show
+// them to the user. We then poll for the token until the user authorized golab.
+func (c Config) DeviceToken(prompt func(userCode string, verificationURI string)) (*Token, error) {
+	dr := &deviceResponse{}
+	err := c.post("/oauth/authorize_device", url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {c.scope()},
+	}, dr)
+	if err != nil {
+		return nil, err
+	}
+	verificationURI := dr.VerificationURIComplete
+	if verificationURI == "" {
+		verificationURI = dr.VerificationURI
+	}
+	prompt(dr.UserCode, verificationURI)
+
+	interval := time.Duration(dr.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(dr.ExpiresIn) * time.Second)
+	for dr.ExpiresIn == 0 || time.Now().Before(deadline) {
+		time.Sleep(interval)
+		token, err := c.requestToken(url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {dr.DeviceCode},
+		})
+		if e, ok := err.(*Error); ok {
+			switch e.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			}
+		}
+		return token, err
+	}
+	return nil, errors.New("device code expired before golab was authorized")
+}
@@ -0,0 +1,215 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package oauth implements the OAuth2 flows that Gitlab provides for
+// applications, see https://docs.gitlab.com/ce/api/oauth2.html
+//
+// We implement the resource owner password credentials flow, the
+// authorization code flow with PKCE and a loopback redirect listener,
+// the device authorization flow and the refresh of tokens.
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	PasswordFlow = "password"
+	CodeFlow     = "code"
+	DeviceFlow   = "device"
+)
+
+// expiryDelta makes tokens expire a bit earlier, so that they don't expire during a request
+const expiryDelta = 30 * time.Second
+
+// Token is an OAuth token as it is persisted in the credential store.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	// ClientID and ClientSecret are required to refresh the token
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// Expired returns true if the token has an expiry that is (nearly) reached.
+func (t *Token) Expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(expiryDelta).After(t.Expiry)
+}
+
+// Encode returns the token as JSON string.
+func (t *Token) Encode() (string, error) {
+	encoded, err := json.Marshal(t)
+	return string(encoded), err
+}
+
+// Decode parses a token that was encoded with Encode.
+func Decode(encoded string) (*Token, error) {
+	token := &Token{}
+	if err := json.Unmarshal([]byte(encoded), token); err != nil {
+		return nil, errors.New("could not decode OAuth token: " + err.Error())
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("could not decode OAuth token: access token is empty")
+	}
+	return token, nil
+}
+
+// Config holds the settings of the OAuth application registered in Gitlab.
+type Config struct {
+	// BaseURL is the URL of the Gitlab server, e.g. https://gitlab.com
+	BaseURL      string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// RedirectPort is the port of the loopback listener for the authorization code flow
+	RedirectPort int
+	HTTPClient   *http.Client
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int64  `json:"expires_in"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// PasswordToken requests a token with the resource owner password credentials flow.
+func (c Config) PasswordToken(username string, password string) (*Token, error) {
+	return c.requestToken(url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+		"scope":      {c.scope()},
+	})
+}
+
+// Refresh requests a new token using the refresh token of the given token.
+func (c Config) Refresh(token *Token) (*Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errors.New("OAuth token is expired and cannot be refreshed, use `golab login` to login again")
+	}
+	c.ClientID = token.ClientID
+	c.ClientSecret = token.ClientSecret
+	refreshed, err := c.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	})
+	if err != nil {
+		return nil, errors.New("could not refresh OAuth token, use `golab login` to login again: " + err.Error())
+	}
+	return refreshed, nil
+}
+
+func (c Config) scope() string {
+	if len(c.Scopes) == 0 {
+		return "api"
+	}
+	return strings.Join(c.Scopes, " ")
+}
+
+func (c Config) endpoint(path string) string {
+	return strings.TrimSuffix(c.BaseURL, "/") + path
+}
+
+func (c Config) client() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c Config) requestToken(params url.Values) (*Token, error) {
+	params.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		params.Set("client_secret", c.ClientSecret)
+	}
+	tr := &tokenResponse{}
+	if err := c.post("/oauth/token", params, tr); err != nil {
+		return nil, err
+	}
+	return c.token(tr), nil
+}
+
+func (c Config) token(tr *tokenResponse) *Token {
+	token := &Token{
+		AccessToken:  tr.AccessToken,
+		RefreshToken: tr.RefreshToken,
+		TokenType:    tr.TokenType,
+		Scope:        tr.Scope,
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+	}
+	if tr.ExpiresIn > 0 {
+		created := time.Now()
+		if tr.CreatedAt > 0 {
+			created = time.Unix(tr.CreatedAt, 0)
+		}
+		token.Expiry = created.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token
+}
+
+// post sends a form to the given endpoint and decodes the JSON response into v.
+// Errors returned by Gitlab are decoded into an *Error.
+func (c Config) post(path string, params url.Values, v interface{}) error {
+	resp, err := c.client().PostForm(c.endpoint(path), params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		e := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(body, e) != nil || e.Code == "" {
+			e.Description = strings.TrimSpace(string(body))
+		}
+		return e
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Error is an error response of the Gitlab OAuth endpoints.
+type Error struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("OAuth request failed with status %d: %s", e.Status, e.Description)
+	}
+	return fmt.Sprintf("OAuth request failed: %s (%s)", e.Code, e.Description)
+}
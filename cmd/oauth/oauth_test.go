@@ -0,0 +1,254 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package oauth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OAuth", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		config Config
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		config = Config{BaseURL: server.URL, ClientID: "my-app"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	tokenResponse := func(w http.ResponseWriter) {
+		fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":7200,"created_at":`+fmt.Sprint(time.Now().Unix())+`}`)
+	}
+
+	Describe("Token", func() {
+
+		It("is expired shortly before its expiry", func() {
+			Expect((&Token{}).Expired()).To(BeFalse())
+			Expect((&Token{Expiry: time.Now().Add(time.Hour)}).Expired()).To(BeFalse())
+			Expect((&Token{Expiry: time.Now().Add(10 * time.Second)}).Expired()).To(BeTrue())
+		})
+
+		It("can be encoded and decoded", func() {
+			token := &Token{AccessToken: "access", RefreshToken: "refresh", ClientID: "my-app", Expiry: time.Unix(1500000000, 0)}
+			encoded, err := token.Encode()
+			Expect(err).To(BeNil())
+			decoded, err := Decode(encoded)
+			Expect(err).To(BeNil())
+			Expect(decoded.AccessToken).To(Equal("access"))
+			Expect(decoded.ClientID).To(Equal("my-app"))
+			Expect(decoded.Expiry.Equal(token.Expiry)).To(BeTrue())
+		})
+
+		It("cannot decode plain personal access tokens", func() {
+			_, err := Decode("abcdef")
+			Expect(err).NotTo(BeNil())
+		})
+
+	})
+
+	Describe("PasswordToken", func() {
+
+		It("requests a token with username and password", func() {
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("grant_type")).To(Equal("password"))
+				Expect(r.FormValue("username")).To(Equal("root"))
+				Expect(r.FormValue("password")).To(Equal("secret"))
+				Expect(r.FormValue("client_id")).To(Equal("my-app"))
+				Expect(r.FormValue("scope")).To(Equal("api"))
+				tokenResponse(w)
+			})
+			token, err := config.PasswordToken("root", "secret")
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+			Expect(token.RefreshToken).To(Equal("refresh"))
+			Expect(token.ClientID).To(Equal("my-app"))
+			Expect(token.Expiry).To(BeTemporally("~", time.Now().Add(2*time.Hour), time.Minute))
+		})
+
+		It("returns the OAuth error", func() {
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"The provided authorization grant is invalid"}`)
+			})
+			_, err := config.PasswordToken("root", "wrong")
+			Expect(err).To(MatchError("OAuth request failed: invalid_grant (The provided authorization grant is invalid)"))
+		})
+
+	})
+
+	Describe("Refresh", func() {
+
+		It("requests a new token with the refresh token", func() {
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("grant_type")).To(Equal("refresh_token"))
+				Expect(r.FormValue("refresh_token")).To(Equal("old-refresh"))
+				Expect(r.FormValue("client_id")).To(Equal("stored-app"))
+				tokenResponse(w)
+			})
+			token, err := Config{BaseURL: server.URL}.Refresh(&Token{AccessToken: "old", RefreshToken: "old-refresh", ClientID: "stored-app"})
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+			Expect(token.ClientID).To(Equal("stored-app"))
+		})
+
+		It("returns an error for tokens without refresh token", func() {
+			_, err := config.Refresh(&Token{AccessToken: "old"})
+			Expect(err).To(MatchError(ContainSubstring("cannot be refreshed")))
+		})
+
+	})
+
+	Describe("CodeToken", func() {
+
+		It("receives the code on the loopback listener and exchanges it", func() {
+			listener, _ := net.Listen("tcp", "127.0.0.1:0")
+			config.RedirectPort = listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("grant_type")).To(Equal("authorization_code"))
+				Expect(r.FormValue("code")).To(Equal("the-code"))
+				Expect(r.FormValue("redirect_uri")).To(Equal(config.RedirectURI()))
+				Expect(r.FormValue("code_verifier")).NotTo(BeEmpty())
+				tokenResponse(w)
+			})
+
+			token, err := config.CodeToken(func(authorizeURL string) error {
+				u, _ := url.Parse(authorizeURL)
+				Expect(u.Path).To(Equal("/oauth/authorize"))
+				Expect(u.Query().Get("code_challenge_method")).To(Equal("S256"))
+				// simulate the browser following the redirect of Gitlab
+				go http.Get(u.Query().Get("redirect_uri") + "?code=the-code&state=" + u.Query().Get("state"))
+				return nil
+			})
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+		})
+
+		It("rejects redirects with an invalid state without aborting the login", func() {
+			listener, _ := net.Listen("tcp", "127.0.0.1:0")
+			config.RedirectPort = listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("code")).To(Equal("the-code"))
+				tokenResponse(w)
+			})
+
+			forged := make(chan int, 1)
+			token, err := config.CodeToken(func(authorizeURL string) error {
+				u, _ := url.Parse(authorizeURL)
+				go func() {
+					resp, err := http.Get(u.Query().Get("redirect_uri") + "?code=forged-code&state=forged")
+					if err == nil {
+						resp.Body.Close()
+						forged <- resp.StatusCode
+					}
+					http.Get(u.Query().Get("redirect_uri") + "?code=the-code&state=" + u.Query().Get("state"))
+				}()
+				return nil
+			})
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+			Expect(<-forged).To(Equal(http.StatusBadRequest))
+		})
+
+		It("answers reloads of the callback page after the code was received", func() {
+			listener, _ := net.Listen("tcp", "127.0.0.1:0")
+			config.RedirectPort = listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+
+			received := make(chan bool)
+			reloaded := make(chan error, 1)
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				// the code is only exchanged once the reloads were answered
+				Eventually(reloaded).Should(Receive(BeNil()))
+				tokenResponse(w)
+			})
+
+			token, err := config.CodeToken(func(authorizeURL string) error {
+				u, _ := url.Parse(authorizeURL)
+				callback := u.Query().Get("redirect_uri") + "?code=the-code&state=" + u.Query().Get("state")
+				go func() {
+					http.Get(callback)
+					close(received)
+					_, err := http.Get(callback)
+					if err == nil {
+						_, err = http.Get(callback)
+					}
+					reloaded <- err
+				}()
+				return nil
+			})
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+			Eventually(received).Should(BeClosed())
+		})
+
+	})
+
+	Describe("DeviceToken", func() {
+
+		It("polls for the token until the user authorized golab", func() {
+			mux.HandleFunc("/oauth/authorize_device", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("client_id")).To(Equal("my-app"))
+				fmt.Fprint(w, `{"device_code":"device","user_code":"ABCD-1234","verification_uri":"`+server.URL+`/oauth/device","expires_in":300,"interval":1}`)
+			})
+			polls := 0
+			mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("device_code")).To(Equal("device"))
+				polls++
+				if polls == 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprint(w, `{"error":"authorization_pending"}`)
+					return
+				}
+				tokenResponse(w)
+			})
+			var userCode string
+			token, err := config.DeviceToken(func(code string, verificationURI string) {
+				userCode = code
+			})
+			Expect(err).To(BeNil())
+			Expect(userCode).To(Equal("ABCD-1234"))
+			Expect(polls).To(Equal(2))
+			Expect(token.AccessToken).To(Equal("access"))
+		})
+
+	})
+
+})
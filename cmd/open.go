@@ -17,49 +17,249 @@
 // LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
-
 package cmd
 
 import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
 	"github.com/michaellihs/golab/cmd/helpers"
 	"github.com/spf13/cobra"
 )
 
+type openFlags struct {
+	Print *bool `flag_name:"print" type:"bool" required:"no" description:"Print the URL instead of opening it in the browser, e.g. when working over SSH (default: false)"`
+}
+
 var openCmd = &golabCommand{
 	Parent: RootCmd,
+	Flags:  &openFlags{},
 	Cmd: &cobra.Command{
 		Use:     "open",
 		Aliases: []string{"o"},
 		Short:   "Open Gitlab for project",
-		Long:    `Open the Gitlab project page for the repository in current directory`,
+		Long: `Open the Gitlab project page for the repository in current directory (see --remote)
+
+Use one of the subcommands to open a specific page of the project, e.g.
+
+    golab open mr 42
+    golab open file cmd/root.go:57
+    golab open new-mr --print`,
+	},
+	Run: func(cmd golabCommand) error {
+		return openPage(cmd, "")
+	},
+}
+
+var openMrCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:     "mr [iid]",
+		Aliases: []string{"merge-request"},
+		Short:   "Open merge request",
+		Long:    `Open the merge request with the given iid, or the list of merge requests if no iid is given`,
+		Args:    cobra.MaximumNArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		return openPage(cmd, "/-/merge_requests"+optionalPathArg(cmd.Args))
+	},
+}
+
+var openIssueCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "issue [iid]",
+		Short: "Open issue",
+		Long:  `Open the issue with the given iid, or the list of issues if no iid is given`,
+		Args:  cobra.MaximumNArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		return openPage(cmd, "/-/issues"+optionalPathArg(cmd.Args))
+	},
+}
+
+var openPipelineCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "pipeline [id]",
+		Short: "Open pipeline",
+		Long:  `Open the pipeline with the given id, or the list of pipelines if no id is given`,
+		Args:  cobra.MaximumNArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		return openPage(cmd, "/-/pipelines"+optionalPathArg(cmd.Args))
+	},
+}
+
+var openFileCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "file <path>[:line]",
+		Short: "Open file",
+		Long:  `Open the file (relative to the working directory) on the current branch, optionally highlighting the given line`,
+		Args:  cobra.ExactArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		page, err := filePage(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+		return openPage(cmd, page)
+	},
+}
+
+var openCommitCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "commit [sha]",
+		Short: "Open commit",
+		Long:  `Open the commit with the given SHA (or any other revision like a tag), or the checked out commit if no SHA is given`,
+		Args:  cobra.MaximumNArgs(1),
 	},
 	Run: func(cmd golabCommand) error {
-		bh := helpers.NewBrowserHelper()
-		if url, err := getRemoteUrl(); err == nil {
-			return bh.Open(url)
-		} else {
+		revision := "HEAD"
+		if len(cmd.Args) > 0 {
+			revision = cmd.Args[0]
+		}
+		sha, err := helpers.GitHelper().RevParse(revision)
+		if err != nil {
+			return fmt.Errorf("unknown revision %s", revision)
+		}
+		return openPage(cmd, "/-/commit/"+sha)
+	},
+}
+
+var openBranchCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "branch [name]",
+		Short: "Open branch",
+		Long:  `Open the files of the given branch, or the current branch if no name is given`,
+		Args:  cobra.MaximumNArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		branch, err := branchArg(cmd.Args)
+		if err != nil {
 			return err
 		}
+		return openPage(cmd, "/-/tree/"+escapePath(branch))
 	},
 }
 
+var openNewMrCmd = &golabCommand{
+	Parent: openCmd.Cmd,
+	Flags:  &openFlags{},
+	Cmd: &cobra.Command{
+		Use:   "new-mr [target branch]",
+		Short: "Open new merge request",
+		Long:  `Open the form for a new merge request from the current branch, optionally into the given target branch`,
+		Args:  cobra.MaximumNArgs(1),
+	},
+	Run: func(cmd golabCommand) error {
+		branch, err := branchArg(nil)
+		if err != nil {
+			return err
+		}
+		query := url.Values{"merge_request[source_branch]": {branch}}
+		if len(cmd.Args) > 0 {
+			query.Set("merge_request[target_branch]", cmd.Args[0])
+		}
+		return openPage(cmd, "/-/merge_requests/new?"+query.Encode())
+	},
+}
+
+// openPage opens (or prints) the given page of the project of the git remote
+func openPage(cmd golabCommand, page string) error {
+	projectUrl, err := getRemoteUrl()
+	if err != nil {
+		return err
+	}
+	if flags := cmd.Flags.(*openFlags); flags.Print != nil && *flags.Print {
+		fmt.Println(projectUrl + page)
+		return nil
+	}
+	return helpers.NewBrowserHelper().Open(projectUrl + page)
+}
+
 func getRemoteUrl() (string, error) {
-	gh := helpers.GitHelper()
-	remotes, err := gh.GetRemotes()
+	remote, err := helpers.GitHelper().GetRemote(remoteName())
 	if err != nil {
 		return "", err
 	}
-	remote, err := gh.GetRemoteUrl(remotes)
+	return remote.WebUrl(), nil
+}
+
+func optionalPathArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return "/" + url.PathEscape(args[0])
+}
+
+// escapePath escapes the segments of a branch name or file path, keeping the slashes between them
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// branchArg returns the given branch or the checked out branch
+func branchArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	return resolveBranch()
+}
+
+var lineSuffix = regexp.MustCompile(`:(\d+)$`)
+
+// filePage returns the blob page of a file given relative to the working directory
+func filePage(file string) (string, error) {
+	anchor := ""
+	if match := lineSuffix.FindStringSubmatch(file); match != nil {
+		anchor = "#L" + match[1]
+		file = strings.TrimSuffix(file, match[0])
+	}
+	git := helpers.GitHelper()
+	prefix, err := git.PathPrefix()
 	if err != nil {
 		return "", err
 	}
-	url, err := gh.GetWebUrl(remote)
+	file = path.Clean(path.Join(prefix, file))
+	if strings.HasPrefix(file, "../") || file == ".." {
+		return "", fmt.Errorf("file %s is outside of the repository", file)
+	}
+	ref, err := git.CurrentBranch()
 	if err != nil {
 		return "", err
 	}
-	return url, nil
+	if ref == "HEAD" {
+		// detached HEAD, link the checked out commit
+		if ref, err = git.RevParse("HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return "/-/blob/" + escapePath(ref) + "/" + escapePath(file) + anchor, nil
 }
 
 func init() {
 	openCmd.Init()
+	openMrCmd.Init()
+	openIssueCmd.Init()
+	openPipelineCmd.Init()
+	openFileCmd.Init()
+	openCommitCmd.Init()
+	openBranchCmd.Init()
+	openNewMrCmd.Init()
 }
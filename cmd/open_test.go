@@ -0,0 +1,107 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("open", func() {
+
+	var repo, prevDir, head string
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		Expect(err).To(BeNil(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	open := func(args ...string) string {
+		stdout, _, err := executeCommand(RootCmd, append([]string{"open"}, args...)...)
+		Expect(err).To(BeNil())
+		return strings.TrimSpace(stdout)
+	}
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		for _, cmd := range []*golabCommand{openCmd, openMrCmd, openIssueCmd, openPipelineCmd, openFileCmd, openCommitCmd, openBranchCmd, openNewMrCmd} {
+			resetFlags(cmd)
+		}
+
+		prevDir, _ = os.Getwd()
+		repo, _ = ioutil.TempDir("", "golab-repo")
+		os.Chdir(repo)
+		git("init", "-q")
+		git("checkout", "-q", "-b", "feature/open")
+		os.Mkdir("cmd", 0755)
+		ioutil.WriteFile("cmd/root.go", []byte("package cmd\n"), 0644)
+		git("add", "-A")
+		git("-c", "user.name=golab", "-c", "user.email=golab@my-domain.com", "commit", "-q", "-m", "initial commit")
+		git("remote", "add", "origin", "git@gitlab.my-domain.com:my-group/my-project.git")
+		head = git("rev-parse", "HEAD")
+	})
+
+	AfterEach(func() {
+		os.Chdir(prevDir)
+		os.RemoveAll(repo)
+	})
+
+	It("prints the URL of the project", func() {
+		Expect(open("--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project"))
+	})
+
+	It("prints the URLs of merge requests, issues and pipelines", func() {
+		Expect(open("mr", "42", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/merge_requests/42"))
+		Expect(open("issue", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/issues"))
+		Expect(open("pipeline", "1234", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/pipelines/1234"))
+	})
+
+	It("prints the URL of a file on the current branch relative to the working directory", func() {
+		os.Chdir("cmd")
+		Expect(open("file", "root.go:1", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/blob/feature/open/cmd/root.go#L1"))
+	})
+
+	It("prints the URLs of commits and branches", func() {
+		Expect(open("commit", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/commit/" + head))
+		Expect(open("branch", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/tree/feature/open"))
+	})
+
+	It("escapes branch names and file paths", func() {
+		Expect(open("branch", "fix#1/100%", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/tree/fix%231/100%25"))
+		Expect(open("file", "docs/my notes#1.md:3", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/blob/feature/open/docs/my%20notes%231.md#L3"))
+	})
+
+	It("prints the URL of a new merge request for the current branch", func() {
+		Expect(open("new-mr", "master", "--print")).To(Equal("https://gitlab.my-domain.com/my-group/my-project/-/merge_requests/new?merge_request%5Bsource_branch%5D=feature%2Fopen&merge_request%5Btarget_branch%5D=master"))
+	})
+
+	It("returns an error for unknown commits", func() {
+		_, _, err := executeCommand(RootCmd, "open", "commit", "does-not-exist", "--print")
+		Expect(err).To(MatchError("unknown revision does-not-exist"))
+	})
+
+})
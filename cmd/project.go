@@ -22,17 +22,16 @@ package cmd
 
 import (
 	"errors"
+	"net/http"
+	"net/url"
 	"strconv"
 
 	. "github.com/michaellihs/golab/cmd/helpers"
 
-	"github.com/michaellihs/golab/cmd/mapper"
 	"github.com/spf13/cobra"
 	"github.com/xanzy/go-gitlab"
 )
 
-var createOptsMapper, listOptsMapper, getOptsMapper, editOptsMapper, forkOptsMapper, shareOptsMapper, addHookOptsMapper, editHookOptsMapper, projectSearchOptsMapper, listForksOptsMapper mapper.FlagMapper
-
 // see https://docs.gitlab.com/ce/api/projects.html
 var projectsCmd = &golabCommand{
 	Parent: RootCmd,
@@ -49,9 +48,9 @@ var projectsCmd = &golabCommand{
 // see https://docs.gitlab.com/ce/api/projects.html#list-all-projects
 type projectsListFlags struct {
 	Archived                 *bool   `flag_name:"archived" type:"bool" required:"no" description:"Limit by archived status"`
-	Visibility               *string `flag_name:"visibility" type:"string" required:"no" description:"Limit by visibility public, internal, or private"`
+	Visibility               *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"Limit by visibility public, internal, or private"`
 	OrderBy                  *string `flag_name:"order_by" type:"string" required:"no" description:"Return projects ordered by id, name, path, created_at, updated_at, or last_activity_at fields. Default is created_at"`
-	Sort                     *string `flag_name:"sort" type:"string" required:"no" description:"Return projects sorted in asc or desc order. Default is desc"`
+	Sort                     *string `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return projects sorted in asc or desc order. Default is desc"`
 	Search                   *string `flag_name:"search" type:"string" required:"no" description:"Return list of projects matching the search criteria"`
 	Simple                   *bool   `flag_name:"simple" type:"bool" required:"no" description:"Return only the ID, URL, name, and path of each project"`
 	Owned                    *bool   `flag_name:"owned" type:"bool" required:"no" description:"Limit by projects owned by the current user"`
@@ -84,21 +83,21 @@ var projectsListCmd = &golabCommand{
 }
 
 type getFlags struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"either the project ID (numeric) or 'namespace/project-name'"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"either the project ID (numeric) or 'namespace/project-name'"`
 	// TODO currently not supported by go-gitlab
 	Statistics *bool `flag_name:"statistics" short:"s" required:"no" description:"include project statistics"`
 }
 
-var projectGetCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Get detailed information for a project",
-	Long:  `Get detailed information for a project identified by either project ID or 'namespace/project-name'`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		_, _, err := getOptsMapper.AutoMap()
-		flags := getOptsMapper.MappedOpts().(*getFlags)
-		if *flags.Id == "" {
-			return errors.New("you have to provide a project ID or 'namespace/project-name' with the -i --id flag")
-		}
+var projectGetCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &getFlags{},
+	Cmd: &cobra.Command{
+		Use:   "get",
+		Short: "Get detailed information for a project",
+		Long:  `Get detailed information for a project identified by either project ID or 'namespace/project-name'`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*getFlags)
 		project, _, err := gitlabClient.Projects.GetProject(parsePid(*flags.Id)) // make sure, parsedPid is of type int if numeric
 		if err != nil {
 			return err
@@ -121,7 +120,7 @@ type createFlags struct {
 	ResolveOutdatedDiffDiscussions            *bool     `flag_name:"resolve_outdated_diff_discussions" type:"bool" required:"no" description:"Automatically resolve merge request diffs discussions on lines changed with a push"`
 	ContainerRegistryEnabled                  *bool     `flag_name:"container_registry_enabled" type:"bool" required:"no" description:"Enable container registry for this project"`
 	SharedRunnersEnabled                      *bool     `flag_name:"shared_runners_enabled" type:"bool" required:"no" description:"Enable shared runners for this project"`
-	Visibility                                *string   `flag_name:"visibility" type:"string" required:"no" description:"See project visibility level"`
+	Visibility                                *string   `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"See project visibility level"`
 	ImportUrl                                 *string   `flag_name:"import_url" type:"string" required:"no" description:"URL to import repository from"`
 	PublicJobs                                *bool     `flag_name:"public_jobs" type:"bool" required:"no" description:"If true, jobs can be viewed by non-project-members"`
 	OnlyAllowMergeIfPipelineSucceeds          *bool     `flag_name:"only_allow_merge_if_pipeline_succeeds" type:"bool" required:"no" description:"Set whether merge requests can only be merged with successful jobs"`
@@ -134,11 +133,16 @@ type createFlags struct {
 	CiConfigPath                              *string   `flag_name:"ci_config_path" type:"string" required:"no" description:"The path to CI config file"`
 }
 
-var projectCreateCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a new project",
-	Long:  `Create a new project for the given parameters`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+var projectCreateCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &createFlags{},
+	Opts:   &gitlab.CreateProjectOptions{},
+	Cmd: &cobra.Command{
+		Use:   "create",
+		Short: "Create a new project",
+		Long:  `Create a new project for the given parameters`,
+	},
+	Run: func(cmd golabCommand) error {
 		// TODO add this to use name of group instead of namespace_id
 		//groups, _, err := gitlabClient.Groups.SearchGroup(group)
 		//if err != nil {
@@ -154,11 +158,7 @@ var projectCreateCmd = &cobra.Command{
 		//	NamespaceID: &groups[0].ID,
 		//}
 
-		_, _, err := createOptsMapper.AutoMap()
-		opts := createOptsMapper.MappedOpts().(*gitlab.CreateProjectOptions)
-		if err != nil {
-			return err
-		}
+		opts := cmd.Opts.(*gitlab.CreateProjectOptions)
 		project, _, err := gitlabClient.Projects.CreateProject(opts)
 		if err != nil {
 			return err
@@ -168,7 +168,7 @@ var projectCreateCmd = &cobra.Command{
 }
 
 type editFlags struct {
-	Id                                        *string   `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id                                        *string   `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Name                                      *string   `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the project"`
 	Path                                      *string   `flag_name:"path" type:"string" required:"no" description:"Custom repository name for the project. By default generated based on name"`
 	DefaultBranch                             *string   `flag_name:"default_branch" type:"string" required:"no" description:"master by default"`
@@ -181,7 +181,7 @@ type editFlags struct {
 	ResolveOutdatedDiffDiscussions            *bool     `flag_name:"resolve_outdated_diff_discussions" type:"bool" required:"no" description:"Automatically resolve merge request diffs discussions on lines changed with a push"`
 	ContainerRegistryEnabled                  *bool     `flag_name:"container_registry_enabled" type:"bool" required:"no" description:"Enable container registry for this project"`
 	SharedRunnersEnabled                      *bool     `flag_name:"shared_runners_enabled" type:"bool" required:"no" description:"Enable shared runners for this project"`
-	Visibility                                *string   `flag_name:"visibility" type:"string" required:"no" description:"See project visibility level"`
+	Visibility                                *string   `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"See project visibility level"`
 	ImportUrl                                 *string   `flag_name:"import_url" type:"string" required:"no" description:"URL to import repository from"`
 	PublicJobs                                *bool     `flag_name:"public_jobs" type:"bool" required:"no" description:"If true, jobs can be viewed by non-project-members"`
 	OnlyAllowMergeIfPipelineSucceeds          *bool     `flag_name:"only_allow_merge_if_pipeline_succeeds" type:"bool" required:"no" description:"Set whether merge requests can only be merged with successful jobs"`
@@ -192,14 +192,18 @@ type editFlags struct {
 	CiConfigPath                              *string   `flag_name:"ci_config_path" type:"string" required:"no" description:"The path to CI config file"`
 }
 
-var projectEditCmd = &cobra.Command{
-	Use:   "edit",
-	Short: "Edit project",
-	Long:  `Updates an existing project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		editOptsMapper.AutoMap()
-		opts := editOptsMapper.MappedOpts().(*gitlab.EditProjectOptions)
-		flags := editOptsMapper.MappedFlags().(*editFlags)
+var projectEditCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &editFlags{},
+	Opts:   &gitlab.EditProjectOptions{},
+	Cmd: &cobra.Command{
+		Use:   "edit",
+		Short: "Edit project",
+		Long:  `Updates an existing project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*editFlags)
+		opts := cmd.Opts.(*gitlab.EditProjectOptions)
 		project, _, err := gitlabClient.Projects.EditProject(*flags.Id, opts)
 		if err != nil {
 			return err
@@ -209,19 +213,22 @@ var projectEditCmd = &cobra.Command{
 }
 
 type forkFlags struct {
-	Id        *string `flag_name:"id" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id        *string `flag_name:"id" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Namespace *string `flag_name:"namespace" type:"integer/string" required:"yes" description:"The ID or path of the namespace that the project will be forked to"`
 }
 
-var projectForkCmd = &cobra.Command{
-	Use:   "fork",
-	Short: "Fork project",
-	Long: `Forks a project into the user namespace of the authenticated user or the one provided.
+var projectForkCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &forkFlags{},
+	Cmd: &cobra.Command{
+		Use:   "fork",
+		Short: "Fork project",
+		Long: `Forks a project into the user namespace of the authenticated user or the one provided.
 
 The forking operation for a project is asynchronous and is completed in a background job. The request will return immediately. To determine whether the fork of the project has completed, query the import_status for the new project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		forkOptsMapper.AutoMap()
-		flags := forkOptsMapper.MappedFlags().(*forkFlags)
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*forkFlags)
 		// TODO target namespace is currently not supported by go-gitlab
 		project, _, err := gitlabClient.Projects.ForkProject(*flags.Id)
 		if err != nil {
@@ -232,11 +239,11 @@ The forking operation for a project is asynchronous and is completed in a backgr
 }
 
 type listForksFlags struct {
-	Id                       *string `flag_name:"id" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id                       *string `flag_name:"id" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	Archived                 *bool   `flag_name:"archived" type:"bool" required:"no" description:"Limit by archived status"`
-	Visibility               *string `flag_name:"visibility" type:"string" required:"no" description:"Limit by visibility public, internal, or private"`
+	Visibility               *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" description:"Limit by visibility public, internal, or private"`
 	OrderBy                  *string `flag_name:"order_by" type:"string" required:"no" description:"Return projects ordered by id, name, path, created_at, updated_at, or last_activity_at fields. Default is created_at"`
-	Sort                     *string `flag_name:"sort" type:"string" required:"no" description:"Return projects sorted in asc or desc order. Default is desc"`
+	Sort                     *string `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return projects sorted in asc or desc order. Default is desc"`
 	Search                   *string `flag_name:"search" type:"string" required:"no" description:"Return list of projects matching the search criteria"`
 	Simple                   *bool   `flag_name:"simple" type:"bool" required:"no" description:"Return only the ID, URL, name, and path of each project"`
 	Owned                    *bool   `flag_name:"owned" type:"bool" required:"no" description:"Limit by projects owned by the current user"`
@@ -247,27 +254,37 @@ type listForksFlags struct {
 	WithMergeRequestsEnabled *bool   `flag_name:"with_merge_requests_enabled" type:"bool" required:"no" description:"Limit by enabled merge requests feature"`
 }
 
-var projectListForksCmd = &cobra.Command{
-	Use:   "list-forks",
-	Short: "List Forks of a project",
-	Long:  `List the projects accessible to the calling user that have an established, forked relationship with the specified project (available since Gitlab 10.1).`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+var projectListForksCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &listForksFlags{},
+	Cmd: &cobra.Command{
+		Use:   "list-forks",
+		Short: "List Forks of a project",
+		Long:  `List the projects accessible to the calling user that have an established, forked relationship with the specified project (available since Gitlab 10.1).`,
+	},
+	Run: func(cmd golabCommand) error {
 		// TODO currently not available in go-gitlab
 		//opts := listForkOpts()
 		return errors.New("list forks of a project is currently not implemented")
 	},
 }
 
-var projectStarCmd = &cobra.Command{
-	Use:   "star",
-	Short: "Star a project ",
-	Long:  `Stars a given project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		project, _, err := gitlabClient.Projects.StarProject(pid)
+// projectIdFlags are the flags of commands that only take the project
+type projectIdFlags struct {
+	Id *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+}
+
+var projectStarCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "star",
+		Short: "Star a project ",
+		Long:  `Stars a given project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		project, _, err := gitlabClient.Projects.StarProject(*flags.Id)
 		if err != nil {
 			return err
 		}
@@ -275,16 +292,17 @@ var projectStarCmd = &cobra.Command{
 	},
 }
 
-var projectUnstarCmd = &cobra.Command{
-	Use:   "unstar",
-	Short: "Unstar a project",
-	Long:  `Unstars a given project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		project, _, err := gitlabClient.Projects.UnstarProject(pid)
+var projectUnstarCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "unstar",
+		Short: "Unstar a project",
+		Long:  `Unstars a given project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		project, _, err := gitlabClient.Projects.UnstarProject(*flags.Id)
 		if err != nil {
 			return err
 		}
@@ -292,16 +310,17 @@ var projectUnstarCmd = &cobra.Command{
 	},
 }
 
-var projectArchiveCmd = &cobra.Command{
-	Use:   "archive",
-	Short: "Archive a project",
-	Long:  `Archives the project if the user is either admin or the project owner of this project. This action is idempotent, thus archiving an already archived project will not change the project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		project, _, err := gitlabClient.Projects.ArchiveProject(pid)
+var projectArchiveCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "archive",
+		Short: "Archive a project",
+		Long:  `Archives the project if the user is either admin or the project owner of this project. This action is idempotent, thus archiving an already archived project will not change the project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		project, _, err := gitlabClient.Projects.ArchiveProject(*flags.Id)
 		if err != nil {
 			return err
 		}
@@ -309,16 +328,17 @@ var projectArchiveCmd = &cobra.Command{
 	},
 }
 
-var projectUnarchiveCmd = &cobra.Command{
-	Use:   "unarchive",
-	Short: "Unarchive a project",
-	Long:  `Unarchives the project if the user is either admin or the project owner of this project. This action is idempotent, thus unarchiving an non-archived project will not change the project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		project, _, err := gitlabClient.Projects.UnarchiveProject(pid)
+var projectUnarchiveCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "unarchive",
+		Short: "Unarchive a project",
+		Long:  `Unarchives the project if the user is either admin or the project owner of this project. This action is idempotent, thus unarchiving an non-archived project will not change the project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		project, _, err := gitlabClient.Projects.UnarchiveProject(*flags.Id)
 		if err != nil {
 			return err
 		}
@@ -326,34 +346,37 @@ var projectUnarchiveCmd = &cobra.Command{
 	},
 }
 
-var projectDeleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Remove project",
-	Long:  `Removes a project including all associated resources (issues, merge requests etc.)`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		_, err = gitlabClient.Projects.DeleteProject(pid)
+var projectDeleteCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Cmd: &cobra.Command{
+		Use:   "delete",
+		Short: "Remove project",
+		Long:  `Removes a project including all associated resources (issues, merge requests etc.)`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		_, err := gitlabClient.Projects.DeleteProject(*flags.Id)
 		return err
 	},
 }
 
-var projectUploadFileCmd = &cobra.Command{
-	Use:   "upload-file",
-	Short: "Upload a file",
-	Long:  `Uploads a file to the specified project to be used in an issue or merge request description, or a comment.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		file, err := cmd.Flags().GetString("file")
-		if err != nil {
-			return err
-		}
-		projectFile, _, err := gitlabClient.Projects.UploadFile(pid, file)
+type projectUploadFileFlags struct {
+	Id   *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	File *string `flag_name:"file" short:"f" type:"string" required:"yes" description:"Path to the file to be uploaded"`
+}
+
+var projectUploadFileCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectUploadFileFlags{},
+	Cmd: &cobra.Command{
+		Use:   "upload-file",
+		Short: "Upload a file",
+		Long:  `Uploads a file to the specified project to be used in an issue or merge request description, or a comment.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectUploadFileFlags)
+		projectFile, _, err := gitlabClient.Projects.UploadFile(*flags.Id, *flags.File)
 		if err != nil {
 			return err
 		}
@@ -362,7 +385,7 @@ var projectUploadFileCmd = &cobra.Command{
 }
 
 type shareFlags struct {
-	Id          *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id          *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	GroupID     *int    `flag_name:"group_id" short:"g" type:"integer" required:"yes" description:"The ID of the group to share with"`
 	GroupAccess *string `flag_name:"group_access" short:"a" type:"integer" transform:"str2AccessLevel" required:"yes" description:"The permissions level to grant the group"`
 	ExpiresAt   *string `flag_name:"expires_at" short:"e" type:"string" required:"no" description:"Share expiration date in ISO 8601 format: 2016-09-26"`
@@ -370,59 +393,68 @@ type shareFlags struct {
 	//ExpiresAt   *string  `flag_name:"expires_at" short:"e" type:"string" transform:"string2IsoTime" required:"no" description:"Share expiration date in ISO 8601 format: 2016-09-26"`
 }
 
-var projectShareWithGroupCmd = &cobra.Command{
-	Use:   "share",
-	Short: "Share project with group",
-	Long:  `Allow to share project with group.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		shareOptsMapper.AutoMap()
-		opts := shareOptsMapper.MappedOpts().(*gitlab.ShareWithGroupOptions)
-		flags := shareOptsMapper.MappedFlags().(*shareFlags)
+var projectShareWithGroupCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &shareFlags{},
+	Opts:   &gitlab.ShareWithGroupOptions{},
+	Cmd: &cobra.Command{
+		Use:   "share",
+		Short: "Share project with group",
+		Long:  `Allow to share project with group.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*shareFlags)
+		opts := cmd.Opts.(*gitlab.ShareWithGroupOptions)
 		_, err := gitlabClient.Projects.ShareProjectWithGroup(*flags.Id, opts)
 		return err
 	},
 }
 
-var projectUnshareWithGroupCmd = &cobra.Command{
-	Use:   "unshare",
-	Short: "Delete a shared project link within a group",
-	Long:  `Unshare the project from the group.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		gid, err := cmd.Flags().GetString("group_id")
-		if err != nil {
-			return err
-		}
+type unshareFlags struct {
+	Id      *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	GroupID *int    `flag_name:"group_id" short:"g" type:"integer" required:"yes" description:"The ID of the group"`
+}
+
+var projectUnshareWithGroupCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &unshareFlags{},
+	Cmd: &cobra.Command{
+		Use:   "unshare",
+		Short: "Delete a shared project link within a group",
+		Long:  `Unshare the project from the group.`,
+	},
+	Run: func(cmd golabCommand) error {
 		// TODO delete a share is currently missing in go-gitlab
-		// gitlabClient.Projects...
-		OutputJson(pid)
-		OutputJson(gid)
 		return errors.New("not implemented...")
 	},
 }
 
-var projectHooksCmd = &cobra.Command{
-	Use:   "hooks",
-	Short: "Manage project hooks.",
-	Long:  `Also called Project Hooks and Webhooks. These are different for System Hooks that are system wide.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+var projectHooksCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Cmd: &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage project hooks.",
+		Long:  `Also called Project Hooks and Webhooks. These are different for System Hooks that are system wide.`,
+	},
+	Run: func(cmd golabCommand) error {
 		return errors.New("cannot run this command without further sub-commands")
 	},
 }
 
-var projectHooksListCmd = &cobra.Command{
-	Use:   "ls",
-	Short: "List project hooks",
-	Long:  `Get a list of project hooks.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		hooks, _, err := gitlabClient.Projects.ListProjectHooks(pid, &gitlab.ListProjectHooksOptions{})
+var projectHooksListCmd = &golabCommand{
+	Parent: projectHooksCmd.Cmd,
+	Flags:  &projectIdFlags{},
+	Opts:   &gitlab.ListProjectHooksOptions{},
+	Paged:  true,
+	Cmd: &cobra.Command{
+		Use:   "ls",
+		Short: "List project hooks",
+		Long:  `Get a list of project hooks.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectIdFlags)
+		opts := cmd.Opts.(*gitlab.ListProjectHooksOptions)
+		hooks, _, err := gitlabClient.Projects.ListProjectHooks(*flags.Id, opts)
 		if err != nil {
 			return err
 		}
@@ -430,23 +462,22 @@ var projectHooksListCmd = &cobra.Command{
 	},
 }
 
-var projectHooksGetCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Get project hook",
-	Long:  `Get a specific hook for a project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		hookId, err := cmd.Flags().GetInt("hook_id")
-		if err != nil {
-			return err
-		}
-		if hookId == 0 {
-			return errors.New("you have to provide a valid hook id")
-		}
-		hook, _, err := gitlabClient.Projects.GetProjectHook(pid, hookId)
+type projectHookFlags struct {
+	Id     *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	HookId *int    `flag_name:"hook_id" type:"integer" required:"yes" description:"The ID of the project hook"`
+}
+
+var projectHooksGetCmd = &golabCommand{
+	Parent: projectHooksCmd.Cmd,
+	Flags:  &projectHookFlags{},
+	Cmd: &cobra.Command{
+		Use:   "get",
+		Short: "Get project hook",
+		Long:  `Get a specific hook for a project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectHookFlags)
+		hook, _, err := gitlabClient.Projects.GetProjectHook(*flags.Id, *flags.HookId)
 		if err != nil {
 			return err
 		}
@@ -455,7 +486,7 @@ var projectHooksGetCmd = &cobra.Command{
 }
 
 type addHookFlags struct {
-	Id                    *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id                    *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	URL                   *string `flag_name:"url" short:"u" type:"string" required:"yes" description:"The hook URL"`
 	PushEvents            *bool   `flag_name:"push_events" type:"bool" required:"no" description:"Trigger hook on push events"`
 	IssuesEvents          *bool   `flag_name:"issues_events" type:"bool" required:"no" description:"Trigger hook on issues events"`
@@ -469,14 +500,18 @@ type addHookFlags struct {
 	Token                 *string `flag_name:"token" type:"string" required:"no" description:"Secret token to validate received payloads; this will not be returned in the response"`
 }
 
-var projectAddHookCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add project hook",
-	Long:  `Adds a hook to a specified project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		addHookOptsMapper.AutoMap()
-		flags := addHookOptsMapper.MappedFlags().(*addHookFlags)
-		opts := addHookOptsMapper.MappedOpts().(*gitlab.AddProjectHookOptions)
+var projectAddHookCmd = &golabCommand{
+	Parent: projectHooksCmd.Cmd,
+	Flags:  &addHookFlags{},
+	Opts:   &gitlab.AddProjectHookOptions{},
+	Cmd: &cobra.Command{
+		Use:   "add",
+		Short: "Add project hook",
+		Long:  `Adds a hook to a specified project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*addHookFlags)
+		opts := cmd.Opts.(*gitlab.AddProjectHookOptions)
 		hook, _, err := gitlabClient.Projects.AddProjectHook(parsePid(*flags.Id), opts)
 		if err != nil {
 			return err
@@ -486,7 +521,7 @@ var projectAddHookCmd = &cobra.Command{
 }
 
 type editHookFlags struct {
-	Id                    *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project"`
+	Id                    *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
 	HookId                *int    `flag_name:"hook_id" type:"integer" required:"yes" description:"The ID of the project hook"`
 	URL                   *string `flag_name:"url" short:"u" type:"string" required:"yes" description:"The hook URL"`
 	PushEvents            *bool   `flag_name:"push_events" type:"bool" required:"no" description:"Trigger hook on push events"`
@@ -501,14 +536,18 @@ type editHookFlags struct {
 	Token                 *string `flag_name:"token" type:"string" required:"no" description:"Secret token to validate received payloads; this will not be returned in the response"`
 }
 
-var projectEditHookCmd = &cobra.Command{
-	Use:   "edit",
-	Short: "Edit project hook",
-	Long:  `Edits a hook for a specified project.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		editHookOptsMapper.AutoMap()
-		flags := editHookOptsMapper.MappedFlags().(*editHookFlags)
-		opts := editHookOptsMapper.MappedOpts().(*gitlab.EditProjectHookOptions)
+var projectEditHookCmd = &golabCommand{
+	Parent: projectHooksCmd.Cmd,
+	Flags:  &editHookFlags{},
+	Opts:   &gitlab.EditProjectHookOptions{},
+	Cmd: &cobra.Command{
+		Use:   "edit",
+		Short: "Edit project hook",
+		Long:  `Edits a hook for a specified project.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*editHookFlags)
+		opts := cmd.Opts.(*gitlab.EditProjectHookOptions)
 		hook, _, err := gitlabClient.Projects.EditProjectHook(parsePid(*flags.Id), *flags.HookId, opts)
 		if err != nil {
 			return err
@@ -517,102 +556,164 @@ var projectEditHookCmd = &cobra.Command{
 	},
 }
 
-var projectDeleteHookCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Delete project hook",
-	Long:  `Removes a hook from a project. This is an idempotent method and can be called multiple times. Either the hook is available or not.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetString("id")
-		if err != nil {
-			return err
-		}
-		hookId, err := cmd.Flags().GetInt("hook_id")
-		if err != nil {
-			return err
-		}
-		_, err = gitlabClient.Projects.DeleteProjectHook(pid, hookId)
-		if err != nil {
-			return err
-		}
-		return nil
+var projectDeleteHookCmd = &golabCommand{
+	Parent: projectHooksCmd.Cmd,
+	Flags:  &projectHookFlags{},
+	Cmd: &cobra.Command{
+		Use:   "delete",
+		Short: "Delete project hook",
+		Long:  `Removes a hook from a project. This is an idempotent method and can be called multiple times. Either the hook is available or not.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectHookFlags)
+		_, err := gitlabClient.Projects.DeleteProjectHook(*flags.Id, *flags.HookId)
+		return err
 	},
 }
 
-var projectForskCmd = &cobra.Command{
-	Use:   "forks",
-	Short: "Admin fork relation",
-	Long:  `Allows modification of the forked relationship between existing projects. Available only for admins.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+var projectForksCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Cmd: &cobra.Command{
+		Use:   "forks",
+		Short: "Admin fork relation",
+		Long:  `Allows modification of the forked relationship between existing projects. Available only for admins.`,
+	},
+	Run: func(cmd golabCommand) error {
 		return errors.New("cannot run this command without a sub-command")
 	},
 }
 
-var projectForksCreateCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a forked from/to relation between existing projects",
-	Long:  `Create a forked from/to relation between existing projects (available only for admins)`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetInt("id")
-		if err != nil {
-			return err
-		}
-		forkedFromId, err := cmd.Flags().GetInt("forked_from_id")
-		if err != nil {
-			return err
-		}
+type forksCreateFlags struct {
+	// TODO gitlab API now supports URL encoded path for project id, but go-gitlab does not
+	Id           *int `flag_name:"id" short:"i" type:"integer" required:"yes" description:"The ID of the project"`
+	ForkedFromId *int `flag_name:"forked_from_id" short:"f" type:"integer" required:"yes" description:"The ID of the project that was forked from"`
+}
+
+var projectForksCreateCmd = &golabCommand{
+	Parent: projectForksCmd.Cmd,
+	Flags:  &forksCreateFlags{},
+	Cmd: &cobra.Command{
+		Use:   "create",
+		Short: "Create a forked from/to relation between existing projects",
+		Long:  `Create a forked from/to relation between existing projects (available only for admins)`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*forksCreateFlags)
 		// go-gitlab assumes that a JSON with the fork info is returned, but it seems like only "OK" is returned upon success
-		_, res, err := gitlabClient.Projects.CreateProjectForkRelation(pid, forkedFromId)
-		if res.Status != "201 Created" {
-			return err
+		_, res, err := gitlabClient.Projects.CreateProjectForkRelation(*flags.Id, *flags.ForkedFromId)
+		if res != nil && res.StatusCode == http.StatusCreated {
+			return nil
 		}
-		return nil
+		return err
 	},
 }
 
-var projectForksDeleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Delete an existing forked from relationship",
-	Long:  `Delete an existing forked from relationship (available only for admins)`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		pid, err := cmd.Flags().GetInt("id")
-		if err != nil {
-			return err
+type forksDeleteFlags struct {
+	Id *int `flag_name:"id" short:"i" type:"integer" required:"yes" description:"The ID of the project"`
+}
+
+var projectForksDeleteCmd = &golabCommand{
+	Parent: projectForksCmd.Cmd,
+	Flags:  &forksDeleteFlags{},
+	Cmd: &cobra.Command{
+		Use:   "delete",
+		Short: "Delete an existing forked from relationship",
+		Long:  `Delete an existing forked from relationship (available only for admins)`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*forksDeleteFlags)
+		_, err := gitlabClient.Projects.DeleteProjectForkRelation(*flags.Id)
+		return err
+	},
+}
+
+// see https://docs.gitlab.com/ce/api/projects.html#list-all-projects
+type projectSearchFlags struct {
+	Search     *string `flag_name:"search" short:"s" type:"string" required:"yes" description:"A string contained in the project name"`
+	Scope      *string `flag_name:"scope" type:"string" required:"no" enum:"all,owned,membership,starred" description:"Limit the search to projects owned by, with membership of or starred by the current user. Default is all"`
+	Visibility *string `flag_name:"visibility" type:"string" transform:"string2visibility" required:"no" enum:"public,internal,private" description:"Limit by visibility"`
+	Archived   *bool   `flag_name:"archived" type:"bool" required:"no" description:"Limit by archived status"`
+	OrderBy    *string `flag_name:"order_by" type:"string" required:"no" enum:"id,name,path,created_at,updated_at,last_activity_at" description:"Return projects ordered by id, name, path, created_at, updated_at or last_activity_at fields. Default is created_at"`
+	Sort       *string `flag_name:"sort" type:"string" required:"no" enum:"asc,desc" description:"Return projects sorted in asc or desc order. Default is desc"`
+}
+
+var projectSearchCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectSearchFlags{},
+	Opts:   &gitlab.ListProjectsOptions{},
+	Paged:  true,
+	Cmd: &cobra.Command{
+		Use:   "search",
+		Short: "Search for projects by name",
+		Long:  `Search for projects by name which are accessible to the authenticated user. This endpoint can be accessed without authentication if the project is publicly accessible.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectSearchFlags)
+		opts := cmd.Opts.(*gitlab.ListProjectsOptions)
+		if flags.Scope != nil {
+			scope := true
+			switch *flags.Scope {
+			case "owned":
+				opts.Owned = &scope
+			case "membership":
+				opts.Membership = &scope
+			case "starred":
+				opts.Starred = &scope
+			}
 		}
-		_, err = gitlabClient.Projects.DeleteProjectForkRelation(pid)
+		projects, _, err := gitlabClient.Projects.ListProjects(opts)
 		if err != nil {
 			return err
 		}
-		return nil
+		return OutputJson(projects)
 	},
 }
 
-type projectSearchFlags struct {
-	Search  *string `flag_name:"search" short:"s" type:"string" required:"yes" description:"A string contained in the project name"`
-	OrderBy *string `flag_name:"order_by" type:"string" required:"no" description:"Return requests ordered by id, name, created_at or last_activity_at fields"`
-	Sort    *string `flag_name:"sort" type:"string" required:"no" description:"Return requests sorted in asc or desc order"`
+// see https://docs.gitlab.com/ce/api/projects.html#start-the-housekeeping-task-for-a-project
+type projectHousekeepingFlags struct {
+	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project"`
+	Task *string `flag_name:"task" type:"string" required:"no" enum:"eager,prune" description:"prune to trigger a manual prune of unreachable objects or eager to trigger an eager housekeeping"`
 }
 
-var projectSearchCmd = &cobra.Command{
-	Use:   "search",
-	Short: "Search for projects by name",
-	Long:  `Search for projects by name which are accessible to the authenticated user. This endpoint can be accessed without authentication if the project is publicly accessible.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return errors.New("currently not implemented")
-		// TODO not implemented in go-gitlab so far
-		//projectSearchOptsMapper.AutoMap()
-		//opts := projectSearchOptsMapper.MappedOpts().(*gitlab...)
-		//flags := projectSearchOptsMapper.MappedFlags().(*projectSearchFlags)
-		//gitlabClient.Projects.Search()
-	},
+type housekeepingOptions struct {
+	Task *string `url:"task,omitempty" json:"task,omitempty"`
+}
+
+// housekeeping is the result of the housekeeping command, Gitlab only returns an empty response
+type housekeeping struct {
+	ProjectId string  `json:"project_id"`
+	Task      *string `json:"task,omitempty"`
+	Status    string  `json:"status"`
 }
 
-var projectHousekeepingCmd = &cobra.Command{
-	Use:   "housekeeping",
-	Short: "Start the Housekeeping task for a Project",
-	Long:  `Start the Housekeeping task for a Project`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// TODO this feature is missing in go-gitlab
-		return errors.New("this feature is not yet implemented")
+var projectHousekeepingCmd = &golabCommand{
+	Parent: projectsCmd.Cmd,
+	Flags:  &projectHousekeepingFlags{},
+	Opts:   &housekeepingOptions{},
+	Cmd: &cobra.Command{
+		Use:   "housekeeping",
+		Short: "Start the Housekeeping task for a Project",
+		Long: `Start the Housekeeping task for a Project.
+
+The task runs in the background, Gitlab refuses to start it while another housekeeping of the project is running.
+The status in the output is either started or conflict for a housekeeping that is already running.`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*projectHousekeepingFlags)
+		opts := cmd.Opts.(*housekeepingOptions)
+		// go-gitlab does not support housekeeping yet
+		req, err := gitlabClient.NewRequest("POST", "projects/"+url.QueryEscape(*flags.Id)+"/housekeeping", opts, nil)
+		if err != nil {
+			return err
+		}
+		result := housekeeping{ProjectId: *flags.Id, Task: opts.Task, Status: "started"}
+		resp, err := gitlabClient.Do(req, nil)
+		if resp != nil && resp.StatusCode == http.StatusConflict {
+			result.Status = "conflict"
+		} else if err != nil {
+			return err
+		}
+		return OutputJson(result)
 	},
 }
 
@@ -625,119 +726,30 @@ func parsePid(value string) interface{} {
 }
 
 func init() {
+	projectsCmd.Init()
 	projectsListCmd.Init()
-	initProjectGetCmd()
-	initProjectCreateCmd()
-	initProjectEditCmd()
-	initProjectForkCmd()
-	initProjectListForksCmd()
-	initCommandWithIdOnly(projectStarCmd, projectsCmd.Cmd)
-	initCommandWithIdOnly(projectUnstarCmd, projectsCmd.Cmd)
-	initCommandWithIdOnly(projectArchiveCmd, projectsCmd.Cmd)
-	initCommandWithIdOnly(projectUnarchiveCmd, projectsCmd.Cmd)
-	initCommandWithIdOnly(projectDeleteCmd, projectsCmd.Cmd)
-	initProjectUploadFileCmd()
-	initProjectShareCmd()
-	initProjectUnshareCmd()
-	initCommandWithIdOnly(projectHooksListCmd, projectHooksCmd)
-	initProjectHooksGetCmd()
-	initProjectAddHookCmd()
-	initProjectEditHookCmd()
-	initProjectDeleteHookCmd()
-	initProjectForksCreateCmd()
-	initCommandWithIntIdOnly(projectForksDeleteCmd, projectForskCmd)
-	initProjectSearchCmd()
-	initCommandWithIdOnly(projectHousekeepingCmd, projectsCmd.Cmd)
-
-	projectsCmd.Cmd.AddCommand(projectForskCmd)
-	projectsCmd.Cmd.AddCommand(projectHooksCmd)
-	RootCmd.AddCommand(projectsCmd.Cmd)
-}
-
-func initProjectGetCmd() {
-	getOptsMapper = mapper.InitializedMapper(projectGetCmd, &getFlags{}, &getFlags{})
-	projectsCmd.Cmd.AddCommand(projectGetCmd)
-}
-
-func initProjectCreateCmd() {
-	createOptsMapper = mapper.InitializedMapper(projectCreateCmd, &createFlags{}, &gitlab.CreateProjectOptions{})
-	projectsCmd.Cmd.AddCommand(projectCreateCmd)
-}
-
-func initProjectEditCmd() {
-	editOptsMapper = mapper.InitializedMapper(projectEditCmd, &editFlags{}, &gitlab.EditProjectOptions{})
-	projectsCmd.Cmd.AddCommand(projectEditCmd)
-}
-
-func initProjectForkCmd() {
-	forkOptsMapper = mapper.InitializedMapper(projectForkCmd, &forkFlags{}, &forkFlags{})
-	projectsCmd.Cmd.AddCommand(projectForkCmd)
-}
-
-func initProjectListForksCmd() {
-	// TODO the opts are currently not available in go-gitlab
-	listForksOptsMapper = mapper.InitializedMapper(projectListForksCmd, &listForksFlags{}, &listForksFlags{})
-	projectsCmd.Cmd.AddCommand(projectListForksCmd)
-}
-
-func initProjectUploadFileCmd() {
-	projectUploadFileCmd.PersistentFlags().StringP("id", "i", "", "(required) The ID or URL-encoded path of the project")
-	projectUploadFileCmd.PersistentFlags().StringP("file", "f", "", "(required) Path to the file to be uploaded")
-	projectsCmd.Cmd.AddCommand(projectUploadFileCmd)
-}
-
-func initProjectShareCmd() {
-	shareOptsMapper = mapper.InitializedMapper(projectShareWithGroupCmd, &shareFlags{}, &gitlab.ShareWithGroupOptions{})
-	projectsCmd.Cmd.AddCommand(projectShareWithGroupCmd)
-}
-
-func initProjectUnshareCmd() {
-	projectUnshareWithGroupCmd.PersistentFlags().StringP("id", "i", "", "The ID or URL-encoded path of the project")
-	projectUnshareWithGroupCmd.PersistentFlags().StringP("group_id", "g", "", "The ID of the group")
-	projectsCmd.Cmd.AddCommand(projectUnshareWithGroupCmd)
-}
-
-func initProjectHooksGetCmd() {
-	projectHooksGetCmd.PersistentFlags().StringP("id", "i", "", "(required) The ID or URL-encoded path of the project")
-	projectHooksGetCmd.PersistentFlags().IntP("hook_id", "", 0, "The ID of a project hook")
-	projectHooksCmd.AddCommand(projectHooksGetCmd)
-}
-
-func initProjectAddHookCmd() {
-	addHookOptsMapper = mapper.InitializedMapper(projectAddHookCmd, &addHookFlags{}, &gitlab.AddProjectHookOptions{})
-	projectHooksCmd.AddCommand(projectAddHookCmd)
-}
-
-func initProjectEditHookCmd() {
-	editHookOptsMapper = mapper.InitializedMapper(projectEditHookCmd, &editHookFlags{}, &gitlab.EditProjectHookOptions{})
-	projectHooksCmd.AddCommand(projectEditHookCmd)
-}
-
-func initProjectDeleteHookCmd() {
-	projectDeleteHookCmd.PersistentFlags().StringP("id", "i", "", "The ID or URL-encoded path of the project")
-	projectDeleteHookCmd.PersistentFlags().Int("hook_id", 0, "The ID of the project hook")
-	projectHooksCmd.AddCommand(projectDeleteHookCmd)
-}
-
-func initProjectForksCreateCmd() {
-	// TODO gitlab API now supports URL encoded path for project id, but go-gitlab does not
-	projectForksCreateCmd.PersistentFlags().IntP("id", "i", 0, "(required) The ID of the project")
-	projectForksCreateCmd.PersistentFlags().IntP("forked_from_id", "f", 0, "(required) The ID of the project that was forked from")
-	projectForskCmd.AddCommand(projectForksCreateCmd)
-}
-
-func initProjectSearchCmd() {
-	// TODO we do not have the opts in go-gitlab yet
-	projectSearchOptsMapper = mapper.InitializedMapper(projectSearchCmd, &projectSearchFlags{}, &projectSearchFlags{})
-	projectsCmd.Cmd.AddCommand(projectSearchCmd)
-}
-
-func initCommandWithIdOnly(cmd *cobra.Command, parent *cobra.Command) {
-	cmd.PersistentFlags().StringP("id", "i", "", "(required) The ID or URL-encoded path of the project")
-	parent.AddCommand(cmd)
-}
-
-func initCommandWithIntIdOnly(cmd *cobra.Command, parent *cobra.Command) {
-	cmd.PersistentFlags().IntP("id", "i", 0, "(required) The ID of the project")
-	parent.AddCommand(cmd)
+	projectGetCmd.Init()
+	projectCreateCmd.Init()
+	projectEditCmd.Init()
+	projectForkCmd.Init()
+	projectListForksCmd.Init()
+	projectStarCmd.Init()
+	projectUnstarCmd.Init()
+	projectArchiveCmd.Init()
+	projectUnarchiveCmd.Init()
+	projectDeleteCmd.Init()
+	projectUploadFileCmd.Init()
+	projectShareWithGroupCmd.Init()
+	projectUnshareWithGroupCmd.Init()
+	projectHooksCmd.Init()
+	projectHooksListCmd.Init()
+	projectHooksGetCmd.Init()
+	projectAddHookCmd.Init()
+	projectEditHookCmd.Init()
+	projectDeleteHookCmd.Init()
+	projectForksCmd.Init()
+	projectForksCreateCmd.Init()
+	projectForksDeleteCmd.Init()
+	projectSearchCmd.Init()
+	projectHousekeepingCmd.Init()
 }
@@ -0,0 +1,66 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/michaellihs/golab/cmd/mapper"
+	"github.com/spf13/viper"
+)
+
+// gitRemote is the name of the git remote the project is resolved from
+var gitRemote string
+
+// resolveProject returns the path (namespace/project) of the project of the
+// git remote in the working directory
+func resolveProject() (string, error) {
+	remote, err := GitHelper().GetRemote(remoteName())
+	if err != nil {
+		return "", err
+	}
+
+	path := remote.Path()
+	if gitlabUrl, err := url.Parse(viper.GetString("url")); err == nil && gitlabUrl.Host != "" {
+		// compare hostnames only, since the ports of ssh and http differ
+		if gitlabUrl.Hostname() != remote.Host {
+			return "", fmt.Errorf("host %s of git remote %s does not match the Gitlab URL %s", remote.Host, remoteName(), viper.GetString("url"))
+		}
+		// Gitlab might be installed under a relative URL, e.g. https://my-domain.com/gitlab
+		path = strings.TrimPrefix(path, strings.Trim(gitlabUrl.Path, "/")+"/")
+	}
+	return path, nil
+}
+
+// remoteName returns the name of the git remote given with --remote
+func remoteName() string {
+	if gitRemote == "" {
+		return "origin"
+	}
+	return gitRemote
+}
+
+func init() {
+	mapper.RegisterResolver("project", resolveProject)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("project resolver", func() {
+
+	var (
+		mux     *http.ServeMux
+		server  *httptest.Server
+		repo    string
+		prevDir string
+	)
+
+	git := func(args ...string) {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		Expect(err).To(BeNil(), string(out))
+	}
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(branchesListCmd)
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		prevDir, _ = os.Getwd()
+		repo, _ = ioutil.TempDir("", "golab-repo")
+		os.Chdir(repo)
+		git("init", "-q")
+		git("remote", "add", "origin", "git@gitlab.my-domain.com:my-group/my-subgroup/my-repo.git")
+		git("remote", "add", "upstream", "https://gitlab.my-domain.com/upstream/my-project.git")
+		viper.Set("url", "https://gitlab.my-domain.com")
+	})
+
+	AfterEach(func() {
+		os.Chdir(prevDir)
+		os.RemoveAll(repo)
+		server.Close()
+		viper.Set("url", nil)
+		gitRemote = ""
+	})
+
+	It("resolves the project from the origin remote", func() {
+		project, err := resolveProject()
+		Expect(err).To(BeNil())
+		Expect(project).To(Equal("my-group/my-subgroup/my-repo"))
+	})
+
+	It("resolves the project from another remote", func() {
+		gitRemote = "upstream"
+		project, err := resolveProject()
+		Expect(err).To(BeNil())
+		Expect(project).To(Equal("upstream/my-project"))
+	})
+
+	It("applies insteadOf rewrites of the git config", func() {
+		git("config", "url.git@gitlab.my-domain.com:.insteadOf", "gl:")
+		git("remote", "add", "short", "gl:short-group/short-project.git")
+		gitRemote = "short"
+		project, err := resolveProject()
+		Expect(err).To(BeNil())
+		Expect(project).To(Equal("short-group/short-project"))
+	})
+
+	It("verifies the host of the remote", func() {
+		viper.Set("url", "https://gitlab.com")
+		_, err := resolveProject()
+		Expect(err).To(MatchError("host gitlab.my-domain.com of git remote origin does not match the Gitlab URL https://gitlab.com"))
+	})
+
+	It("is used for commands without --id", func() {
+		path := ""
+		mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.RawPath
+			fmt.Fprint(w, `[]`)
+		})
+		_, _, err := executeCommand(RootCmd, "branches", "list")
+		Expect(err).To(BeNil())
+		Expect(path).To(Equal("/api/v4/projects/my-group%2Fmy-subgroup%2Fmy-repo/repository/branches"))
+	})
+
+})
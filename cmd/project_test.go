@@ -21,9 +21,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"net/http/httptest"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -60,7 +62,7 @@ var _ = Describe("project command", func() {
 			expected := readFixture("project-ls")
 			mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
 				method = r.Method
-				fmt.Fprintf(w, expected)
+				fmt.Fprint(w, expected)
 			})
 			stdout, _, err := executeCommand(RootCmd, "project", "ls")
 			Expect(err).To(BeNil())
@@ -70,4 +72,122 @@ var _ = Describe("project command", func() {
 		})
 	})
 
-})
\ No newline at end of file
+	Context("when the `star` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectStarCmd)
+		})
+
+		It("stars the project", func() {
+			defer server.Close()
+			method := ""
+			mux.HandleFunc("/api/v4/projects/my-group/my-project/star", func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				fmt.Fprint(w, `{"id":1,"star_count":1}`)
+			})
+			stdout, _, err := executeCommand(RootCmd, "project", "star", "-i", "my-group/my-project")
+			Expect(err).To(BeNil())
+			Expect(method).To(Equal("POST"))
+			Expect(stdout).To(ContainSubstring(`"star_count": 1`))
+		})
+	})
+
+	Context("when the `hooks get` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectHooksGetCmd)
+		})
+
+		It("returns an error if no hook id is given", func() {
+			_, _, err := executeCommand(RootCmd, "project", "hooks", "get", "-i", "42")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("required flag --hook_id was empty"))
+		})
+	})
+
+	Context("when the `forks create` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectForksCreateCmd)
+		})
+
+		It("creates the fork relation", func() {
+			defer server.Close()
+			path := ""
+			mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(http.StatusCreated)
+			})
+			_, _, err := executeCommand(RootCmd, "project", "forks", "create", "-i", "42", "-f", "23")
+			Expect(err).To(BeNil())
+			Expect(path).To(Equal("/api/v4/projects/42/fork/23"))
+		})
+	})
+
+	Context("when the `create` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectCreateCmd)
+		})
+
+		It("creates the project with the given visibility", func() {
+			defer server.Close()
+			var body map[string]interface{}
+			mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&body)
+				fmt.Fprint(w, `{"id":1,"name":"golab"}`)
+			})
+			_, _, err := executeCommand(RootCmd, "project", "create", "-n", "golab", "--visibility", "public")
+			Expect(err).To(BeNil())
+			Expect(body["visibility"]).To(Equal("public"))
+		})
+	})
+
+	Context("when the `search` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectSearchCmd)
+		})
+
+		It("searches the projects of the given scope", func() {
+			defer server.Close()
+			query := ""
+			mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.RawQuery
+				fmt.Fprint(w, `[{"id":1,"name":"golab"}]`)
+			})
+			stdout, _, err := executeCommand(RootCmd, "project", "search", "-s", "golab", "--scope", "starred", "--visibility", "internal", "--sort", "asc")
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal("search=golab&sort=asc&starred=true&visibility=internal"))
+			Expect(stdout).To(ContainSubstring(`"name": "golab"`))
+		})
+	})
+
+	Context("when the `housekeeping` command is executed", func() {
+		BeforeEach(func() {
+			resetFlags(projectHousekeepingCmd)
+		})
+
+		It("starts the housekeeping", func() {
+			defer server.Close()
+			method, body := "", ""
+			mux.HandleFunc("/api/v4/projects/my-group/my-project/housekeeping", func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				bodyBytes, _ := ioutil.ReadAll(r.Body)
+				body = string(bodyBytes)
+				w.WriteHeader(http.StatusCreated)
+			})
+			stdout, _, err := executeCommand(RootCmd, "project", "housekeeping", "-i", "my-group/my-project", "--task", "prune")
+			Expect(err).To(BeNil())
+			Expect(method).To(Equal("POST"))
+			Expect(body).To(Equal(`{"task":"prune"}`))
+			Expect(stdout).To(MatchJSON(`{"project_id": "my-group/my-project", "task": "prune", "status": "started"}`))
+		})
+
+		It("reports a running housekeeping", func() {
+			defer server.Close()
+			mux.HandleFunc("/api/v4/projects/42/housekeeping", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"message":"Somebody already triggered housekeeping for this resource within the last 10 minutes"}`)
+			})
+			stdout, _, err := executeCommand(RootCmd, "project", "housekeeping", "-i", "42")
+			Expect(err).To(BeNil())
+			Expect(stdout).To(MatchJSON(`{"project_id": "42", "status": "conflict"}`))
+		})
+	})
+})
@@ -45,7 +45,7 @@ var protectedBranchesCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/protected_branches.html#list-protected-branches
 type protectedBranchesListFlags struct {
-	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id *string `flag_name:"id" short:"i" type:"string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 }
 
 var protectedBranchesListCmd = &golabCommand{
@@ -68,7 +68,7 @@ var protectedBranchesListCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/protected_branches.html#get-a-single-protected-branch-or-wildcard-protected-branch
 type protectedBranchesGetFlags struct {
-	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the branch or wildcard"`
 }
 
@@ -92,7 +92,7 @@ var protectedBranchesGetCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/protected_branches.html#protect-repository-branches
 type protectedBranchesProtectRepositoryFlags struct {
-	Id               *string `flag_name:"id" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id               *string `flag_name:"id" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name             *string `flag_name:"name" type:"string" required:"yes" description:"The name of the branch or wildcard"`
 	PushAccessLevel  *string `flag_name:"push_access_level" type:"string" transform:"str2AccessLevel" required:"no" description:"Access levels allowed to push (defaults: 40, master access level)"`
 	MergeAccessLevel *string `flag_name:"merge_access_level" type:"string" transform:"str2AccessLevel" required:"no" description:"Access levels allowed to merge (defaults: 40, master access level)"`
@@ -128,7 +128,7 @@ Access Levels:
 
 // see https://docs.gitlab.com/ce/api/protected_branches.html#unprotect-repository-branches
 type protectedBranchesUnprotectBranchFlags struct {
-	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
+	Id   *string `flag_name:"id" short:"i" type:"integer/string" required:"yes" resolve:"project" description:"The ID or URL-encoded path of the project owned by the authenticated user"`
 	Name *string `flag_name:"name" short:"n" type:"string" required:"yes" description:"The name of the branch or wildcard"`
 }
 
@@ -22,40 +22,90 @@ package cmd
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-rootcerts"
+	"github.com/michaellihs/golab/cmd/mapper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/xanzy/go-gitlab"
 )
 
-var cfgFile, caFile, caPath string
+var cfgFile, sudo string
 
 var gitlabClient *gitlab.Client
 
+// debugOutput is where --debug writes the HTTP trace to
+var debugOutput io.Writer = os.Stderr
+
+// clientError is the error of reading the config or initializing the Gitlab client,
+// it is returned by all commands that talk to Gitlab, see checkClient
+var clientError error
+
+// offlineCommands are the paths of the commands that do not talk to Gitlab or set up their own client
+var offlineCommands = map[string]bool{"golab login": true, "golab personal-access-token": true, "golab gendoc": true,
+	"golab zsh-completion": true, "golab bash-completion": true, "golab help": true, "golab version": true}
+
 var RootCmd = &cobra.Command{
-	Use:               "golab",
-	Short:             "Gitlab CLI written in Go",
-	Long:              `This application provides a Command Line Interface for Gitlab.`,
-	DisableAutoGenTag: true, // disables footer in markdown files generated by cobra.gendoc
+	Use:                    "golab",
+	Short:                  "Gitlab CLI written in Go",
+	Long:                   `This application provides a Command Line Interface for Gitlab.`,
+	DisableAutoGenTag:      true, // disables footer in markdown files generated by cobra.gendoc
+	BashCompletionFunction: mapper.EnumCompletionFunction,
+	PersistentPreRunE:      checkClient,
 }
 
+// exit codes of golab
+const (
+	exitError      = 1 // the command failed, e.g. because of an error of the Gitlab API
+	exitUsageError = 2 // a flag is missing or has an invalid value
+)
+
 func Execute() {
 	initRootCommand()
 	if err := RootCmd.Execute(); err != nil {
-		os.Exit(-1)
+		os.Exit(exitCode(err))
+	}
+}
+
+func exitCode(err error) int {
+	if _, ok := err.(*mapper.FlagError); ok {
+		return exitUsageError
 	}
+	return exitError
 }
 
 func initRootCommand() {
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "(optional) golab config file (default is ./.golab.yml and $HOME/.golab.yml)")
-	RootCmd.PersistentFlags().StringVar(&caFile, "ca-file", "", "(optional) provides a .pem file to be used in certificates pool for SSL connection")
-	RootCmd.PersistentFlags().StringVar(&caPath, "ca-path", "", "(optional) provides a directory with .pem certificates to be used for SSL connection")
+	RootCmd.PersistentFlags().String("ca-file", "", "(optional) provides a .pem file to be used in certificates pool for SSL connection")
+	RootCmd.PersistentFlags().String("ca-path", "", "(optional) provides a directory with .pem certificates to be used for SSL connection")
+	RootCmd.PersistentFlags().Bool("insecure", false, "(optional) skip verification of the server's TLS certificate - use with care!")
+	RootCmd.PersistentFlags().String("client-cert", "", "(optional) provides a .pem file with a client certificate for mutual TLS")
+	RootCmd.PersistentFlags().String("client-key", "", "(optional) provides a .pem file with the key of the client certificate (default is the --client-cert file)")
+	RootCmd.PersistentFlags().String("proxy", "", "(optional) URL of the HTTP proxy (default is taken from $HTTPS_PROXY / $HTTP_PROXY)")
+	RootCmd.PersistentFlags().Duration("timeout", 0, "(optional) timeout for a request including its retries, e.g. 30s (default is no timeout)")
+	RootCmd.PersistentFlags().Bool("debug", false, "(optional) log all HTTP requests and responses to stderr, tokens are redacted")
+	RootCmd.PersistentFlags().Bool("debug-body", false, "(optional) log the bodies of HTTP requests and responses as well (implies --debug)")
+	RootCmd.PersistentFlags().Int("retries", 3, "(optional) number of retries for idempotent requests that failed temporarily, e.g. with 429 or 502")
+	RootCmd.PersistentFlags().StringVar(&gitRemote, "remote", "origin", "(optional) git remote to take the project from, if a command is run without --id in a git repository")
+	RootCmd.PersistentFlags().StringVar(&sudo, "sudo", "", "(optional) perform all requests as the given user (username or ID), requires an admin token with sudo scope")
+
+	// invalid values of typed flags (e.g. --page abc) are usage errors as well
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &mapper.FlagError{Err: err}
+	})
+
+	// HTTP settings can be given in the golab config as well
+	for key, flag := range map[string]string{"ca_file": "ca-file", "ca_path": "ca-path", "insecure": "insecure", "client_cert": "client-cert", "client_key": "client-key",
+		"proxy": "proxy", "timeout": "timeout", "retries": "retries", "debug": "debug", "debug_body": "debug-body"} {
+		viper.BindPFlag(key, RootCmd.PersistentFlags().Lookup(flag))
+	}
 
 	// TODO this is an ugly hack to prevent re-initialization when mocked in testing
 	if gitlabClient == nil {
@@ -76,53 +126,127 @@ func initConfig() {
 		viper.AddConfigPath(".")      // adding current directory as first search path
 		viper.AddConfigPath("$HOME")  // adding home directory as second search path
 	}
-	viper.AutomaticEnv() // read in environment variables that match
+	initEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println(err)
+		if _, notFound := err.(viper.ConfigFileNotFoundError); !notFound {
+			clientError = fmt.Errorf("could not read golab config: %s", err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
 	}
 }
 
+// initEnv reads the settings from environment variables with the prefix GOLAB_, e.g. GOLAB_URL or GOLAB_INSECURE,
+// so that unrelated variables like $DEBUG or $INSECURE do not change the settings of golab
+func initEnv() {
+	viper.SetEnvPrefix("golab")
+	viper.AutomaticEnv()
+}
+
 func initGitlabClient() {
+	if clientError == nil {
+		clientError = createGitlabClient()
+	}
+}
+
+// checkClient aborts commands that talk to Gitlab, if the Gitlab client could not
+// be initialized, e.g. because the credential store is not available
+func checkClient(cmd *cobra.Command, args []string) error {
+	if offlineCommands[cmd.CommandPath()] {
+		return nil
+	}
+	return clientError
+}
+
+func createGitlabClient() error {
 	baseUrl, err := url.Parse(viper.GetString("url"))
 	if err != nil {
-		fmt.Printf("Could not parse given URL '%s': %s", baseUrl, err)
+		return fmt.Errorf("could not parse given URL '%s': %s", viper.GetString("url"), err)
 	}
 
 	httpClient, err := initHttpClient()
 	if err != nil {
-		panic("Error in initializing http client " + err.Error())
+		return fmt.Errorf("could not initialize the http client: %s", err)
 	}
 
-	gitlabClient = gitlab.NewClient(httpClient, viper.GetString("token"))
+	currentAuthentication, err = authenticate(httpClient, baseUrl.String())
+	if err != nil {
+		return err
+	}
+
+	if currentAuthentication.OAuth != nil {
+		gitlabClient = gitlab.NewOAuthClient(httpClient, currentAuthentication.Token)
+	} else {
+		gitlabClient = gitlab.NewClient(httpClient, currentAuthentication.Token)
+	}
 	gitlabClient.SetBaseURL(baseUrl.String() + "/api/v4")
+	return nil
 }
 
 func initHttpClient() (*http.Client, error) {
-	// see https://github.com/hashicorp/go-rootcerts
-	tlsConfig := &tls.Config{}
-	err := rootcerts.ConfigureTLS(tlsConfig, &rootcerts.Config{
-		//CAFile: os.Getenv("GOLAB_CAFILE"),
-		//CAPath: os.Getenv("GOLAB_CAPATH"),
-		CAFile: caFile,
-		CAPath: caPath,
-	})
+	tlsConfig, err := initTlsConfig()
 	if err != nil {
 		return nil, err
 	}
 	c := cleanhttp.DefaultClient()
 	t := cleanhttp.DefaultTransport()
 
-	// use this line for debugging certificates
-	//fmt.Println(tlsConfig.RootCAs)
-
 	t.TLSClientConfig = tlsConfig
+	if proxy := viper.GetString("proxy"); proxy != "" {
+		proxyUrl, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse proxy URL '%s': %s", proxy, err)
+		}
+		t.Proxy = http.ProxyURL(proxyUrl)
+	}
+	// the transports are wrapped from the inside out: requests pass the sudo, retry and
+	// debug transport in this order, so that every attempt is logged with the Sudo header
 	c.Transport = t
+	if viper.GetBool("debug") || viper.GetBool("debug_body") {
+		c.Transport = &debugTransport{base: c.Transport, out: debugOutput, bodies: viper.GetBool("debug_body")}
+	}
+	c.Timeout = viper.GetDuration("timeout")
+	if retries := viper.GetInt("retries"); retries > 0 {
+		c.Transport = newRetryTransport(c.Transport, retries)
+	}
+	if sudo != "" {
+		c.Transport = &sudoTransport{base: c.Transport, user: sudo}
+	}
 	return c, nil
+}
 
-	// TODO this is an ugly hack to prevent SSL verification... see https://github.com/andygrunwald/go-jira/issues/52
-	//tr := &http.Transport{
-	//	TLSClientConfig: &tls.Config{InsecureSkipVerify : true},
-	//}
-	//return &http.Client{Transport: tr}, nil
+// initTlsConfig reads the TLS settings from the flags or the golab config
+func initTlsConfig() (*tls.Config, error) {
+	// see https://github.com/hashicorp/go-rootcerts
+	tlsConfig := &tls.Config{}
+	err := rootcerts.ConfigureTLS(tlsConfig, &rootcerts.Config{
+		CAFile: viper.GetString("ca_file"),
+		CAPath: viper.GetString("ca_path"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if viper.GetBool("insecure") {
+		fmt.Fprintln(os.Stderr, "** WARNING: TLS certificate verification is disabled (insecure), the connection to Gitlab can be intercepted!")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	clientCert, clientKey := viper.GetString("client_cert"), viper.GetString("client_key")
+	if clientKey != "" && clientCert == "" {
+		return nil, errors.New("a client key requires a client certificate (--client-cert)")
+	}
+	if clientCert != "" {
+		if clientKey == "" {
+			// the key might be contained in the certificate's PEM file
+			clientKey = clientCert
+		}
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
 }
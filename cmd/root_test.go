@@ -0,0 +1,216 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("http client", func() {
+
+	var (
+		server *httptest.Server
+		dir    string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		dir, _ = ioutil.TempDir("", "golab-tls")
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+		// viper.Reset() would drop the bindings of the global flags
+		for _, key := range []string{"insecure", "client_cert", "client_key", "debug"} {
+			viper.Set(key, nil)
+		}
+		sudo, debugOutput = "", os.Stderr
+	})
+
+	// writeClientCert writes a self-signed client certificate and its key into dir
+	writeClientCert := func() (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "golab"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).To(BeNil())
+		keyDer, err := x509.MarshalECPrivateKey(key)
+		Expect(err).To(BeNil())
+		certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+		ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+		ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+		return certFile, keyFile
+	}
+
+	get := func() error {
+		client, err := initHttpClient()
+		if err != nil {
+			return err
+		}
+		_, err = client.Get(server.URL)
+		return err
+	}
+
+	It("verifies the server certificate", func() {
+		server.StartTLS()
+		Expect(get()).NotTo(BeNil())
+	})
+
+	It("skips verification of the server certificate when insecure", func() {
+		server.StartTLS()
+		viper.Set("insecure", true)
+		Expect(get()).To(BeNil())
+	})
+
+	It("sends the client certificate", func() {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		server.StartTLS()
+		viper.Set("insecure", true)
+		Expect(get()).NotTo(BeNil())
+
+		certFile, keyFile := writeClientCert()
+		viper.Set("client_cert", certFile)
+		viper.Set("client_key", keyFile)
+		Expect(get()).To(BeNil())
+	})
+
+	It("requires a client certificate for a client key", func() {
+		viper.Set("client_key", "client.key")
+		_, err := initHttpClient()
+		Expect(err).To(MatchError("a client key requires a client certificate (--client-cert)"))
+	})
+
+	It("logs the Sudo header in the debug trace", func() {
+		server.Start()
+		var trace bytes.Buffer
+		sudo, debugOutput = "jdoe", &trace
+		viper.Set("debug", true)
+		Expect(get()).To(BeNil())
+		Expect(trace.String()).To(ContainSubstring("Sudo: jdoe"))
+	})
+
+	It("reads settings only from environment variables with the prefix GOLAB_", func() {
+		server.StartTLS()
+		initEnv()
+		os.Setenv("INSECURE", "true")
+		os.Setenv("DEBUG", "true")
+		defer os.Unsetenv("INSECURE")
+		defer os.Unsetenv("DEBUG")
+		var trace bytes.Buffer
+		debugOutput = &trace
+		Expect(get()).NotTo(BeNil())
+		Expect(trace.String()).To(BeEmpty())
+
+		os.Setenv("GOLAB_INSECURE", "true")
+		defer os.Unsetenv("GOLAB_INSECURE")
+		Expect(get()).To(BeNil())
+	})
+
+	It("returns errors of the http client instead of panicking", func() {
+		viper.Set("client_key", "client.key")
+		Expect(createGitlabClient()).To(MatchError("could not initialize the http client: a client key requires a client certificate (--client-cert)"))
+	})
+
+})
+
+var _ = Describe("client errors", func() {
+
+	AfterEach(func() {
+		clientError = nil
+	})
+
+	It("are returned by commands that talk to Gitlab", func() {
+		clientError = errors.New("keyring credential store requires `secret-tool` (libsecret) to be installed")
+		resetCommandLineFlagSet()
+		resetFlags(labelsListCmd)
+		stdout, _, err := executeCommand(RootCmd, "labels", "list", "--id", "1")
+		Expect(err).To(Equal(clientError))
+		Expect(stdout).NotTo(ContainSubstring("secret-tool"))
+	})
+
+	It("are ignored by commands that do not talk to Gitlab", func() {
+		clientError = errors.New("no access token found")
+		Expect(checkClient(personalAccessTokenCmd.Cmd, nil)).To(BeNil())
+		Expect(checkClient(gendocCmd, nil)).To(BeNil())
+		Expect(checkClient(versionCmd.Cmd, nil)).To(BeNil())
+	})
+
+	It("are returned by sub commands named like a command that does not talk to Gitlab", func() {
+		clientError = errors.New("no access token found")
+		root, project, version := &cobra.Command{Use: "golab"}, &cobra.Command{Use: "project"}, &cobra.Command{Use: "version"}
+		root.AddCommand(project)
+		project.AddCommand(version)
+		Expect(checkClient(version, nil)).To(Equal(clientError))
+	})
+
+	It("leave only the version of golab for the version command", func() {
+		clientError = errors.New("no access token found")
+		stdout, _, err := executeCommand(RootCmd, "version")
+		Expect(err).To(BeNil())
+		Expect(stdout).To(MatchJSON(`{"golab": "dev"}`))
+	})
+
+})
+
+var _ = Describe("exit code", func() {
+
+	It("is 2 for missing or invalid flags", func() {
+		resetCommandLineFlagSet()
+		resetFlags(labelsCreateCmd)
+		_, _, err := executeCommand(RootCmd, "labels", "create", "--id", "1", "--color", "#FFAABB")
+		Expect(err).To(MatchError("required flag --name was empty"))
+		Expect(exitCode(err)).To(Equal(2))
+	})
+
+	It("is 1 for other errors", func() {
+		Expect(exitCode(errors.New("404 Not Found"))).To(Equal(1))
+	})
+
+})
@@ -0,0 +1,112 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
+)
+
+var searchScopes = []string{"projects", "groups", "users", "namespaces"}
+
+type searchFlags struct {
+	Search  *string   `flag_name:"search" short:"s" type:"string" required:"yes" description:"A string contained in the name or path of the resources"`
+	Scope   *[]string `flag_name:"scope" type:"array" required:"no" enum:"projects,groups,users,namespaces" description:"Resources to search for, can be given multiple times. Default is all"`
+	PerPage *int      `flag_name:"per_page" type:"integer" required:"no" description:"The maximum number of results per resource (max 100)"`
+}
+
+var searchCmd = &golabCommand{
+	Parent: RootCmd,
+	Flags:  &searchFlags{},
+	Cmd: &cobra.Command{
+		Use:   "search",
+		Short: "Search for projects, groups, users and namespaces",
+		Long: `Search for projects, groups, users and namespaces that are visible to the authenticated user.
+
+The results are grouped by resource, e.g.
+
+    {"projects": [...], "groups": [...], "users": [...], "namespaces": [...]}`,
+	},
+	Run: func(cmd golabCommand) error {
+		flags := cmd.Flags.(*searchFlags)
+		scopes := searchScopes
+		if flags.Scope != nil && len(*flags.Scope) > 0 {
+			scopes = *flags.Scope
+		}
+		var options []gitlab.OptionFunc
+		if flags.PerPage != nil && *flags.PerPage > 0 {
+			options = append(options, perPage(*flags.PerPage))
+		}
+
+		results := map[string]interface{}{}
+		for _, scope := range scopes {
+			found, err := search(scope, *flags.Search, options)
+			if err != nil {
+				return fmt.Errorf("searching %s failed: %s", scope, err)
+			}
+			results[scope] = found
+		}
+		return OutputJson(results)
+	},
+}
+
+// search returns the resources of the given scope that match the search string
+func search(scope string, search string, options []gitlab.OptionFunc) (interface{}, error) {
+	switch scope {
+	case "projects":
+		projects, _, err := gitlabClient.Projects.ListProjects(&gitlab.ListProjectsOptions{Search: &search}, options...)
+		return projects, err
+	case "groups":
+		groups, _, err := gitlabClient.Groups.SearchGroup(search, options...)
+		return groups, err
+	case "users":
+		users, _, err := gitlabClient.Users.ListUsers(&gitlab.ListUsersOptions{Search: &search}, options...)
+		return users, err
+	case "namespaces":
+		namespaces, _, err := gitlabClient.Namespaces.SearchNamespace(search, options...)
+		return namespaces, err
+	}
+	return nil, fmt.Errorf("unknown scope %s", scope)
+}
+
+// perPage sets the number of results of a request, the search functions of go-gitlab don't take list options
+func perPage(n int) gitlab.OptionFunc {
+	return queryParam("per_page", strconv.Itoa(n))
+}
+
+// queryParam sets a query parameter of a request, for parameters that go-gitlab doesn't provide options for
+func queryParam(name string, value string) gitlab.OptionFunc {
+	return func(req *http.Request) error {
+		query := req.URL.Query()
+		query.Set(name, value)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+}
+
+func init() {
+	searchCmd.Init()
+}
@@ -0,0 +1,101 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("search command", func() {
+
+	var (
+		mux      *http.ServeMux
+		server   *httptest.Server
+		requests []string
+	)
+
+	BeforeEach(func() {
+		resetCommandLineFlagSet()
+		resetFlags(searchCmd)
+		requests = nil
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		gitlabClient = gitlab.NewClient(nil, "")
+		gitlabClient.SetBaseURL(server.URL + "/api/v4")
+
+		for resource, result := range map[string]string{
+			"projects":   `[{"id":1,"path_with_namespace":"my-group/golab"}]`,
+			"groups":     `[{"id":2,"path":"golab-group"}]`,
+			"users":      `[{"id":3,"username":"golab"}]`,
+			"namespaces": `[]`,
+		} {
+			result := result
+			mux.HandleFunc("/api/v4/"+resource, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+				fmt.Fprint(w, result)
+			})
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("searches all resources", func() {
+		stdout, _, err := executeCommand(RootCmd, "search", "-s", "golab")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(ConsistOf(
+			"/api/v4/projects?search=golab",
+			"/api/v4/groups?search=golab",
+			"/api/v4/users?search=golab",
+			"/api/v4/namespaces?search=golab",
+		))
+		Expect(stdout).To(ContainSubstring(`"path_with_namespace": "my-group/golab"`))
+		Expect(stdout).To(ContainSubstring(`"username": "golab"`))
+		Expect(stdout).To(ContainSubstring(`"namespaces": []`))
+	})
+
+	It("searches the given scopes with the given number of results", func() {
+		stdout, _, err := executeCommand(RootCmd, "search", "-s", "golab", "--scope", "groups", "--scope", "users", "--per_page", "5")
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(ConsistOf(
+			"/api/v4/groups?per_page=5&search=golab",
+			"/api/v4/users?per_page=5&search=golab",
+		))
+		Expect(stdout).NotTo(ContainSubstring("path_with_namespace"))
+	})
+
+	It("returns an error for unknown scopes", func() {
+		_, _, err := executeCommand(RootCmd, "search", "-s", "golab", "--scope", "issues")
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(Equal(`invalid value "issues" for flag --scope, expected one of projects, groups, users, namespaces`))
+		Expect(requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,305 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/michaellihs/golab/cmd/helpers"
+)
+
+// debugTransport logs all requests and responses, with secrets being redacted
+type debugTransport struct {
+	base   http.RoundTripper
+	out    io.Writer
+	bodies bool
+}
+
+// maxDebugBody limits the size of logged bodies
+const maxDebugBody = 16 * 1024
+
+var secretHeaders = []string{"Private-Token", "Authorization", "Job-Token", "Cookie", "Set-Cookie"}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var log bytes.Buffer
+	reqUrl := Redact(requestUrl(req))
+	fmt.Fprintf(&log, "--> %s %s\n", req.Method, reqUrl)
+	writeHeaders(&log, req.Header)
+	if t.bodies {
+		var err error
+		if req, err = rewindable(req); err != nil {
+			return nil, err
+		}
+	}
+	if t.bodies && req.Body != nil {
+		if body, err := req.GetBody(); err == nil {
+			content, _ := ioutil.ReadAll(body)
+			writeBody(&log, content)
+		}
+	}
+	fmt.Fprint(t.out, log.String())
+	log.Reset()
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start).Nanoseconds() / int64(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.out, "<-- %s %s failed after %dms: %s\n", req.Method, reqUrl, duration, Redact(err.Error()))
+		return resp, err
+	}
+
+	fmt.Fprintf(&log, "<-- %s %s %s (%dms)\n", resp.Status, req.Method, reqUrl, duration)
+	writeHeaders(&log, resp.Header)
+	if t.bodies {
+		content, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+		if err != nil {
+			return resp, err
+		}
+		writeBody(&log, content)
+	}
+	fmt.Fprint(t.out, log.String())
+	return resp, nil
+}
+
+// requestUrl returns the URL of the request - go-gitlab sets an opaque
+// path that is not rendered with scheme and host by url.URL.String()
+func requestUrl(req *http.Request) string {
+	u := *req.URL
+	if strings.HasPrefix(u.Opaque, "/") {
+		u.Path, _ = url.PathUnescape(u.Opaque)
+		u.RawPath, u.Opaque = u.Opaque, ""
+	}
+	return u.String()
+}
+
+func writeHeaders(w io.Writer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		for _, secret := range secretHeaders {
+			if http.CanonicalHeaderKey(name) == secret {
+				value = Redacted
+			}
+		}
+		fmt.Fprintf(w, "    %s: %s\n", name, value)
+	}
+}
+
+func writeBody(w io.Writer, content []byte) {
+	if len(content) == 0 {
+		return
+	}
+	suffix := ""
+	if len(content) > maxDebugBody {
+		suffix = fmt.Sprintf("\n    ... (%d more bytes)", len(content)-maxDebugBody)
+		content = content[:maxDebugBody]
+	}
+	fmt.Fprintf(w, "\n%s%s\n\n", Redact(string(content)), suffix)
+}
+
+// retryTransport retries idempotent requests with an exponential backoff,
+// if Gitlab is not available or rate limits our requests. Waits requested
+// by Gitlab via the Retry-After or RateLimit-Reset headers are honored.
+type retryTransport struct {
+	base       http.RoundTripper
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	// maxWait is the longest wait requested by Gitlab that we accept before giving up
+	maxWait time.Duration
+	sleep   func(context.Context, time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper, retries int) *retryTransport {
+	return &retryTransport{
+		base:       base,
+		retries:    retries,
+		minBackoff: 500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		maxWait:    2 * time.Minute,
+		sleep:      sleepContext,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := rewindable(req)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = cloneRequest(req)
+			req.Body = body
+		}
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.retries || !isRetryable(req, resp, err) {
+			return resp, err
+		}
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if requested, ok := requestedWait(resp); ok {
+				if requested > t.maxWait {
+					return resp, err
+				}
+				wait = requested
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext waits for the given duration, unless the request is canceled
+// or times out (--timeout) before
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isRetryable returns true, if the request can safely be sent again and the
+// error or status code is probably temporary
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	backoff := t.minBackoff << uint(attempt)
+	if backoff > t.maxBackoff || backoff <= 0 {
+		backoff = t.maxBackoff
+	}
+	// add some jitter, so that parallel scripts don't retry at the same time
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// requestedWait returns the wait requested by Gitlab, see
+// https://docs.gitlab.com/ce/user/admin_area/settings/user_and_ip_rate_limits.html#response-headers
+func requestedWait(resp *http.Response) (time.Duration, bool) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return waitUntil(date), true
+		}
+	}
+	if reset := resp.Header.Get("RateLimit-Reset"); reset != "" && resp.StatusCode == http.StatusTooManyRequests {
+		if timestamp, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return waitUntil(time.Unix(timestamp, 0)), true
+		}
+	}
+	return 0, false
+}
+
+func waitUntil(t time.Time) time.Duration {
+	if wait := time.Until(t); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// sudoTransport sets the Sudo header on every request, so that admins can
+// perform requests as another user, see https://docs.gitlab.com/ce/api/#sudo
+type sudoTransport struct {
+	base http.RoundTripper
+	user string
+}
+
+func (t *sudoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = cloneRequest(req)
+	req.Header.Set("Sudo", t.user)
+	return t.base.RoundTrip(req)
+}
+
+// rewindable returns a request whose body can be read again via GetBody,
+// since go-gitlab does not set GetBody for its requests
+func rewindable(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.GetBody != nil {
+		return req, nil
+	}
+	content, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req = cloneRequest(req)
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return req, nil
+}
+
+// cloneRequest returns a copy of the request with its own headers, since a
+// RoundTripper must not modify the request it is given
+func cloneRequest(req *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		clone.Header[k] = append([]string(nil), v...)
+	}
+	return clone
+}
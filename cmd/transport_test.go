@@ -0,0 +1,237 @@
+// Copyright © 2018 Michael Lihs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("transports", func() {
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("sudoTransport", func() {
+
+		It("sets the Sudo header on every request", func() {
+			sudoHeader := ""
+			mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+				sudoHeader = r.Header.Get("Sudo")
+				fmt.Fprint(w, `{"id":2,"username":"jdoe"}`)
+			})
+			client := gitlab.NewClient(&http.Client{Transport: &sudoTransport{base: http.DefaultTransport, user: "jdoe"}}, "admin-token")
+			client.SetBaseURL(server.URL + "/api/v4")
+
+			user, _, err := client.Users.CurrentUser()
+			Expect(err).To(BeNil())
+			Expect(user.Username).To(Equal("jdoe"))
+			Expect(sudoHeader).To(Equal("jdoe"))
+		})
+
+	})
+
+	Context("retryTransport", func() {
+
+		var (
+			waits     []time.Duration
+			transport *retryTransport
+			client    *http.Client
+		)
+
+		BeforeEach(func() {
+			waits = nil
+			transport = newRetryTransport(http.DefaultTransport, 3)
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			client = &http.Client{Transport: transport}
+		})
+
+		It("retries idempotent requests with exponential backoff", func() {
+			calls := 0
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				fmt.Fprint(w, "ok")
+			})
+			resp, err := client.Get(server.URL)
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(3))
+			Expect(waits).To(HaveLen(2))
+			Expect(waits[0]).To(BeNumerically("<=", 500*time.Millisecond))
+			Expect(waits[1]).To(BeNumerically(">=", 500*time.Millisecond))
+		})
+
+		It("gives up after the configured number of retries", func() {
+			calls := 0
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			resp, err := client.Get(server.URL)
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(calls).To(Equal(4))
+		})
+
+		It("honors Retry-After and RateLimit-Reset", func() {
+			calls := 0
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				switch calls {
+				case 1:
+					w.Header().Set("Retry-After", "7")
+					w.WriteHeader(http.StatusTooManyRequests)
+				case 2:
+					w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(20*time.Second).Unix(), 10))
+					w.WriteHeader(http.StatusTooManyRequests)
+				default:
+					fmt.Fprint(w, "ok")
+				}
+			})
+			_, err := client.Get(server.URL)
+			Expect(err).To(BeNil())
+			Expect(waits[0]).To(Equal(7 * time.Second))
+			Expect(waits[1]).To(BeNumerically("~", 20*time.Second, 2*time.Second))
+		})
+
+		It("stops waiting for a retry when the request times out", func() {
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+			})
+			transport.sleep = sleepContext
+			client.Timeout = 100 * time.Millisecond
+			start := time.Now()
+			_, err := client.Get(server.URL)
+			Expect(err).NotTo(BeNil())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+
+		It("resends the body of PUT requests", func() {
+			var bodies []string
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			})
+			req, _ := http.NewRequest("PUT", server.URL, strings.NewReader("payload"))
+			_, err := client.Do(req)
+			Expect(err).To(BeNil())
+			Expect(bodies).To(Equal([]string{"payload", "payload"}))
+		})
+
+		It("resends the body of PUT requests created by go-gitlab", func() {
+			var bodies []string
+			mux.HandleFunc("/api/v4/users/41", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+				fmt.Fprint(w, `{"id":41}`)
+			})
+			gitlabClient := gitlab.NewClient(client, "")
+			gitlabClient.SetBaseURL(server.URL + "/api/v4")
+			_, _, err := gitlabClient.Users.ModifyUser(41, &gitlab.ModifyUserOptions{Admin: gitlab.Bool(true)})
+			Expect(err).To(BeNil())
+			Expect(bodies).To(Equal([]string{`{"admin":true}`, `{"admin":true}`}))
+		})
+
+		It("does not retry POST requests", func() {
+			calls := 0
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusBadGateway)
+			})
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+			Expect(calls).To(Equal(1))
+		})
+
+	})
+
+	Context("debugTransport", func() {
+
+		It("logs requests and responses with redacted secrets", func() {
+			mux.HandleFunc("/api/v4/projects/1", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Set-Cookie", "_gitlab_session=secret-session")
+				fmt.Fprint(w, `{"id":1,"name":"golab","runners_token":"secret-runners-token","token":"secret-token"}`)
+			})
+			var log bytes.Buffer
+			client := gitlab.NewClient(&http.Client{Transport: &debugTransport{base: http.DefaultTransport, out: &log, bodies: true}}, "secret-private-token")
+			client.SetBaseURL(server.URL + "/api/v4")
+
+			project, _, err := client.Projects.GetProject(1)
+			Expect(err).To(BeNil())
+			Expect(project.Name).To(Equal("golab"))
+
+			Expect(log.String()).To(ContainSubstring("--> GET " + server.URL + "/api/v4/projects/1"))
+			Expect(log.String()).To(MatchRegexp(`<-- 200 OK GET \S+/api/v4/projects/1 \(\d+ms\)`))
+			Expect(log.String()).To(ContainSubstring("Private-Token: [REDACTED]"))
+			Expect(log.String()).To(ContainSubstring("Set-Cookie: [REDACTED]"))
+			Expect(log.String()).To(ContainSubstring(`"name":"golab"`))
+			Expect(log.String()).To(ContainSubstring(`"token":"[REDACTED]"`))
+			Expect(log.String()).To(ContainSubstring(`"runners_token":"[REDACTED]"`))
+			Expect(log.String()).NotTo(ContainSubstring("secret"))
+		})
+
+		It("renders the opaque URLs of go-gitlab", func() {
+			req, _ := http.NewRequest("GET", "https://gitlab.com/", nil)
+			req.URL.Opaque = "/api/v4/projects/my-group%2Fmy-project"
+			Expect(requestUrl(req)).To(Equal("https://gitlab.com/api/v4/projects/my-group%2Fmy-project"))
+		})
+
+	})
+
+})
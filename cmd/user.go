@@ -25,7 +25,6 @@ package cmd
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 
 	. "github.com/michaellihs/golab/cmd/helpers"
@@ -34,15 +33,15 @@ import (
 	"github.com/xanzy/go-gitlab"
 )
 
-var email, password, username, state, expires, scopes, name string
-var id, userId, tokenId, emailId int
-
-var userCmd = &cobra.Command{
-	Use:   "user",
-	Short: "Manage Gitlab users",
-	Long:  `Allows create, update and deletion of a user`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Use one of the subcommands, see `golab user -h`")
+var userCmd = &golabCommand{
+	Parent: RootCmd,
+	Cmd: &cobra.Command{
+		Use:   "user",
+		Short: "Manage Gitlab users",
+		Long:  `Allows create, update and deletion of a user`,
+	},
+	Run: func(cmd golabCommand) error {
+		return errors.New("use one of the subcommands, see `golab user -h`")
 	},
 }
 
@@ -53,7 +52,7 @@ type userGetFlags struct {
 }
 
 var userGetCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userGetFlags{},
 	Cmd: &cobra.Command{
 		Use:   "get",
@@ -96,7 +95,7 @@ type userGetAsAdminFlags struct {
 }
 
 var userGetAsAdminCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userGetAsAdminFlags{},
 	Opts:   &gitlab.GetUsersAsAdminOptions{},
 	Cmd: &cobra.Command{
@@ -131,7 +130,7 @@ type listUsersFlags struct {
 }
 
 var userLsCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &listUsersFlags{},
 	Opts:   &gitlab.ListUsersOptions{},
 	Paged:  true,
@@ -176,7 +175,7 @@ type userCreateFlags struct {
 }
 
 var userCreateCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userCreateFlags{},
 	Opts:   &gitlab.CreateUserOptions{},
 	Cmd: &cobra.Command{
@@ -186,7 +185,6 @@ var userCreateCmd = &golabCommand{
 	},
 	Run: func(cmd golabCommand) error {
 		opts := cmd.Opts.(*gitlab.CreateUserOptions)
-		OutputJson(opts)
 		user, _, err := gitlabClient.Users.CreateUser(opts)
 		if err != nil {
 			return err
@@ -202,7 +200,7 @@ type userDeleteFlags struct {
 }
 
 var userDeleteCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userDeleteFlags{},
 	Opts:   nil,
 	Cmd: &cobra.Command{
@@ -258,7 +256,7 @@ type userModifyFlags struct {
 }
 
 var userModifyCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userModifyFlags{},
 	Opts:   &gitlab.ModifyUserOptions{},
 	Cmd: &cobra.Command{
@@ -283,7 +281,7 @@ var userModifyCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/users.html#list-ssh-keys
 var userSshKeysCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  nil,
 	Opts:   nil,
 	Cmd: &cobra.Command{
@@ -428,7 +426,7 @@ type userActivitiesFlags struct {
 }
 
 var userActivitiesCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userActivitiesFlags{},
 	Opts:   &gitlab.GetUserActivitiesOptions{},
 	Cmd: &cobra.Command{
@@ -455,7 +453,7 @@ By default, it shows the activity for all users in the last 6 months, but this c
 
 // see https://docs.gitlab.com/ce/api/users.html#get-all-impersonation-tokens-of-a-user
 var userImpersonationTokenCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  nil,
 	Opts:   nil,
 	Cmd: &cobra.Command{
@@ -587,7 +585,7 @@ var userImpersonationTokenRevokeCmd = &golabCommand{
 
 // see https://docs.gitlab.com/ce/api/users.html#list-emails
 var userEmailsCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Cmd: &cobra.Command{
 		Use:   "emails",
 		Short: "User emails",
@@ -731,7 +729,7 @@ type userBlockFlags struct {
 }
 
 var userBlockCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userBlockFlags{},
 	Cmd: &cobra.Command{
 		Use:   "block",
@@ -754,7 +752,7 @@ type userUnblockFlags struct {
 }
 
 var userUnblockCmd = &golabCommand{
-	Parent: userCmd,
+	Parent: userCmd.Cmd,
 	Flags:  &userUnblockFlags{},
 	Cmd: &cobra.Command{
 		Use:   "unblock",
@@ -789,6 +787,7 @@ func getUserId(id int, username string) (int, error) {
 }
 
 func init() {
+	userCmd.Init()
 	userGetCmd.Init()
 	userGetAsAdminCmd.Init()
 	userLsCmd.Init()
@@ -813,5 +812,4 @@ func init() {
 	userEmailsDeleteCmd.Init()
 	userBlockCmd.Init()
 	userUnblockCmd.Init()
-	RootCmd.AddCommand(userCmd)
 }
@@ -21,26 +21,48 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	. "github.com/michaellihs/golab/cmd/helpers"
 
 	"github.com/spf13/cobra"
+	"github.com/xanzy/go-gitlab"
 )
 
+// golabVersion is set when building a release, e.g. with
+// go install -ldflags "-X github.com/michaellihs/golab/cmd.golabVersion=v1.0.0"
+var golabVersion = "dev"
+
+// versionInfo holds the version of golab and - if golab can talk to Gitlab - the version of Gitlab
+type versionInfo struct {
+	Golab string `json:"golab"`
+	*gitlab.Version
+}
+
 // see https://docs.gitlab.com/ce/api/version.html
 var versionCmd = &golabCommand{
 	Parent: RootCmd,
 	Cmd: &cobra.Command{
 		Use:     "version",
 		Aliases: []string{"v"},
-		Short:   "Gitlab version",
-		Long:    `Retrieve version information for this GitLab instance. Responds 200 OK for authenticated users.`,
+		Short:   "golab and Gitlab version",
+		Long: `Prints the version of golab and retrieves the version information of the GitLab instance, which responds for authenticated users.
+
+If golab is not configured for a Gitlab, only the version of golab is printed.`,
 	},
 	Run: func(cmd golabCommand) error {
+		info := versionInfo{Golab: golabVersion}
+		if clientError != nil {
+			fmt.Fprintf(os.Stderr, "could not get the version of Gitlab: %s\n", clientError)
+			return OutputJson(info)
+		}
 		version, _, err := gitlabClient.Version.GetVersion()
 		if err != nil {
 			return err
 		}
-		return OutputJson(version)
+		info.Version = version
+		return OutputJson(info)
 	},
 }
 